@@ -30,6 +30,7 @@ var (
 	ScopeProvisionersPlugins       = ac.Scope("provisioners", "plugins")
 	ScopeProvisionersDatasources   = ac.Scope("provisioners", "datasources")
 	ScopeProvisionersNotifications = ac.Scope("provisioners", "notifications")
+	ScopeProvisionersAlerting      = ac.Scope("provisioners", "alerting") // LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
 )
 
 // declareFixedRoles declares to the AccessControl service fixed roles and their
@@ -0,0 +1,115 @@
+package alertingrules
+
+// LOGZ.IO GRAFANA CHANGE :: Prometheus rule-file export and import of alert rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/promrules"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// ExportPrometheusRules writes the alert rules of a folder as a Prometheus rule file, to the file given as argument
+// or to stdout.
+func ExportPrometheusRules(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	orgID, folderUID, err := folderFlags(c, sqlStore)
+	if err != nil {
+		return err
+	}
+
+	file, err := newService(sqlStore).Export(context.Background(), orgID, folderUID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to export rules: %w", err)
+	}
+	data, err := promrules.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to export rules: %w", err)
+	}
+
+	path := c.Args().First()
+	if path == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := ioutil.WriteFile(path, data, 0640); err != nil {
+		return err
+	}
+	logger.Infof("%s Exported %d rule groups to %s\n", color.GreenString("✔"), len(file.Groups), path)
+	return nil
+}
+
+// ImportPrometheusRules creates or updates the alert rules of the Prometheus rule file given as argument in a folder.
+func ImportPrometheusRules(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	orgID, folderUID, err := folderFlags(c, sqlStore)
+	if err != nil {
+		return err
+	}
+
+	path := c.Args().First()
+	if path == "" {
+		return errors.New("path to the rule file is required")
+	}
+	// nolint:gosec
+	// The path is given by the user running the command.
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	file, err := promrules.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	result, err := newService(sqlStore).Import(context.Background(), orgID, folderUID, file, promrules.ImportOptions{
+		DatasourceUID: c.String("datasource-uid"),
+	})
+	if errors.Is(err, promrules.ErrInvalidRules) {
+		for _, ruleErr := range result.Errors {
+			logger.Errorf("%s Group %q rule %q: %s\n", color.RedString("✗"), ruleErr.Group, ruleErr.Rule, ruleErr.Error)
+		}
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to import rules: %w", err)
+	}
+
+	logger.Infof("%s Created %d and updated %d rules\n", color.GreenString("✔"), len(result.Created), len(result.Updated))
+	return nil
+}
+
+func folderFlags(c utils.CommandLine, sqlStore *sqlstore.SQLStore) (int64, string, error) {
+	orgID := int64(c.Int("org-id"))
+	folderUID := c.String("folder-uid")
+	if folderUID == "" {
+		return 0, "", errors.New("--folder-uid is required")
+	}
+
+	query := models.GetDashboardQuery{Uid: folderUID, OrgId: orgID}
+	if err := sqlStore.GetDashboard(context.Background(), &query); err != nil {
+		return 0, "", fmt.Errorf("failed to find folder %q: %w", folderUID, err)
+	}
+	if !query.Result.IsFolder {
+		return 0, "", fmt.Errorf("%q is not a folder", folderUID)
+	}
+	return orgID, folderUID, nil
+}
+
+func newService(sqlStore *sqlstore.SQLStore) *promrules.Service {
+	cfg := sqlStore.Cfg.UnifiedAlerting
+	ruleStore := store.NewDBstore(cfg, sqlStore, log.New("ngalert.dbstore"), state.ValidateRuleTemplates)
+	return promrules.NewService(ruleStore, ruleStore, cfg.BaseInterval, cfg.DefaultRuleEvaluationInterval, log.New("ngalert.promrules"))
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
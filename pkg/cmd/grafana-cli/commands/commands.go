@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/alertingrules" // LOGZ.IO GRAFANA CHANGE :: Prometheus rule-file export and import of alert rules
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/datamigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/secretsmigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
@@ -195,8 +196,46 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	// LOGZ.IO GRAFANA CHANGE :: Prometheus rule-file export and import of alert rules
+	{
+		Name:  "alerting",
+		Usage: "Exports and imports unified alerting rules",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "export-prometheus-rules",
+				Usage:  "export-prometheus-rules --folder-uid <uid> [file]. Writes the alert rules of a folder as a Prometheus rule file, to stdout if no file is given.",
+				Action: runDbCommand(alertingrules.ExportPrometheusRules),
+				Flags:  alertingRulesFlags,
+			},
+			{
+				Name:   "import-prometheus-rules",
+				Usage:  "import-prometheus-rules --folder-uid <uid> [--datasource-uid <uid>] <file>. Creates or updates the alert rules of a Prometheus rule file in a folder.",
+				Action: runDbCommand(alertingrules.ImportPrometheusRules),
+				Flags: append(alertingRulesFlags, &cli.StringFlag{
+					Name:  "datasource-uid",
+					Usage: "The data source queried by the rules with a native expr",
+				}),
+			},
+		},
+	},
+	// LOGZ.IO GRAFANA CHANGE :: end
+}
+
+// LOGZ.IO GRAFANA CHANGE :: Prometheus rule-file export and import of alert rules
+var alertingRulesFlags = []cli.Flag{
+	&cli.IntFlag{
+		Name:  "org-id",
+		Usage: "The organization of the folder",
+		Value: 1,
+	},
+	&cli.StringFlag{
+		Name:  "folder-uid",
+		Usage: "The folder of the alert rules",
+	},
 }
 
+// LOGZ.IO GRAFANA CHANGE :: end
+
 var cueCommands = []*cli.Command{
 	{
 		Name:   "validate-schema",
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 
@@ -48,8 +49,15 @@ type DataPipeline []Node
 // map of the refId of the of each command
 func (dp *DataPipeline) execute(c context.Context, s *Service) (mathexp.Vars, error) {
 	vars := make(mathexp.Vars)
+	trace := nodeTraceFromContext(c) // LOGZ.IO GRAFANA CHANGE :: Expose intermediate expression node results
 	for _, node := range *dp {
+		start := time.Now()
 		res, err := node.Execute(c, vars, s)
+		// LOGZ.IO GRAFANA CHANGE :: Expose intermediate expression node results
+		if trace != nil {
+			trace.record(node, time.Since(start), res, err)
+		}
+		// LOGZ.IO GRAFANA CHANGE :: end
 		if err != nil {
 			return nil, err
 		}
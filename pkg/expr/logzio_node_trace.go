@@ -0,0 +1,68 @@
+package expr
+
+// LOGZ.IO GRAFANA CHANGE :: Expose intermediate expression node results
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+type nodeTraceKey struct{}
+
+// NodeTrace collects the outcome of every node executed by a data pipeline.
+// It is attached to the context of the execution with WithNodeTrace.
+type NodeTrace struct {
+	mtx   sync.Mutex
+	nodes []NodeTraceEntry
+}
+
+// NodeTraceEntry is the outcome of the execution of a single node.
+type NodeTraceEntry struct {
+	RefID    string
+	NodeType NodeType
+	Duration time.Duration
+	Frames   data.Frames
+	Error    error
+}
+
+// WithNodeTrace returns a context that makes data pipelines record the outcome of their nodes in trace.
+func WithNodeTrace(ctx context.Context, trace *NodeTrace) context.Context {
+	return context.WithValue(ctx, nodeTraceKey{}, trace)
+}
+
+func nodeTraceFromContext(ctx context.Context) *NodeTrace {
+	trace, _ := ctx.Value(nodeTraceKey{}).(*NodeTrace)
+	return trace
+}
+
+// Nodes returns the recorded nodes in execution order.
+func (t *NodeTrace) Nodes() []NodeTraceEntry {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	nodes := make([]NodeTraceEntry, len(t.nodes))
+	copy(nodes, t.nodes)
+	return nodes
+}
+
+func (t *NodeTrace) record(node Node, duration time.Duration, res mathexp.Results, err error) {
+	entry := NodeTraceEntry{
+		RefID:    node.RefID(),
+		NodeType: node.NodeType(),
+		Duration: duration,
+		Error:    err,
+	}
+	if err == nil {
+		entry.Frames = res.Values.AsDataFrames(node.RefID())
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.nodes = append(t.nodes, entry)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
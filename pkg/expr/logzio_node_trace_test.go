@@ -0,0 +1,76 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeTrace(t *testing.T) {
+	dsDF := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+		data.NewField("value", nil, []*float64{fp(2)}))
+
+	s := Service{
+		cfg:            setting.NewCfg(),
+		dataService:    &mockEndpoint{Frames: []*data.Frame{dsDF}},
+		secretsService: secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore()),
+	}
+
+	queries := []Query{
+		{
+			RefID: "A",
+			DataSource: &models.DataSource{
+				OrgId: 1,
+				Uid:   "test",
+				Type:  "test",
+			},
+			JSON: json.RawMessage(`{ "datasource": { "uid": "1" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+		},
+		{
+			RefID:      "B",
+			DataSource: DataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "reduce", "expression": "$A", "reducer": "last" }`),
+		},
+		{
+			RefID:      "C",
+			DataSource: DataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$B > 1" }`),
+		},
+	}
+
+	pl, err := s.BuildPipeline(&Request{Queries: queries})
+	require.NoError(t, err)
+
+	t.Run("records every node in execution order", func(t *testing.T) {
+		trace := &NodeTrace{}
+		_, err := s.ExecutePipeline(WithNodeTrace(context.Background(), trace), pl)
+		require.NoError(t, err)
+
+		nodes := trace.Nodes()
+		require.Len(t, nodes, 3)
+		require.Equal(t, "A", nodes[0].RefID)
+		require.Equal(t, TypeDatasourceNode, nodes[0].NodeType)
+		require.Equal(t, "B", nodes[1].RefID)
+		require.Equal(t, TypeCMDNode, nodes[1].NodeType)
+		require.Equal(t, "C", nodes[2].RefID)
+		for _, node := range nodes {
+			require.NoError(t, node.Error)
+			require.NotEmpty(t, node.Frames)
+		}
+	})
+
+	t.Run("does not record anything without a trace in the context", func(t *testing.T) {
+		require.Nil(t, nodeTraceFromContext(context.Background()))
+		_, err := s.ExecutePipeline(context.Background(), pl)
+		require.NoError(t, err)
+	})
+}
@@ -4,6 +4,9 @@ package models
 import (
 	"net/http"
 	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 const LogzioHeadersCtxKey string = "logzioHeaders"
@@ -11,27 +14,101 @@ const LogzioRequestIdHeaderName string = "x-request-id"
 
 type LogzIoHeaders struct {
 	RequestHeaders http.Header
+	// DatasourceWhitelist lists the headers passed on in addition to the configured whitelist, see LogzioHeadersWhitelistFromJsonData.
+	DatasourceWhitelist []string
+	// Settings are the header settings of the configuration, see setting.Cfg.LogzioHeaders. No header is passed on when nil.
+	Settings *setting.LogzioHeadersSettings
+	// Forwarded marks RequestHeaders that were already passed on by Grafana, e.g. the headers of a datasource request,
+	// and so carry the names headers were renamed to. The names headers are renamed to are rejected otherwise, so that
+	// an incoming request cannot spoof a renamed header.
+	Forwarded bool
+}
+
+// LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
+// LogzioHeadersWhitelistJsonDataKey is the datasource jsonData key that lists the headers passed on to the datasource
+// in addition to the whitelist of the configuration.
+const LogzioHeadersWhitelistJsonDataKey = "logzioHeadersWhitelist"
+
+const LogzioRedactedHeaderValue = "[REDACTED]"
+
+// LogzioHeadersWhitelistFromJsonData returns the headers the datasource jsonData adds to the whitelist.
+func LogzioHeadersWhitelistFromJsonData(jsonData *simplejson.Json) []string {
+	if jsonData == nil {
+		return nil
+	}
+	return jsonData.Get(LogzioHeadersWhitelistJsonDataKey).MustStringArray()
+}
+
+// forwardedHeader returns the name and the value the header is passed on with, or false if it is not passed on.
+// The whitelist applies to the name of the incoming header, before it is renamed.
+func (logzioHeaders *LogzIoHeaders) forwardedHeader(name string, value string) (string, string, bool) {
+	headers := logzioHeaders.Settings
+	if headers == nil {
+		return "", "", false
+	}
+	lowerName := strings.ToLower(name)
+
+	if renamed, ok := headers.Rename[lowerName]; ok {
+		if !logzioHeaders.isWhitelisted(headers, lowerName) {
+			return "", "", false
+		}
+		name = renamed
+	} else if isRenameTarget(headers, lowerName) {
+		if !logzioHeaders.Forwarded {
+			return "", "", false
+		}
+	} else if !logzioHeaders.isWhitelisted(headers, lowerName) {
+		return "", "", false
+	}
+
+	if pattern, ok := headers.Redact[lowerName]; ok {
+		value = pattern.ReplaceAllString(value, LogzioRedactedHeaderValue)
+	} else if pattern, ok := headers.Redact[strings.ToLower(name)]; ok {
+		value = pattern.ReplaceAllString(value, LogzioRedactedHeaderValue)
+	}
+
+	return name, value, true
+}
+
+func (logzioHeaders *LogzIoHeaders) isWhitelisted(headers *setting.LogzioHeadersSettings, lowerName string) bool {
+	for _, whitelistedHeader := range headers.Whitelist {
+		if whitelistedHeader == lowerName {
+			return true
+		}
+	}
+	for _, whitelistedHeader := range logzioHeaders.DatasourceWhitelist {
+		if strings.EqualFold(whitelistedHeader, lowerName) {
+			return true
+		}
+	}
+	return false
 }
 
-var logzioHeadersWhitelist = []string{
-	"x-auth-token",
-	"x-api-token",
-	"user-context",
-	LogzioRequestIdHeaderName,
-	"cookie",
-	"x-logz-csrf-token",
-	"x-logz-csrf-token-v2",
+func isRenameTarget(headers *setting.LogzioHeadersSettings, lowerName string) bool {
+	for _, renamed := range headers.Rename {
+		if strings.EqualFold(renamed, lowerName) {
+			return true
+		}
+	}
+	return false
 }
 
+// LOGZ.IO GRAFANA CHANGE :: end
+
 func (logzioHeaders *LogzIoHeaders) GetDatasourceQueryHeaders(grafanaGeneratedHeaders http.Header) http.Header {
 	datasourceRequestHeaders := grafanaGeneratedHeaders.Clone()
 	logzioGrafanaRequestHeaders := logzioHeaders.RequestHeaders
 
-	for _, whitelistedHeader := range logzioHeadersWhitelist {
-		if requestHeader := logzioGrafanaRequestHeaders.Get(whitelistedHeader); requestHeader != "" {
-			datasourceRequestHeaders.Set(whitelistedHeader, requestHeader)
+	// LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
+	for requestHeader, values := range logzioGrafanaRequestHeaders {
+		if len(values) == 0 || values[0] == "" {
+			continue
+		}
+		if name, value, ok := logzioHeaders.forwardedHeader(requestHeader, values[0]); ok {
+			datasourceRequestHeaders.Set(name, value)
 		}
 	}
+	// LOGZ.IO GRAFANA CHANGE :: end
 
 	return datasourceRequestHeaders
 }
@@ -41,11 +118,14 @@ func (logzioHeaders *LogzIoHeaders) GetDatasourceQueryHeader(grafanaGeneratedHea
 	headers := map[string]string{}
 
 	for k, v := range grafanaGeneratedHeaders {
-		for _, whitelistedHeader := range logzioHeadersWhitelist {
-			if strings.EqualFold(k, whitelistedHeader) {
-				headers[k] = v[0]
-			}
+		// LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
+		if len(v) == 0 {
+			continue
+		}
+		if name, value, ok := logzioHeaders.forwardedHeader(k, v[0]); ok {
+			headers[name] = value
 		}
+		// LOGZ.IO GRAFANA CHANGE :: end
 	}
 
 	return headers
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestLogzIoHeaders(t *testing.T) {
+	settings := &setting.LogzioHeadersSettings{
+		Whitelist: []string{"x-auth-token", "x-account-id", "cookie"},
+		Rename:    map[string]string{"x-account-id": "X-Logzio-Account"},
+		Redact:    map[string]*regexp.Regexp{"cookie": regexp.MustCompile(`session=[^;]*`)},
+	}
+
+	requestHeaders := http.Header{}
+	requestHeaders.Set("X-Auth-Token", "token")
+	requestHeaders.Set("X-Account-Id", "123")
+	requestHeaders.Set("Cookie", "a=1; session=abc")
+	requestHeaders.Set("X-Datasource-Only", "ds")
+	requestHeaders.Set("X-Not-Whitelisted", "secret")
+
+	t.Run("GetDatasourceQueryHeaders passes on, renames and redacts whitelisted headers", func(t *testing.T) {
+		h := LogzIoHeaders{RequestHeaders: requestHeaders, Settings: settings}
+		headers := h.GetDatasourceQueryHeaders(http.Header{"Fromalert": []string{"true"}})
+
+		require.Equal(t, "true", headers.Get("FromAlert"))
+		require.Equal(t, "token", headers.Get("X-Auth-Token"))
+		require.Equal(t, "123", headers.Get("X-Logzio-Account"))
+		require.Empty(t, headers.Get("X-Account-Id"))
+		require.Equal(t, "a=1; "+LogzioRedactedHeaderValue, headers.Get("Cookie"))
+		require.Empty(t, headers.Get("X-Datasource-Only"))
+		require.Empty(t, headers.Get("X-Not-Whitelisted"))
+	})
+
+	t.Run("GetDatasourceQueryHeader adds the headers whitelisted by the datasource", func(t *testing.T) {
+		jsonData := simplejson.NewFromAny(map[string]interface{}{
+			LogzioHeadersWhitelistJsonDataKey: []interface{}{"x-datasource-only"},
+		})
+		h := LogzIoHeaders{DatasourceWhitelist: LogzioHeadersWhitelistFromJsonData(jsonData), Settings: settings}
+		headers := h.GetDatasourceQueryHeader(requestHeaders)
+
+		require.Equal(t, map[string]string{
+			"X-Auth-Token":      "token",
+			"X-Logzio-Account":  "123",
+			"Cookie":            "a=1; " + LogzioRedactedHeaderValue,
+			"X-Datasource-Only": "ds",
+		}, headers)
+	})
+
+	t.Run("renamed headers pass through a second filtering of forwarded headers", func(t *testing.T) {
+		once := (&LogzIoHeaders{Settings: settings}).GetDatasourceQueryHeader(requestHeaders)
+
+		again := http.Header{}
+		for k, v := range once {
+			again.Set(k, v)
+		}
+		h := LogzIoHeaders{Settings: settings, Forwarded: true}
+		require.Equal(t, "123", h.GetDatasourceQueryHeader(again)["X-Logzio-Account"])
+	})
+
+	t.Run("incoming headers cannot spoof a renamed header", func(t *testing.T) {
+		spoofed := http.Header{}
+		spoofed.Set("X-Logzio-Account", "456")
+		h := LogzIoHeaders{Settings: &setting.LogzioHeadersSettings{
+			Whitelist: []string{"x-account-id", "x-logzio-account"},
+			Rename:    settings.Rename,
+		}}
+
+		require.Empty(t, h.GetDatasourceQueryHeader(spoofed))
+		h.RequestHeaders = spoofed
+		require.Empty(t, h.GetDatasourceQueryHeaders(http.Header{}).Get("X-Logzio-Account"))
+	})
+
+	t.Run("no header is passed on without settings", func(t *testing.T) {
+		h := LogzIoHeaders{}
+		require.Empty(t, h.GetDatasourceQueryHeader(requestHeaders))
+	})
+}
@@ -75,7 +75,7 @@ func TestPluginManager_int_init(t *testing.T) {
 	am := azuremonitor.ProvideService(cfg, hcp, tracer)
 	cw := cloudwatch.ProvideService(cfg, hcp)
 	cm := cloudmonitoring.ProvideService(hcp, tracer)
-	es := elasticsearch.ProvideService(hcp)
+	es := elasticsearch.ProvideService(hcp, cfg)
 	grap := graphite.ProvideService(hcp, tracer)
 	idb := influxdb.ProvideService(hcp)
 	lk := loki.ProvideService(hcp, tracer)
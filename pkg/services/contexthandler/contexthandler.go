@@ -32,7 +32,7 @@ const (
 	InvalidAPIKey           = "invalid API key"
 )
 
-var RoutesWhitelistedForAuth = [...]string{"/internal/alert/api/v1/eval", "/internal/alert/api/v1/process"} // LOGZ.IO GRAFANA CHANGE :: DEV-33653 - Skip auth db queries for whitelisted endpoints
+var RoutesWhitelistedForAuth = [...]string{"/internal/alert/api/v1/eval", "/internal/alert/api/v1/eval/batch", "/internal/alert/api/v1/process", "/internal/alert/api/v1/eval-and-process"} // LOGZ.IO GRAFANA CHANGE :: DEV-33653 - Skip auth db queries for whitelisted endpoints
 
 const ServiceName = "ContextHandler"
 
@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -84,6 +85,11 @@ type API struct {
 	Policies            *provisioning.NotificationPolicyService
 	ContactPointService *provisioning.ContactPointService
 	AlertRules          *provisioning.AlertRuleService
+	MuteTimings         *provisioning.MuteTimingService // LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+	Templates           *provisioning.TemplateService   // LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
+	// LOGZ.IO GRAFANA CHANGE :: Idempotent alert processing
+	ProcessedRequestsCache remotecache.CacheStorage
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 // RegisterAPIEndpoints registers API handlers
@@ -155,6 +161,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			api.InstanceStore,
 			logger,
 			api.SQLStore,
+			api.ProcessedRequestsCache,
 		),
 	), m)
 	// LOGZ.IO GRAFANA CHANGE :: end
@@ -164,5 +171,10 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		policies:            api.Policies,
 		contactPointService: api.ContactPointService,
 		alertRules:          api.AlertRules,
+		muteTimings:         api.MuteTimings, // LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+		templates:           api.Templates,   // LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
+		// LOGZ.IO GRAFANA CHANGE :: Notification delivery log
+		deliveries: api.MultiOrgAlertmanager,
+		// LOGZ.IO GRAFANA CHANGE :: end
 	}), m)
 }
@@ -57,17 +57,30 @@ func (srv PrometheusSrv) RouteGetAlertStatuses(c *models.ReqContext) response.Re
 		}
 
 		alertResponse.Data.Alerts = append(alertResponse.Data.Alerts, &apimodels.Alert{
-			Labels:      alertState.GetLabels(labelOptions...),
-			Annotations: alertState.Annotations,
-			State:       alertState.State.String(),
-			ActiveAt:    &startsAt,
-			Value:       valString,
+			Labels:          alertState.GetLabels(labelOptions...),
+			Annotations:     alertState.Annotations,
+			State:           alertState.State.String(),
+			ActiveAt:        &startsAt,
+			Value:           valString,
+			KeepFiringSince: keepFiringSince(alertState), // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
 		})
 	}
 
 	return response.JSON(http.StatusOK, alertResponse)
 }
 
+// LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+// keepFiringSince returns when the condition of the alert cleared if it is in the keep firing window of its rule.
+func keepFiringSince(alertState *state.State) *time.Time {
+	if !alertState.IsKeptFiring() {
+		return nil
+	}
+	since := alertState.KeptFiringSince
+	return &since
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 func formatValues(alertState *state.State) string {
 	var fv string
 	values := alertState.GetLastEvaluationValuesForCondition()
@@ -195,6 +208,11 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 			Type:           apiv1.RuleTypeAlerting,
 			LastEvaluation: time.Time{},
 		}
+		// LOGZ.IO GRAFANA CHANGE :: Recording rules
+		if rule.IsRecordingRule() {
+			newRule.Type = apiv1.RuleTypeRecording
+		}
+		// LOGZ.IO GRAFANA CHANGE :: end
 
 		for _, alertState := range srv.manager.GetStatesForRuleUID(c.OrgId, rule.UID) {
 			activeAt := alertState.StartsAt
@@ -204,11 +222,12 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 			}
 
 			alert := &apimodels.Alert{
-				Labels:      alertState.GetLabels(labelOptions...),
-				Annotations: alertState.Annotations,
-				State:       alertState.State.String(),
-				ActiveAt:    &activeAt,
-				Value:       valString,
+				Labels:          alertState.GetLabels(labelOptions...),
+				Annotations:     alertState.Annotations,
+				State:           alertState.State.String(),
+				ActiveAt:        &activeAt,
+				Value:           valString,
+				KeepFiringSince: keepFiringSince(alertState), // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
 			}
 
 			if alertState.LastEvaluationTime.After(newRule.LastEvaluation) {
@@ -383,6 +402,11 @@ func (srv PrometheusSrv) LogzioRouteGetRuleStatuses(c *models.ReqContext) respon
 			Type:           apiv1.RuleTypeAlerting,
 			LastEvaluation: time.Time{},
 		}
+		// LOGZ.IO GRAFANA CHANGE :: Recording rules
+		if rule.IsRecordingRule() {
+			newRule.Type = apiv1.RuleTypeRecording
+		}
+		// LOGZ.IO GRAFANA CHANGE :: end
 
 		for _, alertState := range srv.manager.GetStatesForRuleUID(c.OrgId, rule.UID) {
 			activeAt := alertState.StartsAt
@@ -392,11 +416,12 @@ func (srv PrometheusSrv) LogzioRouteGetRuleStatuses(c *models.ReqContext) respon
 			}
 
 			alert := &apimodels.Alert{
-				Labels:      alertState.GetLabels(labelOptions...),
-				Annotations: alertState.Annotations,
-				State:       alertState.State.String(),
-				ActiveAt:    &activeAt,
-				Value:       valString,
+				Labels:          alertState.GetLabels(labelOptions...),
+				Annotations:     alertState.Annotations,
+				State:           alertState.State.String(),
+				ActiveAt:        &activeAt,
+				Value:           valString,
+				KeepFiringSince: keepFiringSince(alertState), // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
 			}
 
 			if alertState.LastEvaluationTime.After(newRule.LastEvaluation) {
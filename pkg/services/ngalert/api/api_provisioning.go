@@ -22,7 +22,10 @@ type ProvisioningSrv struct {
 	policies            NotificationPolicyService
 	contactPointService ContactPointService
 	alertRules          AlertRuleService
+	muteTimings         MuteTimingService // LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+	templates           TemplateService   // LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
 	folderService       dashboards.FolderService
+	deliveries          DeliveryLogService // LOGZ.IO GRAFANA CHANGE :: Notification delivery log
 }
 
 type ContactPointService interface {
@@ -32,12 +35,41 @@ type ContactPointService interface {
 	DeleteContactPoint(ctx context.Context, orgID int64, uid string) error
 }
 
+// LOGZ.IO GRAFANA CHANGE :: Notification delivery log
+type DeliveryLogService interface {
+	LastDelivery(orgID int64, integrationUID string) (definitions.NotificationDelivery, bool)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 type NotificationPolicyService interface {
 	GetPolicyTree(ctx context.Context, orgID int64) (definitions.Route, error)
 	UpdatePolicyTree(ctx context.Context, orgID int64, tree definitions.Route, p alerting_models.Provenance) error
 	ResetPolicyTree(ctx context.Context, orgID int64) (definitions.Route, error)
 }
 
+// LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+type MuteTimingService interface {
+	GetMuteTimings(ctx context.Context, orgID int64) ([]definitions.MuteTimeInterval, error)
+	GetMuteTiming(ctx context.Context, name string, orgID int64) (definitions.MuteTimeInterval, error)
+	CreateMuteTiming(ctx context.Context, mt definitions.MuteTimeInterval, orgID int64) (definitions.MuteTimeInterval, error)
+	UpdateMuteTiming(ctx context.Context, mt definitions.MuteTimeInterval, orgID int64) (definitions.MuteTimeInterval, error)
+	DeleteMuteTiming(ctx context.Context, name string, orgID int64, provenance alerting_models.Provenance) error
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
+type TemplateService interface {
+	GetTemplates(ctx context.Context, orgID int64) ([]definitions.MessageTemplate, error)
+	GetTemplate(ctx context.Context, name string, orgID int64) (definitions.MessageTemplate, error)
+	CreateTemplate(ctx context.Context, tmpl definitions.MessageTemplate, orgID int64) (definitions.MessageTemplate, error)
+	UpdateTemplate(ctx context.Context, tmpl definitions.MessageTemplate, orgID int64) (definitions.MessageTemplate, error)
+	DeleteTemplate(ctx context.Context, name string, orgID int64, provenance alerting_models.Provenance) error
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 type AlertRuleService interface {
 	GetAlertRules(ctx context.Context, orgID int64, dashboardUid string, panelId int64) ([]alerting_models.AlertRule, error) // LOGZ.IO GRAFANA CHANGE :: DEV-33330 - API to return all alert rules
 	GetAlertRule(ctx context.Context, orgID int64, ruleUID string) (alerting_models.AlertRule, alerting_models.Provenance, error)
@@ -87,6 +119,7 @@ func (srv *ProvisioningSrv) RouteGetContactPoints(c *models.ReqContext) response
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
+	srv.setLastDeliveries(c.OrgId, cps) // LOGZ.IO GRAFANA CHANGE :: Notification delivery log
 	return response.JSON(http.StatusOK, cps)
 }
 
@@ -97,11 +130,27 @@ func (srv *ProvisioningSrv) RouteInternalGetContactPoints(c *models.ReqContext)
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
+	srv.setLastDeliveries(c.OrgId, cps) // LOGZ.IO GRAFANA CHANGE :: Notification delivery log
 	return response.JSON(http.StatusOK, cps)
 }
 
 // LOGZ.IO GRAFANA CHANGE :: end
 
+// LOGZ.IO GRAFANA CHANGE :: Notification delivery log
+// setLastDeliveries sets the latest notification attempt of each contact point, if any.
+func (srv *ProvisioningSrv) setLastDeliveries(orgID int64, cps []definitions.EmbeddedContactPoint) {
+	if srv.deliveries == nil {
+		return
+	}
+	for i := range cps {
+		if lastDelivery, ok := srv.deliveries.LastDelivery(orgID, cps[i].UID); ok {
+			cps[i].LastDelivery = &lastDelivery
+		}
+	}
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 func (srv *ProvisioningSrv) RoutePostContactPoint(c *models.ReqContext, cp definitions.EmbeddedContactPoint) response.Response {
 	// TODO: provenance is hardcoded for now, change it later to make it more flexible
 	contactPoint, err := srv.contactPointService.CreateContactPoint(c.Req.Context(), c.OrgId, cp, alerting_models.ProvenanceAPI)
@@ -260,3 +309,125 @@ func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *models.ReqContext, ag defi
 	}
 	return response.JSON(http.StatusOK, ag)
 }
+
+// LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+func (srv *ProvisioningSrv) RouteGetMuteTimings(c *models.ReqContext) response.Response {
+	timings, err := srv.muteTimings.GetMuteTimings(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, timings)
+}
+
+func (srv *ProvisioningSrv) RouteGetMuteTiming(c *models.ReqContext, name string) response.Response {
+	timing, err := srv.muteTimings.GetMuteTiming(c.Req.Context(), name, c.OrgId)
+	if errors.Is(err, provisioning.ErrNotFound) {
+		return response.Error(http.StatusNotFound, err.Error(), nil)
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, timing)
+}
+
+func (srv *ProvisioningSrv) RoutePostMuteTiming(c *models.ReqContext, mt definitions.MuteTimeInterval) response.Response {
+	mt.Provenance = alerting_models.ProvenanceAPI
+	created, err := srv.muteTimings.CreateMuteTiming(c.Req.Context(), mt, c.OrgId)
+	if errors.Is(err, provisioning.ErrValidation) {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusCreated, created)
+}
+
+func (srv *ProvisioningSrv) RoutePutMuteTiming(c *models.ReqContext, mt definitions.MuteTimeInterval, name string) response.Response {
+	mt.Name = name
+	mt.Provenance = alerting_models.ProvenanceAPI
+	updated, err := srv.muteTimings.UpdateMuteTiming(c.Req.Context(), mt, c.OrgId)
+	if errors.Is(err, provisioning.ErrValidation) {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+	if errors.Is(err, provisioning.ErrNotFound) {
+		return response.Error(http.StatusNotFound, err.Error(), nil)
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusAccepted, updated)
+}
+
+func (srv *ProvisioningSrv) RouteDeleteMuteTiming(c *models.ReqContext, name string) response.Response {
+	err := srv.muteTimings.DeleteMuteTiming(c.Req.Context(), name, c.OrgId, alerting_models.ProvenanceAPI)
+	if errors.Is(err, provisioning.ErrNotFound) {
+		return response.Error(http.StatusNotFound, err.Error(), nil)
+	}
+	if errors.Is(err, provisioning.ErrInUse) {
+		return response.Error(http.StatusConflict, err.Error(), nil)
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusNoContent, "")
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
+func (srv *ProvisioningSrv) RouteGetTemplates(c *models.ReqContext) response.Response {
+	templates, err := srv.templates.GetTemplates(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, templates)
+}
+
+func (srv *ProvisioningSrv) RouteGetTemplate(c *models.ReqContext, name string) response.Response {
+	template, err := srv.templates.GetTemplate(c.Req.Context(), name, c.OrgId)
+	if errors.Is(err, provisioning.ErrNotFound) {
+		return response.Error(http.StatusNotFound, err.Error(), nil)
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, template)
+}
+
+func (srv *ProvisioningSrv) RoutePostTemplate(c *models.ReqContext, tmpl definitions.MessageTemplate) response.Response {
+	tmpl.Provenance = alerting_models.ProvenanceAPI
+	created, err := srv.templates.CreateTemplate(c.Req.Context(), tmpl, c.OrgId)
+	if errors.Is(err, provisioning.ErrValidation) {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusCreated, created)
+}
+
+func (srv *ProvisioningSrv) RoutePutTemplate(c *models.ReqContext, tmpl definitions.MessageTemplate, name string) response.Response {
+	tmpl.Name = name
+	tmpl.Provenance = alerting_models.ProvenanceAPI
+	updated, err := srv.templates.UpdateTemplate(c.Req.Context(), tmpl, c.OrgId)
+	if errors.Is(err, provisioning.ErrValidation) {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+	if errors.Is(err, provisioning.ErrNotFound) {
+		return response.Error(http.StatusNotFound, err.Error(), nil)
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusAccepted, updated)
+}
+
+func (srv *ProvisioningSrv) RouteDeleteTemplate(c *models.ReqContext, name string) response.Response {
+	err := srv.templates.DeleteTemplate(c.Req.Context(), name, c.OrgId, alerting_models.ProvenanceAPI)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusNoContent, "")
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io" // LOGZ.IO GRAFANA CHANGE :: Prometheus rule-file export and import of alert rules
 	"net/http"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/promrules" // LOGZ.IO GRAFANA CHANGE :: Prometheus rule-file export and import of alert rules
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -455,6 +457,12 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimode
 			RuleGroup:       r.RuleGroup,
 			NoDataState:     apimodels.NoDataState(r.NoDataState),
 			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
+			Record:          r.Record,                        // LOGZ.IO GRAFANA CHANGE :: Recording rules
+			QueryOffset:     model.Duration(r.QueryOffset),   // LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+			IsPaused:        r.IsPaused,                      // LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+			KeepFiringFor:   model.Duration(r.KeepFiringFor), // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+			MaxInstances:    r.MaxInstances,                  // LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+			EvictionDelay:   model.Duration(r.EvictionDelay), // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
 		},
 	}
 	gettableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
@@ -653,3 +661,84 @@ func (srv RulerSrv) LogzioRouteGetRulesConfig(c *models.ReqContext) response.Res
 }
 
 // LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Prometheus rule-file export and import of alert rules
+func (srv RulerSrv) promRulesService() *promrules.Service {
+	return promrules.NewService(srv.store, srv.xactManager, srv.cfg.BaseInterval, srv.cfg.DefaultRuleEvaluationInterval, srv.log)
+}
+
+// RouteGetNamespacePrometheusRules exports the rules of a folder, that the user can query the data sources of, as a
+// Prometheus rule file.
+func (srv RulerSrv) RouteGetNamespacePrometheusRules(c *models.ReqContext) response.Response {
+	namespaceTitle := web.Params(c.Req)[":Namespace"]
+	namespace, err := srv.store.GetNamespaceByTitle(c.Req.Context(), namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, false)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	hasAccess := func(evaluator accesscontrol.Evaluator) bool {
+		return accesscontrol.HasAccess(srv.ac, c)(accesscontrol.ReqViewer, evaluator)
+	}
+	file, err := srv.promRulesService().Export(c.Req.Context(), c.SignedInUser.OrgId, namespace.Uid, func(rule *ngmodels.AlertRule) bool {
+		return authorizeDatasourceAccessForRule(rule, hasAccess)
+	})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to export rules")
+	}
+	body, err := promrules.Marshal(file)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to export rules")
+	}
+	return response.Respond(http.StatusOK, body).SetHeader("Content-Type", "application/yaml")
+}
+
+// RoutePostNamespacePrometheusRules imports a Prometheus rule file into a folder. Rules with a native expr query the
+// data source given by the datasource_uid query parameter.
+func (srv RulerSrv) RoutePostNamespacePrometheusRules(c *models.ReqContext) response.Response {
+	namespaceTitle := web.Params(c.Req)[":Namespace"]
+	namespace, err := srv.store.GetNamespaceByTitle(c.Req.Context(), namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	body, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read rule file")
+	}
+	file, err := promrules.Parse(body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	hasAccess := func(evaluator accesscontrol.Evaluator) bool {
+		return accesscontrol.HasAccess(srv.ac, c)(accesscontrol.ReqOrgAdminOrEditor, evaluator)
+	}
+	result, err := srv.promRulesService().Import(c.Req.Context(), c.SignedInUser.OrgId, namespace.Uid, file, promrules.ImportOptions{
+		DatasourceUID: c.Query("datasource_uid"),
+		Authorize: func(rule *ngmodels.AlertRule) bool {
+			return authorizeDatasourceAccessForRule(rule, hasAccess)
+		},
+		QuotaReached: func(ctx context.Context) (bool, error) {
+			return srv.QuotaService.CheckQuotaReached(ctx, "alert_rule", &quota.ScopeParameters{
+				OrgId:  c.OrgId,
+				UserId: c.UserId,
+			}) // alert rule is table name
+		},
+		RuleUpdated: srv.scheduleService.UpdateAlertRule,
+	})
+	if errors.Is(err, promrules.ErrInvalidRules) {
+		return response.JSON(http.StatusBadRequest, result)
+	}
+	if errors.Is(err, promrules.ErrQuotaReached) {
+		return ErrResp(http.StatusForbidden, err, "")
+	}
+	if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
+		return ErrResp(http.StatusBadRequest, err, "failed to import rules")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to import rules")
+	}
+	return response.JSON(http.StatusAccepted, result)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/prometheus/common/model"
+
 	"github.com/grafana/grafana/pkg/models"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -86,6 +88,19 @@ func validateRuleNode(
 		}
 	}
 
+	// LOGZ.IO GRAFANA CHANGE :: Recording rules
+	// the record of a partial rule is validated once it is patched with the queries of the existing rule
+	if record := ruleNode.GrafanaManagedAlert.Record; record != nil && len(ruleNode.GrafanaManagedAlert.Data) != 0 {
+		if err := record.Validate(ruleNode.GrafanaManagedAlert.Data); err != nil {
+			return nil, err
+		}
+		// the recorded query or expression is the condition of the rule if none is specified
+		if ruleNode.GrafanaManagedAlert.Condition == "" {
+			ruleNode.GrafanaManagedAlert.Condition = record.From
+		}
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
 	if len(ruleNode.GrafanaManagedAlert.Data) != 0 {
 		cond := ngmodels.Condition{
 			Condition: ruleNode.GrafanaManagedAlert.Condition,
@@ -108,8 +123,29 @@ func validateRuleNode(
 		RuleGroup:       groupName,
 		NoDataState:     noDataState,
 		ExecErrState:    errorState,
+		Record:          ruleNode.GrafanaManagedAlert.Record,   // LOGZ.IO GRAFANA CHANGE :: Recording rules
+		IsPaused:        ruleNode.GrafanaManagedAlert.IsPaused, // LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
 	}
 
+	// LOGZ.IO GRAFANA CHANGE :: Optional rule fields
+	setDuration := func(field ngmodels.AlertRuleFields, value *model.Duration, ruleValue *time.Duration) {
+		if value == nil {
+			newAlertRule.UnsetFields |= field
+			return
+		}
+		*ruleValue = time.Duration(*value)
+	}
+	setDuration(ngmodels.QueryOffsetField, ruleNode.GrafanaManagedAlert.QueryOffset, &newAlertRule.QueryOffset)
+	setDuration(ngmodels.KeepFiringForField, ruleNode.GrafanaManagedAlert.KeepFiringFor, &newAlertRule.KeepFiringFor)
+	setDuration(ngmodels.EvictionDelayField, ruleNode.GrafanaManagedAlert.EvictionDelay, &newAlertRule.EvictionDelay)
+	// a negative maximum number of alert instances removes the override of the rule
+	if maxInstances := ruleNode.GrafanaManagedAlert.MaxInstances; maxInstances == nil {
+		newAlertRule.UnsetFields |= ngmodels.MaxInstancesField
+	} else if *maxInstances >= 0 {
+		newAlertRule.MaxInstances = maxInstances
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
 	if ruleNode.ApiRuleNode != nil {
 		newAlertRule.For = time.Duration(ruleNode.ApiRuleNode.For)
 		newAlertRule.Annotations = ruleNode.ApiRuleNode.Annotations
@@ -714,3 +714,135 @@ func TestValidateRuleNodeIntervalFailures(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRuleNode_OptionalFields(t *testing.T) {
+	cfg := config(t)
+	duration := func(d time.Duration) *model.Duration {
+		md := model.Duration(d)
+		return &md
+	}
+	maxInstances := func(v int64) *int64 {
+		return &v
+	}
+
+	testCases := []struct {
+		name     string
+		rule     func(r *apimodels.PostableGrafanaRule)
+		expected func(t *testing.T, patched *models.AlertRule)
+	}{
+		{
+			name: "keeps the query offset of the existing rule when it is not set",
+			rule: func(r *apimodels.PostableGrafanaRule) {},
+			expected: func(t *testing.T, patched *models.AlertRule) {
+				require.Equal(t, 5*time.Minute, patched.QueryOffset)
+			},
+		},
+		{
+			name: "resets the query offset when it is zero",
+			rule: func(r *apimodels.PostableGrafanaRule) { r.QueryOffset = duration(0) },
+			expected: func(t *testing.T, patched *models.AlertRule) {
+				require.Zero(t, patched.QueryOffset)
+			},
+		},
+		{
+			name: "updates the query offset",
+			rule: func(r *apimodels.PostableGrafanaRule) { r.QueryOffset = duration(10 * time.Minute) },
+			expected: func(t *testing.T, patched *models.AlertRule) {
+				require.Equal(t, 10*time.Minute, patched.QueryOffset)
+			},
+		},
+		{
+			name: "keeps the keep firing for of the existing rule when it is not set",
+			rule: func(r *apimodels.PostableGrafanaRule) {},
+			expected: func(t *testing.T, patched *models.AlertRule) {
+				require.Equal(t, 5*time.Minute, patched.KeepFiringFor)
+			},
+		},
+		{
+			name: "resets the keep firing for when it is zero",
+			rule: func(r *apimodels.PostableGrafanaRule) { r.KeepFiringFor = duration(0) },
+			expected: func(t *testing.T, patched *models.AlertRule) {
+				require.Zero(t, patched.KeepFiringFor)
+			},
+		},
+		{
+			name: "updates the keep firing for",
+			rule: func(r *apimodels.PostableGrafanaRule) { r.KeepFiringFor = duration(10 * time.Minute) },
+			expected: func(t *testing.T, patched *models.AlertRule) {
+				require.Equal(t, 10*time.Minute, patched.KeepFiringFor)
+			},
+		},
+		{
+			name: "keeps the eviction delay of the existing rule when it is not set",
+			rule: func(r *apimodels.PostableGrafanaRule) {},
+			expected: func(t *testing.T, patched *models.AlertRule) {
+				require.Equal(t, 5*time.Minute, patched.EvictionDelay)
+			},
+		},
+		{
+			name: "resets the eviction delay when it is zero",
+			rule: func(r *apimodels.PostableGrafanaRule) { r.EvictionDelay = duration(0) },
+			expected: func(t *testing.T, patched *models.AlertRule) {
+				require.Zero(t, patched.EvictionDelay)
+			},
+		},
+		{
+			name: "updates the eviction delay",
+			rule: func(r *apimodels.PostableGrafanaRule) { r.EvictionDelay = duration(10 * time.Minute) },
+			expected: func(t *testing.T, patched *models.AlertRule) {
+				require.Equal(t, 10*time.Minute, patched.EvictionDelay)
+			},
+		},
+		{
+			name: "keeps the maximum number of alert instances of the existing rule when it is not set",
+			rule: func(r *apimodels.PostableGrafanaRule) {},
+			expected: func(t *testing.T, patched *models.AlertRule) {
+				require.Equal(t, maxInstances(100), patched.MaxInstances)
+			},
+		},
+		{
+			name: "removes the maximum number of alert instances of the rule when it is negative",
+			rule: func(r *apimodels.PostableGrafanaRule) { r.MaxInstances = maxInstances(-1) },
+			expected: func(t *testing.T, patched *models.AlertRule) {
+				require.Nil(t, patched.MaxInstances)
+			},
+		},
+		{
+			name: "makes the alert instances of the rule unlimited when the maximum is zero",
+			rule: func(r *apimodels.PostableGrafanaRule) { r.MaxInstances = maxInstances(0) },
+			expected: func(t *testing.T, patched *models.AlertRule) {
+				require.Equal(t, maxInstances(0), patched.MaxInstances)
+			},
+		},
+		{
+			name: "updates the maximum number of alert instances",
+			rule: func(r *apimodels.PostableGrafanaRule) { r.MaxInstances = maxInstances(500) },
+			expected: func(t *testing.T, patched *models.AlertRule) {
+				require.Equal(t, maxInstances(500), patched.MaxInstances)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			r := validRule()
+			testCase.rule(r.GrafanaManagedAlert)
+			f := func(condition models.Condition) error {
+				return nil
+			}
+
+			alert, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval, rand.Int63(), randFolder(), f, cfg)
+			require.NoError(t, err)
+
+			existing := *alert
+			existing.QueryOffset = 5 * time.Minute
+			existing.KeepFiringFor = 5 * time.Minute
+			existing.EvictionDelay = 5 * time.Minute
+			existing.MaxInstances = maxInstances(100)
+			existing.UnsetFields = 0
+			models.PatchPartialAlertRule(&existing, alert)
+			require.Zero(t, alert.UnsetFields)
+			testCase.expected(t, alert)
+		})
+	}
+}
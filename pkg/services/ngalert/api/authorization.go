@@ -59,6 +59,16 @@ func (api *API) authorize(method, path string) web.Handler {
 			ac.EvalPermission(ac.ActionAlertingRuleCreate, scope),
 			ac.EvalPermission(ac.ActionAlertingRuleDelete, scope),
 		)
+	// LOGZ.IO GRAFANA CHANGE :: Prometheus rule-file export and import of alert rules
+	case http.MethodGet + "/api/ruler/grafana/api/v1/prometheus/rules/{Namespace}":
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead, dashboards.ScopeFoldersProvider.GetResourceScopeName(ac.Parameter(":Namespace")))
+	case http.MethodPost + "/api/ruler/grafana/api/v1/prometheus/rules/{Namespace}":
+		scope := dashboards.ScopeFoldersProvider.GetResourceScopeName(ac.Parameter(":Namespace"))
+		eval = ac.EvalAll(
+			ac.EvalPermission(ac.ActionAlertingRuleCreate, scope),
+			ac.EvalPermission(ac.ActionAlertingRuleUpdate, scope),
+		)
+	// LOGZ.IO GRAFANA CHANGE :: end
 
 	// Grafana, Prometheus-compatible Paths
 	case http.MethodGet + "/api/prometheus/grafana/api/v1/rules":
@@ -184,7 +194,11 @@ func (api *API) authorize(method, path string) web.Handler {
 	case http.MethodGet + "/api/v1/provisioning/policies",
 		http.MethodGet + "/api/v1/provisioning/contact-points",
 		http.MethodGet + "/api/v1/provisioning/alert-rules/{UID}",
-		http.MethodGet + "/api/v1/provisioning/alert-rules": // LOGZ.IO GRAFANA CHANGE :: DEV-33330 - API to return all alert rules
+		http.MethodGet + "/api/v1/provisioning/alert-rules",         // LOGZ.IO GRAFANA CHANGE :: DEV-33330 - API to return all alert rules
+		http.MethodGet + "/api/v1/provisioning/mute-timings",        // LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+		http.MethodGet + "/api/v1/provisioning/mute-timings/{name}", // LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+		http.MethodGet + "/api/v1/provisioning/templates",           // LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
+		http.MethodGet + "/api/v1/provisioning/templates/{name}":    // LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
 		return middleware.ReqSignedIn
 
 	case http.MethodPost + "/api/v1/provisioning/policies",
@@ -196,7 +210,13 @@ func (api *API) authorize(method, path string) web.Handler {
 		http.MethodDelete + "/api/v1/provisioning/alert-rules/{UID}",
 		http.MethodDelete + "/api/v1/provisioning/policies",
 		http.MethodPut + "/api/v1/provisioning/policies",
-		http.MethodPut + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}":
+		http.MethodPut + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}",
+		http.MethodPost + "/api/v1/provisioning/mute-timings",          // LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+		http.MethodPut + "/api/v1/provisioning/mute-timings/{name}",    // LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+		http.MethodDelete + "/api/v1/provisioning/mute-timings/{name}", // LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+		http.MethodPost + "/api/v1/provisioning/templates",             // LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
+		http.MethodPut + "/api/v1/provisioning/templates/{name}",       // LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
+		http.MethodDelete + "/api/v1/provisioning/templates/{name}":    // LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
 		return middleware.ReqEditorRole
 
 	// LOGZ.IO GRAFANA CHANGE :: DEV-32721 - Guard platform wide contact point modification
@@ -136,3 +136,14 @@ func (f *ForkedRulerApi) forkRoutePostNameGrafanaRulesConfig(ctx *models.ReqCont
 	}
 	return f.GrafanaRuler.RoutePostNameRulesConfig(ctx, conf)
 }
+
+// // LOGZ.IO GRAFANA CHANGE :: Prometheus rule-file export and import of alert rules
+func (f *ForkedRulerApi) forkRouteGetNamespaceGrafanaPrometheusRules(ctx *models.ReqContext) response.Response {
+	return f.GrafanaRuler.RouteGetNamespacePrometheusRules(ctx)
+}
+
+func (f *ForkedRulerApi) forkRoutePostNamespaceGrafanaPrometheusRules(ctx *models.ReqContext) response.Response {
+	return f.GrafanaRuler.RoutePostNamespacePrometheusRules(ctx)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -94,3 +94,49 @@ func (f *ForkedProvisioningApi) forkRouteDeleteAlertRule(ctx *models.ReqContext,
 func (f *ForkedProvisioningApi) forkRoutePutAlertRuleGroup(ctx *models.ReqContext, ag apimodels.AlertRuleGroup, folder, group string) response.Response {
 	return f.svc.RoutePutAlertRuleGroup(ctx, ag, folder, group)
 }
+
+// LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+func (f *ForkedProvisioningApi) forkRouteGetMuteTimings(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetMuteTimings(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetMuteTiming(ctx *models.ReqContext, name string) response.Response {
+	return f.svc.RouteGetMuteTiming(ctx, name)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePostMuteTiming(ctx *models.ReqContext, mt apimodels.MuteTimeInterval) response.Response {
+	return f.svc.RoutePostMuteTiming(ctx, mt)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePutMuteTiming(ctx *models.ReqContext, mt apimodels.MuteTimeInterval, name string) response.Response {
+	return f.svc.RoutePutMuteTiming(ctx, mt, name)
+}
+
+func (f *ForkedProvisioningApi) forkRouteDeleteMuteTiming(ctx *models.ReqContext, name string) response.Response {
+	return f.svc.RouteDeleteMuteTiming(ctx, name)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
+func (f *ForkedProvisioningApi) forkRouteGetTemplates(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetTemplates(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetTemplate(ctx *models.ReqContext, name string) response.Response {
+	return f.svc.RouteGetTemplate(ctx, name)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePostTemplate(ctx *models.ReqContext, tmpl apimodels.MessageTemplate) response.Response {
+	return f.svc.RoutePostTemplate(ctx, tmpl)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePutTemplate(ctx *models.ReqContext, tmpl apimodels.MessageTemplate, name string) response.Response {
+	return f.svc.RoutePutTemplate(ctx, tmpl, name)
+}
+
+func (f *ForkedProvisioningApi) forkRouteDeleteTemplate(ctx *models.ReqContext, name string) response.Response {
+	return f.svc.RouteDeleteTemplate(ctx, name)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
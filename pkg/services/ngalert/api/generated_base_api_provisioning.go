@@ -36,6 +36,20 @@ type ProvisioningApiForkingService interface {
 	RouteInternalPutContactpoint(*models.ReqContext) response.Response // LOGZ.IO GRAFANA CHANGE :: DEV-32721 - Internal API to manage contact points
 	RoutePutPolicyTree(*models.ReqContext) response.Response
 	RouteResetPolicyTree(*models.ReqContext) response.Response
+	// LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+	RouteGetMuteTimings(*models.ReqContext) response.Response
+	RouteGetMuteTiming(*models.ReqContext) response.Response
+	RoutePostMuteTiming(*models.ReqContext) response.Response
+	RoutePutMuteTiming(*models.ReqContext) response.Response
+	RouteDeleteMuteTiming(*models.ReqContext) response.Response
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
+	RouteGetTemplates(*models.ReqContext) response.Response
+	RouteGetTemplate(*models.ReqContext) response.Response
+	RoutePostTemplate(*models.ReqContext) response.Response
+	RoutePutTemplate(*models.ReqContext) response.Response
+	RouteDeleteTemplate(*models.ReqContext) response.Response
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 func (f *ForkedProvisioningApi) RouteDeleteAlertRule(ctx *models.ReqContext) response.Response {
@@ -148,6 +162,74 @@ func (f *ForkedProvisioningApi) RouteResetPolicyTree(ctx *models.ReqContext) res
 	return f.forkRouteResetPolicyTree(ctx)
 }
 
+// LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+func (f *ForkedProvisioningApi) RouteGetMuteTimings(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetMuteTimings(ctx)
+}
+
+func (f *ForkedProvisioningApi) RouteGetMuteTiming(ctx *models.ReqContext) response.Response {
+	nameParam := web.Params(ctx.Req)[":name"]
+	return f.forkRouteGetMuteTiming(ctx, nameParam)
+}
+
+func (f *ForkedProvisioningApi) RoutePostMuteTiming(ctx *models.ReqContext) response.Response {
+	conf := apimodels.MuteTimeInterval{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.forkRoutePostMuteTiming(ctx, conf)
+}
+
+func (f *ForkedProvisioningApi) RoutePutMuteTiming(ctx *models.ReqContext) response.Response {
+	nameParam := web.Params(ctx.Req)[":name"]
+	conf := apimodels.MuteTimeInterval{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.forkRoutePutMuteTiming(ctx, conf, nameParam)
+}
+
+func (f *ForkedProvisioningApi) RouteDeleteMuteTiming(ctx *models.ReqContext) response.Response {
+	nameParam := web.Params(ctx.Req)[":name"]
+	return f.forkRouteDeleteMuteTiming(ctx, nameParam)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
+func (f *ForkedProvisioningApi) RouteGetTemplates(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetTemplates(ctx)
+}
+
+func (f *ForkedProvisioningApi) RouteGetTemplate(ctx *models.ReqContext) response.Response {
+	nameParam := web.Params(ctx.Req)[":name"]
+	return f.forkRouteGetTemplate(ctx, nameParam)
+}
+
+func (f *ForkedProvisioningApi) RoutePostTemplate(ctx *models.ReqContext) response.Response {
+	conf := apimodels.MessageTemplate{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.forkRoutePostTemplate(ctx, conf)
+}
+
+func (f *ForkedProvisioningApi) RoutePutTemplate(ctx *models.ReqContext) response.Response {
+	nameParam := web.Params(ctx.Req)[":name"]
+	conf := apimodels.MessageTemplate{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.forkRoutePutTemplate(ctx, conf, nameParam)
+}
+
+func (f *ForkedProvisioningApi) RouteDeleteTemplate(ctx *models.ReqContext) response.Response {
+	nameParam := web.Params(ctx.Req)[":name"]
+	return f.forkRouteDeleteTemplate(ctx, nameParam)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApiForkingService, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
 		group.Delete(
@@ -318,5 +400,109 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApiForkingServi
 				m,
 			),
 		)
+		// LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/mute-timings"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/mute-timings"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/mute-timings",
+				srv.RouteGetMuteTimings,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/mute-timings/{name}"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/mute-timings/{name}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/mute-timings/{name}",
+				srv.RouteGetMuteTiming,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/mute-timings"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/mute-timings"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/mute-timings",
+				srv.RoutePostMuteTiming,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/mute-timings/{name}"),
+			api.authorize(http.MethodPut, "/api/v1/provisioning/mute-timings/{name}"),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/mute-timings/{name}",
+				srv.RoutePutMuteTiming,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/v1/provisioning/mute-timings/{name}"),
+			api.authorize(http.MethodDelete, "/api/v1/provisioning/mute-timings/{name}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/provisioning/mute-timings/{name}",
+				srv.RouteDeleteMuteTiming,
+				m,
+			),
+		)
+		// LOGZ.IO GRAFANA CHANGE :: end
+		// LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/templates"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/templates"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/templates",
+				srv.RouteGetTemplates,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/templates/{name}"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/templates/{name}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/templates/{name}",
+				srv.RouteGetTemplate,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/templates"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/templates"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/templates",
+				srv.RoutePostTemplate,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/templates/{name}"),
+			api.authorize(http.MethodPut, "/api/v1/provisioning/templates/{name}"),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/templates/{name}",
+				srv.RoutePutTemplate,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/v1/provisioning/templates/{name}"),
+			api.authorize(http.MethodDelete, "/api/v1/provisioning/templates/{name}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/provisioning/templates/{name}",
+				srv.RouteDeleteTemplate,
+				m,
+			),
+		)
+		// LOGZ.IO GRAFANA CHANGE :: end
 	}, middleware.ReqSignedIn)
 }
@@ -32,6 +32,10 @@ type RulerApiForkingService interface {
 	RouteGetRulesConfig(*models.ReqContext) response.Response
 	RoutePostNameGrafanaRulesConfig(*models.ReqContext) response.Response
 	RoutePostNameRulesConfig(*models.ReqContext) response.Response
+	// // LOGZ.IO GRAFANA CHANGE :: Prometheus rule-file export and import of alert rules
+	RouteGetNamespaceGrafanaPrometheusRules(*models.ReqContext) response.Response
+	RoutePostNamespaceGrafanaPrometheusRules(*models.ReqContext) response.Response
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 func (f *ForkedRulerApi) RouteDeleteGrafanaRuleGroupConfig(ctx *models.ReqContext) response.Response {
@@ -90,6 +94,17 @@ func (f *ForkedRulerApi) RoutePostNameRulesConfig(ctx *models.ReqContext) respon
 	return f.forkRoutePostNameRulesConfig(ctx, conf)
 }
 
+// // LOGZ.IO GRAFANA CHANGE :: Prometheus rule-file export and import of alert rules
+func (f *ForkedRulerApi) RouteGetNamespaceGrafanaPrometheusRules(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetNamespaceGrafanaPrometheusRules(ctx)
+}
+
+func (f *ForkedRulerApi) RoutePostNamespaceGrafanaPrometheusRules(ctx *models.ReqContext) response.Response {
+	return f.forkRoutePostNamespaceGrafanaPrometheusRules(ctx)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 func (api *API) RegisterRulerApiEndpoints(srv RulerApiForkingService, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
 		group.Delete(
@@ -212,5 +227,27 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApiForkingService, m *metrics
 				m,
 			),
 		)
+		// // LOGZ.IO GRAFANA CHANGE :: Prometheus rule-file export and import of alert rules
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/prometheus/rules/{Namespace}"),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/api/v1/prometheus/rules/{Namespace}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/prometheus/rules/{Namespace}",
+				srv.RouteGetNamespaceGrafanaPrometheusRules,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/api/v1/prometheus/rules/{Namespace}"),
+			api.authorize(http.MethodPost, "/api/ruler/grafana/api/v1/prometheus/rules/{Namespace}"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/api/v1/prometheus/rules/{Namespace}",
+				srv.RoutePostNamespaceGrafanaPrometheusRules,
+				m,
+			),
+		)
+		// LOGZ.IO GRAFANA CHANGE :: end
 	}, middleware.ReqSignedIn)
 }
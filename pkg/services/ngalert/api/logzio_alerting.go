@@ -3,12 +3,15 @@ package api
 // LOGZ.IO GRAFANA CHANGE :: DEV-30169,DEV-30170: add endpoints to evaluate and process alerts
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/benbjohnson/clock"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -20,15 +23,31 @@ import (
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrations/ualert"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/prometheus/common/model"
+	"golang.org/x/sync/singleflight"
 	"math"
 	"net/http"
 	"net/url"
+	"sort"
+	"sync"
+	"time"
 )
 
 const (
 	EvaluationErrorRefIdKey = "REF_ID"
 	QueryErrorType          = "QUERY_ERROR"
 	OtherErrorType          = "OTHER"
+
+	// maxBacktestEvaluations limits the number of evaluations of a single backtest request.
+	maxBacktestEvaluations = 1440 // LOGZ.IO GRAFANA CHANGE :: Alert rule backtesting endpoint
+
+	// LOGZ.IO GRAFANA CHANGE :: Idempotent alert processing
+	// IdempotencyKeyHeader allows callers to choose the key that identifies retries of the same process request.
+	IdempotencyKeyHeader = "X-Idempotency-Key"
+	// IdempotentReplayHeader is set on responses that were returned from a previously processed request.
+	IdempotentReplayHeader      = "X-Idempotent-Replay"
+	processedRequestCachePrefix = "ngalert-processed-request:"
+	// LOGZ.IO GRAFANA CHANGE :: end
 )
 
 type LogzioAlertingService struct {
@@ -43,6 +62,14 @@ type LogzioAlertingService struct {
 	InstanceStore        store.InstanceStore
 	Log                  log.Logger
 	Migrator             *migrator.Migrator
+	// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+	OrgMigrationStatusStore store.LogzioOrgMigrationStatusStore
+	// LOGZ.IO GRAFANA CHANGE :: end
+	StateHistoryStore store.LogzioStateHistoryStore // LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+	// LOGZ.IO GRAFANA CHANGE :: Idempotent alert processing
+	ProcessedRequestsCache remotecache.CacheStorage
+	processRequestsGroup   singleflight.Group
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 func NewLogzioAlertingService(
@@ -56,6 +83,7 @@ func NewLogzioAlertingService(
 	InstanceStore store.InstanceStore,
 	log log.Logger,
 	SQLStore *sqlstore.SQLStore,
+	ProcessedRequestsCache remotecache.CacheStorage,
 ) *LogzioAlertingService {
 	return &LogzioAlertingService{
 		AlertingProxy:        Proxy,
@@ -69,10 +97,148 @@ func NewLogzioAlertingService(
 		InstanceStore:        InstanceStore,
 		Log:                  log,
 		Migrator:             SQLStore.BuildMigrator(),
+		// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+		OrgMigrationStatusStore: store.LogzioOrgMigrationStatusStore{SQLStore: SQLStore},
+		StateHistoryStore:       store.LogzioStateHistoryStore{SQLStore: SQLStore}, // LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+		// LOGZ.IO GRAFANA CHANGE :: Idempotent alert processing
+		ProcessedRequestsCache: ProcessedRequestsCache,
 	}
 }
 
 func (srv *LogzioAlertingService) RouteEvaluateAlert(httpReq http.Request, evalRequest apimodels.AlertEvaluationRequest) response.Response {
+	// LOGZ.IO GRAFANA CHANGE :: Expose intermediate expression node results
+	if evalRequest.Explain {
+		return srv.explainEvaluateAlert(httpReq, evalRequest)
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	evalResults, err := srv.evaluateAlert(httpReq, evalRequest, 0)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate conditions", err)
+	}
+
+	return response.JSON(http.StatusOK, evaluationResultsToApiResults(evalResults))
+}
+
+// LOGZ.IO GRAFANA CHANGE :: Expose intermediate expression node results
+func (srv *LogzioAlertingService) explainEvaluateAlert(httpReq http.Request, evalRequest apimodels.AlertEvaluationRequest) response.Response {
+	trace := &expr.NodeTrace{}
+	evalResults, err := srv.evaluate(httpReq, evalRequest, 0, trace)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate conditions", err)
+	}
+
+	nodes := trace.Nodes()
+	explainResponse := apimodels.AlertExplainEvaluationResponse{
+		Results: evaluationResultsToApiResults(evalResults),
+		Nodes:   make([]apimodels.ApiNodeResult, 0, len(nodes)),
+	}
+	for _, node := range nodes {
+		apiNode := apimodels.ApiNodeResult{
+			RefID:    node.RefID,
+			NodeType: node.NodeType.String(),
+			Duration: model.Duration(node.Duration),
+			Frames:   node.Frames,
+		}
+		if node.Error != nil {
+			apiNode.Error = node.Error.Error()
+		}
+		explainResponse.Nodes = append(explainResponse.Nodes, apiNode)
+	}
+
+	return response.JSON(http.StatusOK, explainResponse)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Batch evaluation endpoint
+type batchEvaluationResult struct {
+	ruleUID string
+	result  apimodels.AlertBatchEvaluationResult
+}
+
+func (srv *LogzioAlertingService) RouteBatchEvaluateAlert(httpReq http.Request, batchRequest apimodels.AlertBatchEvaluationRequest) response.Response {
+	if len(batchRequest.Requests) == 0 {
+		return response.Error(http.StatusBadRequest, "no alert rules to evaluate", nil)
+	}
+
+	seenRuleUIDs := make(map[string]struct{}, len(batchRequest.Requests))
+	for _, evalRequest := range batchRequest.Requests {
+		ruleUID := evalRequest.AlertRule.UID
+		if ruleUID == "" {
+			return response.Error(http.StatusBadRequest, "alert rule UID is required for batch evaluation", nil)
+		}
+		if _, ok := seenRuleUIDs[ruleUID]; ok {
+			return response.Error(http.StatusBadRequest, fmt.Sprintf("alert rule %s appears more than once in the batch", ruleUID), nil)
+		}
+		seenRuleUIDs[ruleUID] = struct{}{}
+	}
+
+	numWorkers := srv.Cfg.UnifiedAlerting.BatchEvaluationMaxWorkers
+	if batchRequest.MaxWorkers > 0 && batchRequest.MaxWorkers < numWorkers {
+		numWorkers = batchRequest.MaxWorkers
+	}
+	if numWorkers > len(batchRequest.Requests) {
+		numWorkers = len(batchRequest.Requests)
+	}
+
+	ruleTimeout := srv.Cfg.UnifiedAlerting.BatchEvaluationRuleTimeout
+	if requested := time.Duration(batchRequest.RuleTimeout); requested > 0 && requested < ruleTimeout {
+		ruleTimeout = requested
+	}
+
+	workCh := make(chan apimodels.AlertEvaluationRequest, len(batchRequest.Requests))
+	for _, evalRequest := range batchRequest.Requests {
+		workCh <- evalRequest
+	}
+	close(workCh)
+
+	resultCh := make(chan batchEvaluationResult, len(batchRequest.Requests))
+	var wg sync.WaitGroup
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for evalRequest := range workCh {
+				start := srv.Clock.Now()
+				evalResults, err := srv.evaluateAlert(httpReq, evalRequest, ruleTimeout)
+				result := apimodels.AlertBatchEvaluationResult{
+					Results:  evaluationResultsToApiResults(evalResults),
+					Duration: model.Duration(srv.Clock.Now().Sub(start)),
+				}
+				if err != nil {
+					result.Error = &apimodels.ApiEvalError{
+						Type:     OtherErrorType,
+						Message:  err.Error(),
+						Metadata: map[string]string{},
+					}
+				}
+				resultCh <- batchEvaluationResult{ruleUID: evalRequest.AlertRule.UID, result: result}
+			}
+		}()
+	}
+	wg.Wait()
+	close(resultCh)
+
+	batchResponse := apimodels.AlertBatchEvaluationResponse{
+		Results: make(map[string]apimodels.AlertBatchEvaluationResult, len(batchRequest.Requests)),
+	}
+	for next := range resultCh {
+		batchResponse.Results[next.ruleUID] = next.result
+	}
+
+	return response.JSON(http.StatusOK, batchResponse)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// evaluateAlert evaluates the alert rule of the request. The configured evaluation timeout is used if timeout is not greater than zero.
+func (srv *LogzioAlertingService) evaluateAlert(httpReq http.Request, evalRequest apimodels.AlertEvaluationRequest, timeout time.Duration) (eval.Results, error) {
+	return srv.evaluate(httpReq, evalRequest, timeout, nil)
+}
+
+// evaluate evaluates the alert rule of the request and records the outcome of every node in trace if it is not nil.
+func (srv *LogzioAlertingService) evaluate(httpReq http.Request, evalRequest apimodels.AlertEvaluationRequest, timeout time.Duration, trace *expr.NodeTrace) (eval.Results, error) {
 	alertRuleToEvaluate := apiRuleToDbAlertRule(evalRequest.AlertRule)
 	condition := ngmodels.Condition{
 		Condition: alertRuleToEvaluate.Condition,
@@ -87,36 +253,117 @@ func (srv *LogzioAlertingService) RouteEvaluateAlert(httpReq http.Request, evalR
 		}
 	}
 
-	start := srv.Clock.Now()
-	evalResults, err := srv.Evaluator.ConditionEval(&condition, evalRequest.EvalTime, srv.ExpressionService, &ngmodels.LogzioAlertRuleEvalContext{
+	evalCtx := &ngmodels.LogzioAlertRuleEvalContext{
 		LogzioHeaders:     httpReq.Header,
 		DsOverrideByDsUid: dsOverrideByDsUid,
-	})
+		EvaluationTimeout: timeout,
+	}
+	if trace != nil {
+		evalCtx.DecorateContext = func(ctx context.Context) context.Context {
+			return expr.WithNodeTrace(ctx, trace)
+		}
+	}
+
+	start := srv.Clock.Now()
+	evalResults, err := eval.ConditionEvalWithQueryOffset(srv.Evaluator, &condition, evalRequest.EvalTime, alertRuleToEvaluate.QueryOffset, srv.ExpressionService, evalCtx) // LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
 	dur := srv.Clock.Now().Sub(start)
 
 	if err != nil {
 		srv.Log.Error("failed to evaluate alert rule", "duration", dur, "err", err, "ruleId", alertRuleToEvaluate.ID)
-		return response.Error(http.StatusInternalServerError, "Failed to evaluate conditions", err)
+		return nil, err
 	}
 
+	return evalResults, nil
+}
+
+func evaluationResultsToApiResults(evalResults eval.Results) []apimodels.ApiEvalResult {
 	var apiEvalResults []apimodels.ApiEvalResult
 	for _, result := range evalResults {
 		apiEvalResults = append(apiEvalResults, evaluationResultsToApi(result))
 	}
-
-	return response.JSON(http.StatusOK, apiEvalResults)
+	return apiEvalResults
 }
 
 func (srv *LogzioAlertingService) RouteProcessAlert(httpReq http.Request, request apimodels.AlertProcessRequest) response.Response {
-	alertRule := apiRuleToDbAlertRule(request.AlertRule)
+	// LOGZ.IO GRAFANA CHANGE :: Idempotent alert processing
+	key := processIdempotencyKey(httpReq, request)
+	if key == "" || srv.ProcessedRequestsCache == nil || srv.Cfg.UnifiedAlerting.ProcessIdempotencyTTL <= 0 {
+		return srv.processAlert(httpReq, request)
+	}
 
-	var shouldCreateAnnotationsAndAlertInstances bool
-	if request.ShouldManageAnnotationsAndInstances == nil {
-		shouldCreateAnnotationsAndAlertInstances = true
-	} else {
-		shouldCreateAnnotationsAndAlertInstances = *request.ShouldManageAnnotationsAndInstances
+	// concurrent retries of the same request on this instance wait for the first one instead of processing it again
+	resp, _, _ := srv.processRequestsGroup.Do(key, func() (interface{}, error) {
+		ctx := httpReq.Context()
+		cacheKey := processedRequestCachePrefix + key
+		if cached, err := srv.ProcessedRequestsCache.Get(ctx, cacheKey); err == nil {
+			if body, ok := cached.([]byte); ok {
+				srv.Log.Info("alert process request was already processed, replaying the response", "key", key, "ruleUID", request.AlertRule.UID)
+				return response.JSON(http.StatusOK, json.RawMessage(body)).SetHeader(IdempotentReplayHeader, "true"), nil
+			}
+		} else if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			srv.Log.Warn("failed to look up processed alert request, processing it", "key", key, "err", err)
+		}
+
+		alerts, errResp := srv.processAlertResults(httpReq, request)
+		if errResp != nil {
+			return errResp, nil
+		}
+
+		body, err := json.Marshal(alerts)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to marshal processed alerts", err), nil
+		}
+		if err := srv.ProcessedRequestsCache.Set(ctx, cacheKey, body, srv.Cfg.UnifiedAlerting.ProcessIdempotencyTTL); err != nil {
+			srv.Log.Warn("failed to store processed alert request", "key", key, "err", err)
+		}
+		return response.JSON(http.StatusOK, json.RawMessage(body)), nil
+	})
+	return resp.(response.Response)
+	// LOGZ.IO GRAFANA CHANGE :: end
+}
+
+// LOGZ.IO GRAFANA CHANGE :: Idempotent alert processing
+// processIdempotencyKey returns the key identifying retries of the request. It is the value of the IdempotencyKeyHeader
+// header scoped to the org and the rule, so that callers of different rules cannot replay each other's responses, or
+// else the rule and the evaluation time of the results. It is empty when none of them is known.
+func processIdempotencyKey(httpReq http.Request, request apimodels.AlertProcessRequest) string {
+	if key := httpReq.Header.Get(IdempotencyKeyHeader); key != "" {
+		return fmt.Sprintf("%d:%s:%s", request.AlertRule.OrgID, request.AlertRule.UID, key)
 	}
 
+	if request.AlertRule.UID == "" || len(request.EvaluationResults) == 0 {
+		return ""
+	}
+	var evaluatedAt time.Time
+	for _, result := range request.EvaluationResults {
+		if result.EvaluatedAt.After(evaluatedAt) {
+			evaluatedAt = result.EvaluatedAt
+		}
+	}
+	if evaluatedAt.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%d:%s:%d", request.AlertRule.OrgID, request.AlertRule.UID, evaluatedAt.UnixNano())
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+func (srv *LogzioAlertingService) processAlert(httpReq http.Request, request apimodels.AlertProcessRequest) response.Response {
+	alerts, errResp := srv.processAlertResults(httpReq, request)
+	if errResp != nil {
+		return errResp
+	}
+
+	return response.JSONStreaming(http.StatusOK, alerts)
+}
+
+// processAlertResults applies the evaluation results of the request and pushes the resulting alerts to the Alertmanager.
+// It returns an error response if the alerts could not be pushed.
+func (srv *LogzioAlertingService) processAlertResults(httpReq http.Request, request apimodels.AlertProcessRequest) (apimodels.PostableAlerts, response.Response) {
+	alertRule := apiRuleToDbAlertRule(request.AlertRule)
+
+	shouldCreateAnnotationsAndAlertInstances := shouldManageAnnotationsAndInstances(request.ShouldManageAnnotationsAndInstances)
+
 	var evalResults eval.Results
 	for _, apiEvalResult := range request.EvaluationResults {
 		evalResults = append(evalResults, apiToEvaluationResult(apiEvalResult))
@@ -129,56 +376,274 @@ func (srv *LogzioAlertingService) RouteProcessAlert(httpReq http.Request, reques
 		srv.saveAlertStates(processedStates)
 	}
 
-	alerts := schedule.FromAlertStateToPostableAlerts(processedStates, srv.StateManager, srv.AppUrl)
-	for _, alert := range alerts.PostableAlerts {
-		alert.Annotations[ngmodels.LogzioAccountIdAnnotation] = request.AccountId
+	alerts := srv.toPostableAlerts(processedStates, srv.StateManager, request.AccountId)
+	if errResp := srv.pushAlerts(alertRule, alerts); errResp != nil {
+		return apimodels.PostableAlerts{}, errResp
 	}
 
-	if len(alerts.PostableAlerts) > 0 {
-		n, err := srv.MultiOrgAlertmanager.AlertmanagerFor(alertRule.OrgID)
-		if err == nil {
-			srv.Log.Info("Pushing alerts to alert manager")
-			if err := n.PutAlerts(alerts); err != nil {
-				srv.Log.Error("failed to put alerts in the local notifier", "count", len(alerts.PostableAlerts), "err", err, "ruleId", alertRule.ID)
-				return response.Error(http.StatusInternalServerError, "Failed to process alert", err)
+	return alerts, nil
+}
+
+// LOGZ.IO GRAFANA CHANGE :: Combined evaluate-and-process endpoint
+func (srv *LogzioAlertingService) RouteEvaluateAndProcessAlert(httpReq http.Request, request apimodels.AlertEvaluateAndProcessRequest) response.Response {
+	alertRule := apiRuleToDbAlertRule(request.AlertRule)
+
+	evalResults, err := srv.evaluateAlert(httpReq, apimodels.AlertEvaluationRequest{
+		AlertRule:   request.AlertRule,
+		EvalTime:    request.EvalTime,
+		DsOverrides: request.DsOverrides,
+	}, 0)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate conditions", err)
+	}
+
+	stateManager := srv.StateManager
+	shouldCreateAnnotationsAndAlertInstances := shouldManageAnnotationsAndInstances(request.ShouldManageAnnotationsAndInstances)
+	ctx := context.WithValue(httpReq.Context(), state.ShouldManageAnnotationsAndInstancesContextKey, shouldCreateAnnotationsAndAlertInstances)
+	if request.DryRun {
+		stateManager = srv.StateManager.IsolatedCopyForRule(httpReq.Context(), alertRule.OrgID, alertRule.UID)
+		ctx = state.WithoutAnnotationsAndInstances(httpReq.Context())
+	}
+
+	transitions := stateManager.ProcessEvalResultsWithTransitions(ctx, &alertRule, evalResults)
+	processedStates := make([]*state.State, 0, len(transitions))
+	for _, transition := range transitions {
+		processedStates = append(processedStates, transition.State)
+	}
+
+	if shouldCreateAnnotationsAndAlertInstances && !request.DryRun {
+		srv.saveAlertStates(processedStates)
+	}
+
+	alerts := srv.toPostableAlerts(processedStates, stateManager, request.AccountId)
+	if !request.DryRun {
+		if errResp := srv.pushAlerts(alertRule, alerts); errResp != nil {
+			return errResp
+		}
+	}
+
+	return response.JSON(http.StatusOK, apimodels.AlertEvaluateAndProcessResponse{
+		DryRun:            request.DryRun,
+		EvaluationResults: evaluationResultsToApiResults(evalResults),
+		StateTransitions:  transitionsToApi(transitions),
+		Alerts:            alerts,
+	})
+}
+
+func transitionsToApi(transitions []state.Transition) []apimodels.ApiStateTransition {
+	apiTransitions := make([]apimodels.ApiStateTransition, 0, len(transitions))
+	for _, transition := range transitions {
+		apiTransitions = append(apiTransitions, apimodels.ApiStateTransition{
+			Labels:             transition.State.Labels,
+			Annotations:        transition.State.Annotations,
+			PreviousState:      transition.PreviousState.String(),
+			State:              transition.State.State.String(),
+			Changed:            transition.Changed(),
+			Resolved:           transition.State.Resolved,
+			StartsAt:           transition.State.StartsAt,
+			EndsAt:             transition.State.EndsAt,
+			LastEvaluationTime: transition.State.LastEvaluationTime,
+		})
+	}
+	return apiTransitions
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Alert rule backtesting endpoint
+func (srv *LogzioAlertingService) RouteBacktestAlert(httpReq http.Request, request apimodels.AlertBacktestRequest) response.Response {
+	alertRule := apiRuleToDbAlertRule(request.AlertRule)
+
+	step := time.Duration(request.Step)
+	if step <= 0 {
+		step = time.Duration(alertRule.IntervalSeconds) * time.Second
+	}
+	if step <= 0 {
+		return response.Error(http.StatusBadRequest, "step or alert rule interval must be greater than 0", nil)
+	}
+	if request.From.IsZero() || request.To.IsZero() || !request.From.Before(request.To) {
+		return response.Error(http.StatusBadRequest, "from must be before to", nil)
+	}
+	evaluations := int(request.To.Sub(request.From)/step) + 1
+	if evaluations > maxBacktestEvaluations {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("backtest requires %d evaluations, the maximum is %d; increase the step or shorten the time range", evaluations, maxBacktestEvaluations), nil)
+	}
+
+	stateManager := state.NewIsolatedManager(srv.Log, srv.AppUrl, nil)
+	ctx := state.WithoutAnnotationsAndInstances(httpReq.Context())
+
+	timelines := make(map[string]*apimodels.ApiStateTimeline)
+	for evalTime := request.From; !evalTime.After(request.To); evalTime = evalTime.Add(step) {
+		if err := httpReq.Context().Err(); err != nil {
+			return response.Error(http.StatusRequestTimeout, "backtest was cancelled", err)
+		}
+
+		evalResults, err := srv.evaluateAlert(httpReq, apimodels.AlertEvaluationRequest{
+			AlertRule:   request.AlertRule,
+			EvalTime:    evalTime,
+			DsOverrides: request.DsOverrides,
+		}, 0)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, fmt.Sprintf("Failed to evaluate conditions at %s", evalTime.Format(time.RFC3339)), err)
+		}
+
+		for _, s := range stateManager.ProcessEvalResultsAt(ctx, &alertRule, evalResults, evalTime) {
+			timeline, ok := timelines[s.CacheId]
+			if !ok {
+				timeline = &apimodels.ApiStateTimeline{Labels: s.Labels.Copy()}
+				timelines[s.CacheId] = timeline
 			}
-		} else {
-			if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
-				srv.Log.Info("local notifier was not found", "orgId", alertRule.OrgID)
-				return response.Error(http.StatusBadRequest, "Alert manager for organization not found", err)
-			} else {
-				srv.Log.Error("local notifier is not available", "err", err, "orgId", alertRule.OrgID)
-				return response.Error(http.StatusInternalServerError, "Failed to process alert", err)
+			point := apimodels.ApiStatePoint{
+				Time:  evalTime,
+				State: s.State.String(),
+			}
+			if s.Error != nil {
+				point.Error = s.Error.Error()
 			}
+			timeline.Points = append(timeline.Points, point)
 		}
-	} else {
+	}
+
+	cacheIds := make([]string, 0, len(timelines))
+	for cacheId := range timelines {
+		cacheIds = append(cacheIds, cacheId)
+	}
+	sort.Strings(cacheIds)
+
+	backtestResponse := apimodels.AlertBacktestResponse{
+		Evaluations: evaluations,
+		Timelines:   make([]apimodels.ApiStateTimeline, 0, len(timelines)),
+	}
+	for _, cacheId := range cacheIds {
+		backtestResponse.Timelines = append(backtestResponse.Timelines, *timelines[cacheId])
+	}
+
+	return response.JSON(http.StatusOK, backtestResponse)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+func shouldManageAnnotationsAndInstances(requested *bool) bool {
+	if requested == nil {
+		return true
+	}
+	return *requested
+}
+
+func (srv *LogzioAlertingService) toPostableAlerts(processedStates []*state.State, stateManager *state.Manager, accountId string) apimodels.PostableAlerts {
+	alerts := schedule.FromAlertStateToPostableAlerts(processedStates, stateManager, srv.AppUrl)
+	for _, alert := range alerts.PostableAlerts {
+		alert.Annotations[ngmodels.LogzioAccountIdAnnotation] = accountId
+	}
+	return alerts
+}
+
+// pushAlerts sends the alerts to the Alertmanager of the organization of the rule. It returns an error response if the alerts could not be sent.
+func (srv *LogzioAlertingService) pushAlerts(alertRule ngmodels.AlertRule, alerts apimodels.PostableAlerts) response.Response {
+	if len(alerts.PostableAlerts) == 0 {
 		srv.Log.Debug("no alerts to put in the notifier or to send to external Alertmanager(s)")
+		return nil
 	}
 
-	return response.JSONStreaming(http.StatusOK, alerts)
+	n, err := srv.MultiOrgAlertmanager.AlertmanagerFor(alertRule.OrgID)
+	if err != nil {
+		if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
+			srv.Log.Info("local notifier was not found", "orgId", alertRule.OrgID)
+			return response.Error(http.StatusBadRequest, "Alert manager for organization not found", err)
+		}
+		srv.Log.Error("local notifier is not available", "err", err, "orgId", alertRule.OrgID)
+		return response.Error(http.StatusInternalServerError, "Failed to process alert", err)
+	}
+
+	srv.Log.Info("Pushing alerts to alert manager")
+	if err := n.PutAlerts(alerts); err != nil {
+		srv.Log.Error("failed to put alerts in the local notifier", "count", len(alerts.PostableAlerts), "err", err, "ruleId", alertRule.ID)
+		return response.Error(http.StatusInternalServerError, "Failed to process alert", err)
+	}
+	return nil
 }
 
-func (srv *LogzioAlertingService) RouteMigrateOrg(request RunAlertMigrationForOrg) response.Response {
+func (srv *LogzioAlertingService) RouteMigrateOrg(ctx context.Context, request RunAlertMigrationForOrg) response.Response {
 	channelUidByEmailAddress := make(map[string]string)
 	for _, emailNot := range request.EmailNotifications {
 		channelUidByEmailAddress[emailNot.EmailAddress] = emailNot.ChannelUid
 	}
+
+	// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+	if request.DryRun {
+		dryRun := ualert.NewOrgAlertMigrationDryRun(request.OrgId, channelUidByEmailAddress)
+		if err := srv.Migrator.RunMigration(dryRun); err != nil {
+			srv.Log.Error("Failed to run alert migration dry-run", "orgId", request.OrgId, "err", err)
+			return response.Error(http.StatusInternalServerError, "Failed to run alert migration dry-run", err)
+		}
+		return response.JSON(http.StatusOK, dryRun.Report())
+	}
+
+	status := &ngmodels.OrgMigrationStatus{
+		OrgID:     request.OrgId,
+		Status:    ngmodels.OrgMigrationStarted,
+		StartedAt: srv.Clock.Now(),
+	}
+	if err := srv.OrgMigrationStatusStore.SaveOrgMigrationStatus(ctx, status); err != nil {
+		srv.Log.Error("Failed to save alert migration status", "orgId", request.OrgId, "err", err)
+		return response.Error(http.StatusInternalServerError, "Failed to save alert migration status", err)
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
 	alertMigration := ualert.NewOrgAlertMigration(request.OrgId, channelUidByEmailAddress)
 
 	if err := srv.Migrator.RunMigration(alertMigration); err != nil {
 		srv.Log.Error("Failed to run alert migration", "orgId", request.OrgId, "err", err)
+		srv.finishOrgMigration(ctx, status, alertMigration.Report(), err) // LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
 		return response.Error(http.StatusInternalServerError, "Failed to run alert migration", err)
 	}
 
 	if err := srv.Migrator.RunMigration(&ualert.UpdateOrgDashboardUIDPanelIDMigration{OrgId: request.OrgId}); err != nil {
 		srv.Log.Error("Failed to run update dashboard uuid and panel ID migration", "orgId", request.OrgId, "err", err)
+		srv.finishOrgMigration(ctx, status, alertMigration.Report(), err) // LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
 		return response.Error(http.StatusInternalServerError, "Failed to run update dashboard uuid and panel ID migration", err)
 	}
 
+	srv.finishOrgMigration(ctx, status, alertMigration.Report(), nil) // LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+
 	return response.JSONStreaming(http.StatusOK, "Success")
 }
 
-func (srv *LogzioAlertingService) RouteClearOrgMigration(requestBody ClearOrgAlertMigration) response.Response {
+// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+func (srv *LogzioAlertingService) RouteGetOrgMigrationStatus(ctx context.Context, orgId int64) response.Response {
+	status, err := srv.OrgMigrationStatusStore.GetOrgMigrationStatus(ctx, orgId)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrOrgMigrationStatusNotFound) {
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get alert migration status", err)
+	}
+
+	return response.JSON(http.StatusOK, status)
+}
+
+// finishOrgMigration records the outcome of the migration. Failing to record it does not fail the migration.
+func (srv *LogzioAlertingService) finishOrgMigration(ctx context.Context, status *ngmodels.OrgMigrationStatus, report *ualert.OrgMigrationReport, migrationErr error) {
+	finishedAt := srv.Clock.Now()
+	status.FinishedAt = &finishedAt
+	status.Status = ngmodels.OrgMigrationFinished
+	status.Alerts = len(report.Alerts)
+	status.ContactPoints = len(report.ContactPoints)
+	status.EmailContactPoints = len(report.EmailContactPoints)
+	status.Unsupported = len(report.Unsupported)
+	if migrationErr != nil {
+		status.Status = ngmodels.OrgMigrationFailed
+		status.Error = migrationErr.Error()
+	}
+
+	if err := srv.OrgMigrationStatusStore.SaveOrgMigrationStatus(ctx, status); err != nil {
+		srv.Log.Error("Failed to save alert migration status", "orgId", status.OrgID, "status", status.Status, "err", err)
+	}
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+func (srv *LogzioAlertingService) RouteClearOrgMigration(ctx context.Context, requestBody ClearOrgAlertMigration) response.Response {
 	migration := &ualert.RmOrgAlertMigration{OrgId: requestBody.OrgId}
 
 	if err := srv.Migrator.RunMigration(migration); err != nil {
@@ -186,9 +651,83 @@ func (srv *LogzioAlertingService) RouteClearOrgMigration(requestBody ClearOrgAle
 		return response.Error(http.StatusInternalServerError, "Failed to run clear alert migration", err)
 	}
 
+	// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+	if err := srv.OrgMigrationStatusStore.DeleteOrgMigrationStatus(ctx, requestBody.OrgId); err != nil {
+		srv.Log.Error("Failed to delete alert migration status", "orgId", requestBody.OrgId, "err", err)
+		return response.Error(http.StatusInternalServerError, "Failed to delete alert migration status", err)
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
 	return response.JSONStreaming(http.StatusOK, "Success")
 }
 
+// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+func (srv *LogzioAlertingService) RouteGetStatesScheduledForEviction(orgId int64) response.Response {
+	nextEvictionAt, scheduled := srv.StateManager.StatesScheduledForEviction(orgId)
+
+	evictions := make([]apimodels.ApiScheduledStateEviction, 0, len(scheduled))
+	for _, eviction := range scheduled {
+		evictions = append(evictions, apimodels.ApiScheduledStateEviction{
+			RuleUID:            eviction.State.AlertRuleUID,
+			Labels:             eviction.State.Labels,
+			State:              eviction.State.State.String(),
+			LastEvaluationTime: eviction.State.LastEvaluationTime,
+			EndsAt:             eviction.State.EndsAt,
+			EvictionDelay:      model.Duration(eviction.EvictionDelay),
+			EvictableAt:        eviction.EvictableAt,
+		})
+	}
+
+	return response.JSON(http.StatusOK, apimodels.StateEvictionResponse{
+		NextEvictionAt: nextEvictionAt,
+		States:         evictions,
+	})
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+func (srv *LogzioAlertingService) RouteGetStateHistory(ctx context.Context, query ngmodels.GetStateHistoryQuery) response.Response {
+	if err := srv.StateHistoryStore.GetStateHistory(ctx, &query); err != nil {
+		srv.Log.Error("Failed to get alert state history", "orgId", query.OrgID, "err", err)
+		return response.Error(http.StatusInternalServerError, "Failed to get alert state history", err)
+	}
+
+	transitions := make([]apimodels.ApiStateHistoryEntry, 0, len(query.Result))
+	for _, entry := range query.Result {
+		transitions = append(transitions, apimodels.ApiStateHistoryEntry{
+			RuleUID:       entry.RuleUID,
+			LabelsHash:    entry.LabelsHash,
+			Labels:        entry.Labels,
+			PreviousState: entry.PreviousState,
+			State:         entry.State,
+			Values:        entry.Values,
+			EvaluatedAt:   time.UnixMilli(entry.EvaluatedAt).UTC(),
+		})
+	}
+
+	return response.JSON(http.StatusOK, apimodels.StateHistoryResponse{Transitions: transitions})
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Notification delivery log
+func (srv *LogzioAlertingService) RouteGetNotificationDeliveryLog(orgId int64, query notifier.DeliveryLogQuery) response.Response {
+	am, err := srv.MultiOrgAlertmanager.AlertmanagerFor(orgId)
+	if err != nil {
+		if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
+			return response.Error(http.StatusNotFound, err.Error(), nil)
+		}
+		if !errors.Is(err, notifier.ErrAlertmanagerNotReady) {
+			return response.Error(http.StatusInternalServerError, "Failed to get the Alertmanager of the org", err)
+		}
+	}
+
+	return response.JSON(http.StatusOK, apimodels.NotificationDeliveryLogResponse{Deliveries: am.DeliveryLog(query)})
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 func evaluationResultsToApi(evalResult eval.Result) apimodels.ApiEvalResult {
 	apiEvalResult := apimodels.ApiEvalResult{
 		Instance:           evalResult.Instance,
@@ -284,6 +823,10 @@ func apiRuleToDbAlertRule(api apimodels.ApiAlertRule) ngmodels.AlertRule {
 		For:             api.For,
 		Annotations:     api.Annotations,
 		Labels:          api.Labels,
+		QueryOffset:     time.Duration(api.QueryOffset),   // LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+		KeepFiringFor:   time.Duration(api.KeepFiringFor), // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+		MaxInstances:    api.MaxInstances,                 // LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+		EvictionDelay:   time.Duration(api.EvictionDelay), // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
 	}
 }
 
@@ -2,13 +2,19 @@ package api
 
 // LOGZ.IO GRAFANA CHANGE :: DEV-30169,DEV-30170: add endpoints to evaluate and process alerts
 import (
+	"fmt"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/models"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/web"
+	"github.com/prometheus/alertmanager/pkg/labels"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type LogzioAlertingApi struct {
@@ -18,6 +24,8 @@ type LogzioAlertingApi struct {
 type RunAlertMigrationForOrg struct {
 	OrgId              int64                             `json:"orgId"`
 	EmailNotifications []AlertMigrationEmailNotification `json:"emailNotifications"`
+	// DryRun reports what the migration would create without migrating anything.
+	DryRun bool `json:"dryRun"` // LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
 }
 
 type ClearOrgAlertMigration struct {
@@ -45,6 +53,18 @@ func (api *LogzioAlertingApi) RouteEvaluateAlert(ctx *models.ReqContext) respons
 	return api.service.RouteEvaluateAlert(*ctx.Req, body)
 }
 
+// LOGZ.IO GRAFANA CHANGE :: Batch evaluation endpoint
+func (api *LogzioAlertingApi) RouteBatchEvaluateAlert(ctx *models.ReqContext) response.Response {
+	body := apimodels.AlertBatchEvaluationRequest{}
+	if err := web.Bind(ctx.Req, &body); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	return api.service.RouteBatchEvaluateAlert(*ctx.Req, body)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 func (api *LogzioAlertingApi) RouteProcessAlert(ctx *models.ReqContext) response.Response {
 	body := apimodels.AlertProcessRequest{}
 	if err := web.Bind(ctx.Req, &body); err != nil {
@@ -54,6 +74,30 @@ func (api *LogzioAlertingApi) RouteProcessAlert(ctx *models.ReqContext) response
 	return api.service.RouteProcessAlert(*ctx.Req, body)
 }
 
+// LOGZ.IO GRAFANA CHANGE :: Combined evaluate-and-process endpoint
+func (api *LogzioAlertingApi) RouteEvaluateAndProcessAlert(ctx *models.ReqContext) response.Response {
+	body := apimodels.AlertEvaluateAndProcessRequest{}
+	if err := web.Bind(ctx.Req, &body); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	return api.service.RouteEvaluateAndProcessAlert(*ctx.Req, body)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Alert rule backtesting endpoint
+func (api *LogzioAlertingApi) RouteBacktestAlert(ctx *models.ReqContext) response.Response {
+	body := apimodels.AlertBacktestRequest{}
+	if err := web.Bind(ctx.Req, &body); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	return api.service.RouteBacktestAlert(*ctx.Req, body)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 func (api *LogzioAlertingApi) RouteMigrateOrg(ctx *models.ReqContext) response.Response {
 	body := RunAlertMigrationForOrg{}
 
@@ -61,9 +105,103 @@ func (api *LogzioAlertingApi) RouteMigrateOrg(ctx *models.ReqContext) response.R
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
 
-	return api.service.RouteMigrateOrg(body)
+	return api.service.RouteMigrateOrg(ctx.Req.Context(), body)
 }
 
+// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+func (api *LogzioAlertingApi) RouteGetOrgMigrationStatus(ctx *models.ReqContext) response.Response {
+	orgId, err := strconv.ParseInt(web.Params(ctx.Req)[":OrgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	return api.service.RouteGetOrgMigrationStatus(ctx.Req.Context(), orgId)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+func (api *LogzioAlertingApi) RouteGetStatesScheduledForEviction(ctx *models.ReqContext) response.Response {
+	orgId, err := strconv.ParseInt(web.Params(ctx.Req)[":OrgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	return api.service.RouteGetStatesScheduledForEviction(orgId)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+func (api *LogzioAlertingApi) RouteGetStateHistory(ctx *models.ReqContext) response.Response {
+	orgId, err := strconv.ParseInt(web.Params(ctx.Req)[":OrgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	query := ngmodels.GetStateHistoryQuery{
+		OrgID:   orgId,
+		RuleUID: ctx.Query("ruleUID"),
+		State:   ctx.Query("state"),
+	}
+	for _, m := range ctx.QueryStrings("matcher") {
+		matcher, err := labels.ParseMatcher(m)
+		if err != nil {
+			return response.Error(http.StatusBadRequest, fmt.Sprintf("matcher %q is invalid", m), err)
+		}
+		query.Matchers = append(query.Matchers, matcher)
+	}
+	if query.From, err = parseStateHistoryTime(ctx.Query("from")); err != nil {
+		return response.Error(http.StatusBadRequest, "from is invalid", err)
+	}
+	if query.To, err = parseStateHistoryTime(ctx.Query("to")); err != nil {
+		return response.Error(http.StatusBadRequest, "to is invalid", err)
+	}
+	if limit := ctx.Query("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit < 0 {
+			return response.Error(http.StatusBadRequest, "limit is invalid", err)
+		}
+	}
+
+	return api.service.RouteGetStateHistory(ctx.Req.Context(), query)
+}
+
+// parseStateHistoryTime parses a time given either in RFC 3339 or in milliseconds since the Unix epoch.
+func parseStateHistoryTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Notification delivery log
+func (api *LogzioAlertingApi) RouteGetNotificationDeliveryLog(ctx *models.ReqContext) response.Response {
+	orgId, err := strconv.ParseInt(web.Params(ctx.Req)[":OrgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	query := notifier.DeliveryLogQuery{
+		Receiver:       ctx.Query("receiver"),
+		IntegrationUID: ctx.Query("integrationUid"),
+		Failed:         ctx.QueryBoolWithDefault("failed", false),
+	}
+	if limit := ctx.Query("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit < 0 {
+			return response.Error(http.StatusBadRequest, "limit is invalid", err)
+		}
+	}
+
+	return api.service.RouteGetNotificationDeliveryLog(orgId, query)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 func (api *LogzioAlertingApi) RouteClearOrgMigration(ctx *models.ReqContext) response.Response {
 	body := ClearOrgAlertMigration{}
 
@@ -71,7 +209,7 @@ func (api *LogzioAlertingApi) RouteClearOrgMigration(ctx *models.ReqContext) res
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
 
-	return api.service.RouteClearOrgMigration(body)
+	return api.service.RouteClearOrgMigration(ctx.Req.Context(), body)
 }
 
 func (api *API) RegisterLogzioAlertingApiEndpoints(srv *LogzioAlertingApi, m *metrics.API) {
@@ -85,6 +223,17 @@ func (api *API) RegisterLogzioAlertingApiEndpoints(srv *LogzioAlertingApi, m *me
 				m,
 			),
 		)
+		// LOGZ.IO GRAFANA CHANGE :: Batch evaluation endpoint
+		group.Post(
+			toMacaronPath("/internal/alert/api/v1/eval/batch"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/internal/alert/api/v1/eval/batch",
+				srv.RouteBatchEvaluateAlert,
+				m,
+			),
+		)
+		// LOGZ.IO GRAFANA CHANGE :: end
 		group.Post(
 			toMacaronPath("/internal/alert/api/v1/process"),
 			metrics.Instrument(
@@ -94,6 +243,28 @@ func (api *API) RegisterLogzioAlertingApiEndpoints(srv *LogzioAlertingApi, m *me
 				m,
 			),
 		)
+		// LOGZ.IO GRAFANA CHANGE :: Combined evaluate-and-process endpoint
+		group.Post(
+			toMacaronPath("/internal/alert/api/v1/eval-and-process"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/internal/alert/api/v1/eval-and-process",
+				srv.RouteEvaluateAndProcessAlert,
+				m,
+			),
+		)
+		// LOGZ.IO GRAFANA CHANGE :: end
+		// LOGZ.IO GRAFANA CHANGE :: Alert rule backtesting endpoint
+		group.Post(
+			toMacaronPath("/internal/alert/api/v1/backtest"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/internal/alert/api/v1/backtest",
+				srv.RouteBacktestAlert,
+				m,
+			),
+		)
+		// LOGZ.IO GRAFANA CHANGE :: end
 		group.Post(
 			toMacaronPath("/internal/alert/api/v1/migrate-org"),
 			metrics.Instrument(
@@ -103,6 +274,50 @@ func (api *API) RegisterLogzioAlertingApiEndpoints(srv *LogzioAlertingApi, m *me
 				m,
 			),
 		)
+		// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+		group.Get(
+			toMacaronPath("/internal/alert/api/v1/migrate-org/{OrgId}/status"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/internal/alert/api/v1/migrate-org/{OrgId}/status",
+				srv.RouteGetOrgMigrationStatus,
+				m,
+			),
+		)
+		// LOGZ.IO GRAFANA CHANGE :: end
+		// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+		group.Get(
+			toMacaronPath("/internal/alert/api/v1/state/eviction/{OrgId}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/internal/alert/api/v1/state/eviction/{OrgId}",
+				srv.RouteGetStatesScheduledForEviction,
+				m,
+			),
+		)
+		// LOGZ.IO GRAFANA CHANGE :: end
+		// LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+		group.Get(
+			toMacaronPath("/internal/alert/api/v1/state/history/{OrgId}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/internal/alert/api/v1/state/history/{OrgId}",
+				srv.RouteGetStateHistory,
+				m,
+			),
+		)
+		// LOGZ.IO GRAFANA CHANGE :: end
+		// LOGZ.IO GRAFANA CHANGE :: Notification delivery log
+		group.Get(
+			toMacaronPath("/internal/alert/api/v1/notifications/delivery-log/{OrgId}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/internal/alert/api/v1/notifications/delivery-log/{OrgId}",
+				srv.RouteGetNotificationDeliveryLog,
+				m,
+			),
+		)
+		// LOGZ.IO GRAFANA CHANGE :: end
 		group.Post(
 			toMacaronPath("/internal/alert/api/v1/clear-org-migration"),
 			metrics.Instrument(
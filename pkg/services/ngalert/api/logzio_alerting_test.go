@@ -0,0 +1,479 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore/mockstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeProcessedRequestsCache struct {
+	mtx   sync.Mutex
+	items map[string]interface{}
+	gets  int
+	sets  int
+	err   error
+	// block, if set, blocks the lookups until it is closed; entered receives a value when a lookup starts
+	block   chan struct{}
+	entered chan struct{}
+}
+
+func newFakeProcessedRequestsCache() *fakeProcessedRequestsCache {
+	return &fakeProcessedRequestsCache{items: make(map[string]interface{})}
+}
+
+func (c *fakeProcessedRequestsCache) Get(_ context.Context, key string) (interface{}, error) {
+	c.mtx.Lock()
+	c.gets++
+	block, entered := c.block, c.entered
+	c.mtx.Unlock()
+	if entered != nil {
+		entered <- struct{}{}
+	}
+	if block != nil {
+		<-block
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.err != nil {
+		return nil, c.err
+	}
+	item, ok := c.items[key]
+	if !ok {
+		return nil, remotecache.ErrCacheItemNotFound
+	}
+	return item, nil
+}
+
+func (c *fakeProcessedRequestsCache) Set(_ context.Context, key string, value interface{}, _ time.Duration) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.sets++
+	if c.err != nil {
+		return c.err
+	}
+	c.items[key] = value
+	return nil
+}
+
+func (c *fakeProcessedRequestsCache) Delete(_ context.Context, key string) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	delete(c.items, key)
+	return nil
+}
+
+func (c *fakeProcessedRequestsCache) counts() (int, int) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.gets, c.sets
+}
+
+func createLogzioAlertingSrv(cache remotecache.CacheStorage, ttl time.Duration) *LogzioAlertingService {
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting.ProcessIdempotencyTTL = ttl
+	return &LogzioAlertingService{
+		Cfg:                    cfg,
+		Clock:                  clock.NewMock(),
+		StateManager:           state.NewIsolatedManager(log.NewNopLogger(), nil, nil),
+		Log:                    log.NewNopLogger(),
+		ProcessedRequestsCache: cache,
+	}
+}
+
+func createProcessRequest(ruleUID string, evaluatedAt time.Time) apimodels.AlertProcessRequest {
+	manage := false
+	return apimodels.AlertProcessRequest{
+		ShouldManageAnnotationsAndInstances: &manage,
+		AlertRule:                           apimodels.ApiAlertRule{OrgID: 1, UID: ruleUID, IntervalSeconds: 10},
+		EvaluationResults: []apimodels.ApiEvalResult{
+			{Instance: data.Labels{"series": "a"}, State: eval.Normal, EvaluatedAt: evaluatedAt},
+		},
+	}
+}
+
+func createProcessHttpRequest(idempotencyKey string) http.Request {
+	req := http.Request{Header: http.Header{}}
+	req = *req.WithContext(context.Background())
+	if idempotencyKey != "" {
+		req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+	}
+	return req
+}
+
+func isReplay(resp response.Response) bool {
+	normal, ok := resp.(*response.NormalResponse)
+	return ok && normal.Header().Get(IdempotentReplayHeader) == "true"
+}
+
+func TestProcessIdempotencyKey(t *testing.T) {
+	evaluatedAt := time.Unix(1000, 0)
+
+	require.Equal(t, "1:rule:retry-1", processIdempotencyKey(createProcessHttpRequest("retry-1"), createProcessRequest("rule", evaluatedAt)))
+	require.Equal(t, "1:other:retry-1", processIdempotencyKey(createProcessHttpRequest("retry-1"), createProcessRequest("other", evaluatedAt)))
+	require.Equal(t, "1:rule:1000000000000", processIdempotencyKey(createProcessHttpRequest(""), createProcessRequest("rule", evaluatedAt)))
+	require.Empty(t, processIdempotencyKey(createProcessHttpRequest(""), createProcessRequest("", evaluatedAt)))
+	require.Empty(t, processIdempotencyKey(createProcessHttpRequest(""), createProcessRequest("rule", time.Time{})))
+}
+
+func TestRouteProcessAlertIdempotency(t *testing.T) {
+	evaluatedAt := time.Unix(1000, 0)
+
+	t.Run("retries are replayed from the cache", func(t *testing.T) {
+		cache := newFakeProcessedRequestsCache()
+		srv := createLogzioAlertingSrv(cache, time.Minute)
+
+		first := srv.RouteProcessAlert(createProcessHttpRequest(""), createProcessRequest("rule", evaluatedAt))
+		require.Equal(t, http.StatusOK, first.Status())
+		require.False(t, isReplay(first))
+		require.Contains(t, cache.items, processedRequestCachePrefix+"1:rule:1000000000000")
+
+		retry := srv.RouteProcessAlert(createProcessHttpRequest(""), createProcessRequest("rule", evaluatedAt))
+		require.Equal(t, http.StatusOK, retry.Status())
+		require.True(t, isReplay(retry))
+		require.JSONEq(t, string(first.Body()), string(retry.Body()))
+		_, sets := cache.counts()
+		require.Equal(t, 1, sets)
+	})
+
+	t.Run("the idempotency key of the header is scoped to the rule", func(t *testing.T) {
+		cache := newFakeProcessedRequestsCache()
+		srv := createLogzioAlertingSrv(cache, time.Minute)
+
+		resp := srv.RouteProcessAlert(createProcessHttpRequest("retry-1"), createProcessRequest("rule", evaluatedAt))
+		require.False(t, isReplay(resp))
+		resp = srv.RouteProcessAlert(createProcessHttpRequest("retry-1"), createProcessRequest("other", evaluatedAt))
+		require.False(t, isReplay(resp))
+		resp = srv.RouteProcessAlert(createProcessHttpRequest("retry-1"), createProcessRequest("rule", evaluatedAt.Add(time.Minute)))
+		require.True(t, isReplay(resp))
+		require.Len(t, cache.items, 2)
+	})
+
+	t.Run("concurrent retries wait for the first request", func(t *testing.T) {
+		cache := newFakeProcessedRequestsCache()
+		cache.block = make(chan struct{})
+		cache.entered = make(chan struct{}, 2)
+		srv := createLogzioAlertingSrv(cache, time.Minute)
+
+		responses := make([]response.Response, 2)
+		var wg sync.WaitGroup
+		for i := range responses {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				responses[i] = srv.RouteProcessAlert(createProcessHttpRequest("retry-1"), createProcessRequest("rule", evaluatedAt))
+			}(i)
+			if i == 0 {
+				<-cache.entered
+			}
+		}
+		// give the second request the time to join the first one
+		time.Sleep(100 * time.Millisecond)
+		close(cache.block)
+		wg.Wait()
+
+		gets, sets := cache.counts()
+		require.Equal(t, 1, gets)
+		require.Equal(t, 1, sets)
+		require.Same(t, responses[0], responses[1])
+	})
+
+	t.Run("requests are processed without a ttl", func(t *testing.T) {
+		cache := newFakeProcessedRequestsCache()
+		srv := createLogzioAlertingSrv(cache, 0)
+
+		for i := 0; i < 2; i++ {
+			resp := srv.RouteProcessAlert(createProcessHttpRequest("retry-1"), createProcessRequest("rule", evaluatedAt))
+			require.Equal(t, http.StatusOK, resp.Status())
+			require.False(t, isReplay(resp))
+		}
+		gets, sets := cache.counts()
+		require.Zero(t, gets)
+		require.Zero(t, sets)
+	})
+
+	t.Run("requests are processed when the cache fails", func(t *testing.T) {
+		cache := newFakeProcessedRequestsCache()
+		cache.err = errors.New("cache unavailable")
+		srv := createLogzioAlertingSrv(cache, time.Minute)
+
+		for i := 0; i < 2; i++ {
+			resp := srv.RouteProcessAlert(createProcessHttpRequest("retry-1"), createProcessRequest("rule", evaluatedAt))
+			require.Equal(t, http.StatusOK, resp.Status())
+			require.False(t, isReplay(resp))
+		}
+		gets, sets := cache.counts()
+		require.Equal(t, 2, gets)
+		require.Equal(t, 2, sets)
+	})
+}
+
+// fakeConditionEvaluator evaluates the conditions with evaluate and records the evaluation context of every call.
+type fakeConditionEvaluator struct {
+	mtx      sync.Mutex
+	evaluate func(condition *ngmodels.Condition, now time.Time) (eval.Results, error)
+	contexts []*ngmodels.LogzioAlertRuleEvalContext
+}
+
+func (e *fakeConditionEvaluator) ConditionEval(condition *ngmodels.Condition, now time.Time, _ *expr.Service, ctx *ngmodels.LogzioAlertRuleEvalContext) (eval.Results, error) {
+	e.mtx.Lock()
+	e.contexts = append(e.contexts, ctx)
+	e.mtx.Unlock()
+	return e.evaluate(condition, now)
+}
+
+func (e *fakeConditionEvaluator) QueriesAndExpressionsEval(int64, []ngmodels.AlertQuery, time.Time, *expr.Service, http.Header) (*backend.QueryDataResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// alertingEvaluator returns a single alerting result for the series a at every evaluation.
+func alertingEvaluator() *fakeConditionEvaluator {
+	return &fakeConditionEvaluator{evaluate: func(_ *ngmodels.Condition, now time.Time) (eval.Results, error) {
+		return eval.Results{{Instance: data.Labels{"series": "a"}, State: eval.Alerting, EvaluatedAt: now}}, nil
+	}}
+}
+
+func createLogzioEvaluationSrv(evaluator eval.Evaluator) (*LogzioAlertingService, *store.FakeInstanceStore) {
+	instanceStore := &store.FakeInstanceStore{}
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting.BatchEvaluationMaxWorkers = 2
+	cfg.UnifiedAlerting.BatchEvaluationRuleTimeout = 10 * time.Second
+	return &LogzioAlertingService{
+		Cfg:           cfg,
+		Clock:         clock.NewMock(),
+		Evaluator:     evaluator,
+		StateManager:  state.NewManager(log.NewNopLogger(), metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(), nil, nil, instanceStore, mockstore.NewSQLStoreMock()),
+		InstanceStore: instanceStore,
+		Log:           log.NewNopLogger(),
+	}, instanceStore
+}
+
+// savedInstances returns the alert instances saved in the instance store.
+func savedInstances(instanceStore *store.FakeInstanceStore) []ngmodels.AlertInstance {
+	var instances []ngmodels.AlertInstance
+	for _, op := range instanceStore.RecordedOps {
+		if saved, ok := op.([]ngmodels.AlertInstance); ok {
+			instances = append(instances, saved...)
+		}
+	}
+	return instances
+}
+
+func createHttpRequest() http.Request {
+	return *(&http.Request{Header: http.Header{}}).WithContext(context.Background())
+}
+
+func TestRouteBatchEvaluateAlert(t *testing.T) {
+	evalTime := time.Unix(1000, 0)
+	evalRequest := func(ruleUID, condition string) apimodels.AlertEvaluationRequest {
+		return apimodels.AlertEvaluationRequest{
+			AlertRule: apimodels.ApiAlertRule{OrgID: 1, UID: ruleUID, Condition: condition, IntervalSeconds: 10},
+			EvalTime:  evalTime,
+		}
+	}
+
+	t.Run("the results and errors are keyed by rule UID", func(t *testing.T) {
+		evaluator := &fakeConditionEvaluator{evaluate: func(condition *ngmodels.Condition, now time.Time) (eval.Results, error) {
+			if condition.Condition == "B" {
+				return nil, errors.New("query failed")
+			}
+			return eval.Results{{Instance: data.Labels{"series": "a"}, State: eval.Alerting, EvaluatedAt: now}}, nil
+		}}
+		srv, _ := createLogzioEvaluationSrv(evaluator)
+
+		resp := srv.RouteBatchEvaluateAlert(createHttpRequest(), apimodels.AlertBatchEvaluationRequest{
+			Requests: []apimodels.AlertEvaluationRequest{evalRequest("a", "A"), evalRequest("b", "B")},
+		})
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var batchResponse apimodels.AlertBatchEvaluationResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &batchResponse))
+		require.Len(t, batchResponse.Results, 2)
+		require.Nil(t, batchResponse.Results["a"].Error)
+		require.Len(t, batchResponse.Results["a"].Results, 1)
+		require.Equal(t, eval.Alerting, batchResponse.Results["a"].Results[0].State)
+		require.Empty(t, batchResponse.Results["b"].Results)
+		require.Equal(t, &apimodels.ApiEvalError{Type: OtherErrorType, Message: "query failed", Metadata: map[string]string{}}, batchResponse.Results["b"].Error)
+	})
+
+	t.Run("the rule timeout does not exceed the configured maximum", func(t *testing.T) {
+		for ruleTimeout, expected := range map[string]time.Duration{
+			"":   10 * time.Second,
+			"5s": 5 * time.Second,
+			"1m": 10 * time.Second,
+		} {
+			evaluator := alertingEvaluator()
+			srv, _ := createLogzioEvaluationSrv(evaluator)
+
+			var batchRequest apimodels.AlertBatchEvaluationRequest
+			body := `{"requests": [{"alertRule": {"uid": "a"}}]}`
+			if ruleTimeout != "" {
+				body = `{"requests": [{"alertRule": {"uid": "a"}}], "ruleTimeout": "` + ruleTimeout + `"}`
+			}
+			require.NoError(t, json.Unmarshal([]byte(body), &batchRequest))
+
+			resp := srv.RouteBatchEvaluateAlert(createHttpRequest(), batchRequest)
+			require.Equal(t, http.StatusOK, resp.Status())
+			require.Len(t, evaluator.contexts, 1)
+			require.Equal(t, expected, evaluator.contexts[0].EvaluationTimeout, ruleTimeout)
+		}
+	})
+
+	t.Run("invalid batches are rejected", func(t *testing.T) {
+		for name, requests := range map[string][]apimodels.AlertEvaluationRequest{
+			"no rules":            nil,
+			"rule without UID":    {evalRequest("a", "A"), evalRequest("", "A")},
+			"rule more than once": {evalRequest("a", "A"), evalRequest("a", "A")},
+		} {
+			evaluator := alertingEvaluator()
+			srv, _ := createLogzioEvaluationSrv(evaluator)
+
+			resp := srv.RouteBatchEvaluateAlert(createHttpRequest(), apimodels.AlertBatchEvaluationRequest{Requests: requests})
+			require.Equal(t, http.StatusBadRequest, resp.Status(), name)
+			require.Empty(t, evaluator.contexts, name)
+		}
+	})
+}
+
+func TestRouteEvaluateAndProcessAlert(t *testing.T) {
+	annotationsRepo := store.NewFakeAnnotationsRepo()
+	annotations.SetRepository(annotationsRepo)
+
+	manage := true
+	// the rule is pending after the first alerting result, so that no alert is sent to the Alertmanager
+	request := func(dryRun bool) apimodels.AlertEvaluateAndProcessRequest {
+		return apimodels.AlertEvaluateAndProcessRequest{
+			ShouldManageAnnotationsAndInstances: &manage,
+			AlertRule:                           apimodels.ApiAlertRule{OrgID: 1, UID: "rule", Title: "rule", IntervalSeconds: 10, For: time.Minute},
+			EvalTime:                            time.Unix(1000, 0),
+			DryRun:                              dryRun,
+		}
+	}
+	transitionsOf := func(t *testing.T, resp response.Response) []apimodels.ApiStateTransition {
+		require.Equal(t, http.StatusOK, resp.Status())
+		var processResponse apimodels.AlertEvaluateAndProcessResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &processResponse))
+		require.Len(t, processResponse.EvaluationResults, 1)
+		require.Empty(t, processResponse.Alerts.PostableAlerts)
+		return processResponse.StateTransitions
+	}
+
+	t.Run("dry-run does not change the states, the instances or the annotations", func(t *testing.T) {
+		srv, instanceStore := createLogzioEvaluationSrv(alertingEvaluator())
+
+		transitions := transitionsOf(t, srv.RouteEvaluateAndProcessAlert(createHttpRequest(), request(true)))
+		require.Len(t, transitions, 1)
+		require.Equal(t, eval.Normal.String(), transitions[0].PreviousState)
+		require.Equal(t, eval.Pending.String(), transitions[0].State)
+		require.True(t, transitions[0].Changed)
+
+		require.Empty(t, srv.StateManager.GetStatesForRuleUID(1, "rule"))
+		require.Empty(t, savedInstances(instanceStore))
+		require.Never(t, func() bool {
+			return annotationsRepo.Len() > 0
+		}, 500*time.Millisecond, 50*time.Millisecond)
+	})
+
+	t.Run("the states, the instances and the annotations are saved", func(t *testing.T) {
+		srv, instanceStore := createLogzioEvaluationSrv(alertingEvaluator())
+
+		transitions := transitionsOf(t, srv.RouteEvaluateAndProcessAlert(createHttpRequest(), request(false)))
+		require.Len(t, transitions, 1)
+		require.Equal(t, eval.Pending.String(), transitions[0].State)
+
+		states := srv.StateManager.GetStatesForRuleUID(1, "rule")
+		require.Len(t, states, 1)
+		require.Equal(t, eval.Pending, states[0].State)
+		require.Len(t, savedInstances(instanceStore), 1)
+		require.Eventually(t, func() bool {
+			return annotationsRepo.Len() == 1
+		}, time.Second, 50*time.Millisecond)
+	})
+}
+
+func TestRouteBacktestAlert(t *testing.T) {
+	from := time.Unix(1000, 0)
+	rule := apimodels.ApiAlertRule{OrgID: 1, UID: "rule", IntervalSeconds: 10}
+
+	t.Run("the states are evaluated at every step", func(t *testing.T) {
+		evaluator := alertingEvaluator()
+		srv, instanceStore := createLogzioEvaluationSrv(evaluator)
+
+		var request apimodels.AlertBacktestRequest
+		require.NoError(t, json.Unmarshal([]byte(`{"alertRule": {"orgID": 1, "uid": "rule", "intervalSeconds": 10}, "from": "1970-01-01T00:16:40Z", "to": "1970-01-01T00:17:00Z"}`), &request))
+		resp := srv.RouteBacktestAlert(createHttpRequest(), request)
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var backtestResponse apimodels.AlertBacktestResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &backtestResponse))
+		require.Equal(t, 3, backtestResponse.Evaluations)
+		require.Len(t, backtestResponse.Timelines, 1)
+		require.Equal(t, "a", backtestResponse.Timelines[0].Labels["series"])
+		require.Equal(t, []apimodels.ApiStatePoint{
+			{Time: from.UTC(), State: eval.Alerting.String()},
+			{Time: from.Add(10 * time.Second).UTC(), State: eval.Alerting.String()},
+			{Time: from.Add(20 * time.Second).UTC(), State: eval.Alerting.String()},
+		}, backtestResponse.Timelines[0].Points)
+
+		require.Len(t, evaluator.contexts, 3)
+		require.Empty(t, srv.StateManager.GetStatesForRuleUID(1, "rule"))
+		require.Empty(t, instanceStore.RecordedOps)
+	})
+
+	t.Run("the step overrides the interval of the alert rule", func(t *testing.T) {
+		evaluator := alertingEvaluator()
+		srv, _ := createLogzioEvaluationSrv(evaluator)
+
+		var request apimodels.AlertBacktestRequest
+		require.NoError(t, json.Unmarshal([]byte(`{"alertRule": {"orgID": 1, "uid": "rule", "intervalSeconds": 10}, "from": "1970-01-01T00:16:40Z", "to": "1970-01-01T00:17:00Z", "step": "5s"}`), &request))
+		resp := srv.RouteBacktestAlert(createHttpRequest(), request)
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var backtestResponse apimodels.AlertBacktestResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &backtestResponse))
+		require.Equal(t, 5, backtestResponse.Evaluations)
+		require.Len(t, evaluator.contexts, 5)
+	})
+
+	t.Run("invalid backtests are rejected", func(t *testing.T) {
+		ruleWithoutInterval := rule
+		ruleWithoutInterval.IntervalSeconds = 0
+		for name, request := range map[string]apimodels.AlertBacktestRequest{
+			"no step":              {AlertRule: ruleWithoutInterval, From: from, To: from.Add(time.Minute)},
+			"no time range":        {AlertRule: rule},
+			"from after to":        {AlertRule: rule, From: from.Add(time.Minute), To: from},
+			"too many evaluations": {AlertRule: rule, From: from, To: from.Add(time.Duration(maxBacktestEvaluations) * 10 * time.Second)},
+		} {
+			evaluator := alertingEvaluator()
+			srv, _ := createLogzioEvaluationSrv(evaluator)
+
+			resp := srv.RouteBacktestAlert(createHttpRequest(), request)
+			require.Equal(t, http.StatusBadRequest, resp.Status(), name)
+			require.Empty(t, evaluator.contexts, name)
+		}
+	})
+}
@@ -466,6 +466,8 @@ type AlertEvaluationRequest struct {
 	AlertRule   ApiAlertRule                          `json:"alertRule"`
 	EvalTime    time.Time                             `json:"evalTime"`
 	DsOverrides []models.EvaluationDatasourceOverride `json:"dsOverrides"`
+	// Explain adds the outcome of every query and expression node to the response. LOGZ.IO GRAFANA CHANGE :: Expose intermediate expression node results
+	Explain bool `json:"explain"`
 }
 
 type AlertProcessRequest struct {
@@ -494,6 +496,10 @@ type ApiAlertRule struct {
 	For             time.Duration              `json:"for"`
 	Annotations     map[string]string          `json:"annotations"`
 	Labels          map[string]string          `json:"labels"`
+	QueryOffset     model.Duration             `json:"queryOffset"`   // LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+	KeepFiringFor   model.Duration             `json:"keepFiringFor"` // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+	MaxInstances    *int64                     `json:"maxInstances"`  // LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+	EvictionDelay   model.Duration             `json:"evictionDelay"` // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
 }
 
 type ApiEvalResult struct {
@@ -524,6 +530,171 @@ type EvalResultResponse struct {
 	Results []ApiEvalResult `json:"results"`
 }
 
+// LOGZ.IO GRAFANA CHANGE :: Expose intermediate expression node results
+// AlertExplainEvaluationResponse is the response of an evaluation request with explain mode enabled.
+type AlertExplainEvaluationResponse struct {
+	Results []ApiEvalResult `json:"results"`
+	Nodes   []ApiNodeResult `json:"nodes"`
+}
+
+// ApiNodeResult is the outcome of a single query or expression node in execution order.
+type ApiNodeResult struct {
+	RefID    string         `json:"refId"`
+	NodeType string         `json:"nodeType"`
+	Duration model.Duration `json:"duration"`
+	Frames   data.Frames    `json:"frames"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Combined evaluate-and-process endpoint
+type AlertEvaluateAndProcessRequest struct {
+	AccountId                           string                                `json:"accountId"`
+	ShouldManageAnnotationsAndInstances *bool                                 `json:"shouldManageAnnotationsAndInstances"`
+	AlertRule                           ApiAlertRule                          `json:"alertRule"`
+	EvalTime                            time.Time                             `json:"evalTime"`
+	DsOverrides                         []models.EvaluationDatasourceOverride `json:"dsOverrides"`
+	// DryRun computes the state transitions and postable alerts without changing the state cache, the alert instances,
+	// the annotations or notifying the Alertmanager.
+	DryRun bool `json:"dryRun"`
+}
+
+type AlertEvaluateAndProcessResponse struct {
+	DryRun            bool                 `json:"dryRun"`
+	EvaluationResults []ApiEvalResult      `json:"evaluationResults"`
+	StateTransitions  []ApiStateTransition `json:"stateTransitions"`
+	Alerts            PostableAlerts       `json:"alerts"`
+}
+
+type ApiStateTransition struct {
+	Labels             data.Labels       `json:"labels"`
+	Annotations        map[string]string `json:"annotations"`
+	PreviousState      string            `json:"previousState"`
+	State              string            `json:"state"`
+	Changed            bool              `json:"changed"`
+	Resolved           bool              `json:"resolved"`
+	StartsAt           time.Time         `json:"startsAt"`
+	EndsAt             time.Time         `json:"endsAt"`
+	LastEvaluationTime time.Time         `json:"lastEvaluationTime"`
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Alert rule backtesting endpoint
+type AlertBacktestRequest struct {
+	AlertRule ApiAlertRule `json:"alertRule"`
+	From      time.Time    `json:"from"`
+	To        time.Time    `json:"to"`
+	// Step is the duration between two evaluations. It defaults to the interval of the alert rule.
+	Step        model.Duration                        `json:"step"`
+	DsOverrides []models.EvaluationDatasourceOverride `json:"dsOverrides"`
+}
+
+type AlertBacktestResponse struct {
+	Evaluations int                `json:"evaluations"`
+	Timelines   []ApiStateTimeline `json:"timelines"`
+}
+
+// ApiStateTimeline is the state of a single alert instance at every evaluation of a backtest.
+type ApiStateTimeline struct {
+	Labels data.Labels     `json:"labels"`
+	Points []ApiStatePoint `json:"points"`
+}
+
+type ApiStatePoint struct {
+	Time  time.Time `json:"time"`
+	State string    `json:"state"`
+	Error string    `json:"error,omitempty"`
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Batch evaluation endpoint
+type AlertBatchEvaluationRequest struct {
+	Requests []AlertEvaluationRequest `json:"requests"`
+	// MaxWorkers limits the number of rules evaluated concurrently. It cannot exceed the configured maximum.
+	MaxWorkers int `json:"maxWorkers"`
+	// RuleTimeout limits the evaluation duration of every rule. It cannot exceed the configured maximum.
+	RuleTimeout model.Duration `json:"ruleTimeout"`
+}
+
+type AlertBatchEvaluationResponse struct {
+	// Results holds the outcome of every evaluated rule keyed by rule UID.
+	Results map[string]AlertBatchEvaluationResult `json:"results"`
+}
+
+type AlertBatchEvaluationResult struct {
+	Results  []ApiEvalResult `json:"results"`
+	Error    *ApiEvalError   `json:"error"`
+	Duration model.Duration  `json:"duration"`
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+type StateEvictionResponse struct {
+	// NextEvictionAt is when the state manager looks for states to evict next.
+	NextEvictionAt time.Time `json:"nextEvictionAt"`
+	// States are the states that are evicted at the next eviction.
+	States []ApiScheduledStateEviction `json:"states"`
+}
+
+type ApiScheduledStateEviction struct {
+	RuleUID            string         `json:"ruleUid"`
+	Labels             data.Labels    `json:"labels"`
+	State              string         `json:"state"`
+	LastEvaluationTime time.Time      `json:"lastEvaluationTime"`
+	EndsAt             time.Time      `json:"endsAt"`
+	EvictionDelay      model.Duration `json:"evictionDelay"`
+	// EvictableAt is when the state became eligible for eviction.
+	EvictableAt time.Time `json:"evictableAt"`
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+type StateHistoryResponse struct {
+	// Transitions are the state transitions that match the query, latest first.
+	Transitions []ApiStateHistoryEntry `json:"transitions"`
+}
+
+type ApiStateHistoryEntry struct {
+	RuleUID       string            `json:"ruleUid"`
+	LabelsHash    string            `json:"labelsHash"`
+	Labels        map[string]string `json:"labels"`
+	PreviousState string            `json:"previousState"`
+	State         string            `json:"state"`
+	// Values are the values of the evaluation that caused the transition. Values that are not numbers are null.
+	Values      map[string]*float64 `json:"values"`
+	EvaluatedAt time.Time           `json:"evaluatedAt"`
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Notification delivery log
+type NotificationDeliveryLogResponse struct {
+	// Deliveries are the notification attempts that match the query, latest first.
+	Deliveries []NotificationDelivery `json:"deliveries"`
+}
+
+// NotificationDelivery is an attempt to deliver a notification to an integration of a receiver.
+type NotificationDelivery struct {
+	Time             time.Time `json:"time"`
+	Receiver         string    `json:"receiver"`
+	Integration      string    `json:"integration"`
+	IntegrationUID   string    `json:"integrationUid"`
+	IntegrationIndex int       `json:"integrationIndex"`
+	// Fingerprints are the fingerprints of the alerts of the notification.
+	Fingerprints []string `json:"fingerprints"`
+	// StatusCode is the status code of the response of the integration, zero if it did not answer over HTTP.
+	StatusCode int            `json:"statusCode,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	Duration   model.Duration `json:"duration"`
+	// Retry is the number of failed attempts to deliver the same alert group to the integration before this one.
+	Retry int `json:"retry"`
+}
+
 // LOGZ.IO GRAFANA CHANGE :: end
 
 func (c *PostableUserConfig) UnmarshalJSON(b []byte) error {
@@ -1028,6 +1199,9 @@ type GettableGrafanaReceiver struct {
 	DisableResolveMessage bool             `json:"disableResolveMessage"`
 	Settings              *simplejson.Json `json:"settings"`
 	SecureFields          map[string]bool  `json:"secureFields"`
+	// LOGZ.IO GRAFANA CHANGE :: Notification delivery log
+	LastDelivery *NotificationDelivery `json:"lastDelivery,omitempty"`
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 type PostableGrafanaReceiver struct {
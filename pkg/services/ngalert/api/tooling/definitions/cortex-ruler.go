@@ -122,6 +122,61 @@ import (
 //     Responses:
 //       202: Ack
 
+// LOGZ.IO GRAFANA CHANGE :: Prometheus rule-file export and import of alert rules
+
+// swagger:route Get /api/ruler/grafana/api/v1/prometheus/rules/{Namespace} ruler RouteGetNamespaceGrafanaPrometheusRules
+//
+// Export the rule groups of a namespace as a Prometheus rule file. Rules backed by a single PromQL query are
+// exported as native alerting rules, every other rule carries its definition in a grafana_alert block.
+//
+//     Produces:
+//     - application/yaml
+//
+//     Responses:
+//       200: Ack
+
+// swagger:route POST /api/ruler/grafana/api/v1/prometheus/rules/{Namespace} ruler RoutePostNamespaceGrafanaPrometheusRules
+//
+// Import a Prometheus rule file into a namespace
+//
+//     Consumes:
+//     - application/yaml
+//
+//     Responses:
+//       202: PrometheusRulesImportResult
+//       400: PrometheusRulesImportResult
+
+// swagger:parameters RouteGetNamespaceGrafanaPrometheusRules RoutePostNamespaceGrafanaPrometheusRules
+type PathNamespacePrometheusRules struct {
+	// in: path
+	Namespace string
+}
+
+// swagger:parameters RoutePostNamespaceGrafanaPrometheusRules
+type PrometheusRulesImportParams struct {
+	// The data source queried by the rules with a native expr.
+	// in: query
+	DatasourceUID string `json:"datasource_uid"`
+}
+
+// PrometheusRulesImportResult lists the titles of the created and updated rules, or why rules cannot be imported.
+// swagger:model
+type PrometheusRulesImportResult struct {
+	Created []string                    `json:"created"`
+	Updated []string                    `json:"updated"`
+	Errors  []PrometheusRuleImportError `json:"errors,omitempty"`
+}
+
+// PrometheusRuleImportError is the reason a rule of a rule file cannot be imported. Errors of a whole group have
+// no rule.
+type PrometheusRuleImportError struct {
+	Group string `json:"group"`
+	Rule  string `json:"rule,omitempty"`
+	Error string `json:"error"`
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 // swagger:parameters RoutePostNameRulesConfig RoutePostNameGrafanaRulesConfig
 type NamespaceConfig struct {
 	// in:path
@@ -368,6 +423,33 @@ type PostableGrafanaRule struct {
 	UID          string              `json:"uid" yaml:"uid"`
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	// Record makes the rule a recording rule that writes the series of one of its queries or expressions as a metric.
+	Record *models.Record `json:"record,omitempty" yaml:"record,omitempty"` // LOGZ.IO GRAFANA CHANGE :: Recording rules
+	// LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+	// QueryOffset shifts the queries of the rule back in time, to leave time for delayed data to be ingested. The query
+	// offset of the existing rule is kept when it is not set.
+	QueryOffset *model.Duration `json:"query_offset,omitempty" yaml:"query_offset,omitempty"`
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+	// IsPaused stops the evaluation of the rule without deleting it.
+	IsPaused bool `json:"is_paused" yaml:"is_paused"`
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+	// KeepFiringFor is how long an alert keeps firing after its condition cleared. The keep firing for of the existing
+	// rule is kept when it is not set.
+	KeepFiringFor *model.Duration `json:"keep_firing_for,omitempty" yaml:"keep_firing_for,omitempty"`
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+	// MaxInstances overrides the maximum number of alert instances of the rule when it is set. Zero is unlimited, and a
+	// negative value removes the override so that the limit of the organization applies. The maximum number of alert
+	// instances of the existing rule is kept when it is not set.
+	MaxInstances *int64 `json:"max_instances,omitempty" yaml:"max_instances,omitempty"`
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+	// EvictionDelay overrides how long after the last evaluation of the rule its states are evicted when it is not zero.
+	// The eviction delay of the existing rule is kept when it is not set.
+	EvictionDelay *model.Duration `json:"eviction_delay,omitempty" yaml:"eviction_delay,omitempty"`
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 // swagger:model
@@ -386,4 +468,10 @@ type GettableGrafanaRule struct {
 	RuleGroup       string              `json:"rule_group" yaml:"rule_group"`
 	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	Record          *models.Record      `json:"record,omitempty" yaml:"record,omitempty"`                   // LOGZ.IO GRAFANA CHANGE :: Recording rules
+	QueryOffset     model.Duration      `json:"query_offset,omitempty" yaml:"query_offset,omitempty"`       // LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+	IsPaused        bool                `json:"is_paused" yaml:"is_paused"`                                 // LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+	KeepFiringFor   model.Duration      `json:"keep_firing_for,omitempty" yaml:"keep_firing_for,omitempty"` // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+	MaxInstances    *int64              `json:"max_instances,omitempty" yaml:"max_instances,omitempty"`     // LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+	EvictionDelay   model.Duration      `json:"eviction_delay,omitempty" yaml:"eviction_delay,omitempty"`   // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
 }
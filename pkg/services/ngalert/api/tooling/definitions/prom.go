@@ -137,6 +137,10 @@ type Alert struct {
 	ActiveAt *time.Time `json:"activeAt"`
 	// required: true
 	Value string `json:"value"`
+	// LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+	// KeepFiringSince is when the condition of a firing alert cleared while it keeps firing.
+	KeepFiringSince *time.Time `json:"keepFiringSince,omitempty"`
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 // override the labels type with a map for generation.
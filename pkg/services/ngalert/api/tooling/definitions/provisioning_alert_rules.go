@@ -94,44 +94,66 @@ type AlertRule struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// readonly: true
 	Provenance models.Provenance `json:"provenance,omitempty"`
+	// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+	// example: false
+	IsPaused bool `json:"isPaused"`
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+	KeepFiringFor time.Duration `json:"keepFiringFor,omitempty"`
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+	// example: 500
+	MaxInstances *int64 `json:"maxInstances,omitempty"`
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+	EvictionDelay time.Duration `json:"evictionDelay,omitempty"`
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 func (a *AlertRule) UpstreamModel() models.AlertRule {
 	return models.AlertRule{
-		ID:           a.ID,
-		UID:          a.UID,
-		OrgID:        a.OrgID,
-		NamespaceUID: a.FolderUID,
-		RuleGroup:    a.RuleGroup,
-		Title:        a.Title,
-		Condition:    a.Condition,
-		Data:         a.Data,
-		Updated:      a.Updated,
-		NoDataState:  a.NoDataState,
-		ExecErrState: a.ExecErrState,
-		For:          a.For,
-		Annotations:  a.Annotations,
-		Labels:       a.Labels,
+		ID:            a.ID,
+		UID:           a.UID,
+		OrgID:         a.OrgID,
+		NamespaceUID:  a.FolderUID,
+		RuleGroup:     a.RuleGroup,
+		Title:         a.Title,
+		Condition:     a.Condition,
+		Data:          a.Data,
+		Updated:       a.Updated,
+		NoDataState:   a.NoDataState,
+		ExecErrState:  a.ExecErrState,
+		For:           a.For,
+		Annotations:   a.Annotations,
+		Labels:        a.Labels,
+		IsPaused:      a.IsPaused,      // LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+		KeepFiringFor: a.KeepFiringFor, // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+		MaxInstances:  a.MaxInstances,  // LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+		EvictionDelay: a.EvictionDelay, // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
 	}
 }
 
 func NewAlertRule(rule models.AlertRule, provenance models.Provenance) AlertRule {
 	return AlertRule{
-		ID:           rule.ID,
-		UID:          rule.UID,
-		OrgID:        rule.OrgID,
-		FolderUID:    rule.NamespaceUID,
-		RuleGroup:    rule.RuleGroup,
-		Title:        rule.Title,
-		For:          rule.For,
-		Condition:    rule.Condition,
-		Data:         rule.Data,
-		Updated:      rule.Updated,
-		NoDataState:  rule.NoDataState,
-		ExecErrState: rule.ExecErrState,
-		Annotations:  rule.Annotations,
-		Labels:       rule.Labels,
-		Provenance:   provenance,
+		ID:            rule.ID,
+		UID:           rule.UID,
+		OrgID:         rule.OrgID,
+		FolderUID:     rule.NamespaceUID,
+		RuleGroup:     rule.RuleGroup,
+		Title:         rule.Title,
+		For:           rule.For,
+		Condition:     rule.Condition,
+		Data:          rule.Data,
+		Updated:       rule.Updated,
+		NoDataState:   rule.NoDataState,
+		ExecErrState:  rule.ExecErrState,
+		Annotations:   rule.Annotations,
+		Labels:        rule.Labels,
+		Provenance:    provenance,
+		IsPaused:      rule.IsPaused,      // LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+		KeepFiringFor: rule.KeepFiringFor, // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+		MaxInstances:  rule.MaxInstances,  // LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+		EvictionDelay: rule.EvictionDelay, // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
 	}
 }
 
@@ -84,6 +84,11 @@ type EmbeddedContactPoint struct {
 	DisableResolveMessage bool `json:"disableResolveMessage"`
 	// readonly: true
 	Provenance string `json:"provenance,omitempty"`
+	// LOGZ.IO GRAFANA CHANGE :: Notification delivery log
+	// LastDelivery is the latest attempt to deliver a notification to the contact point.
+	// readonly: true
+	LastDelivery *NotificationDelivery `json:"lastDelivery,omitempty"`
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 const RedactedValue = "[REDACTED]"
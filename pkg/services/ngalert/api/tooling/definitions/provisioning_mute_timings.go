@@ -0,0 +1,100 @@
+package definitions
+
+// LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+
+import (
+	"github.com/prometheus/alertmanager/config"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// swagger:route GET /api/v1/provisioning/mute-timings provisioning stable RouteGetMuteTimings
+//
+// Get all the mute timings.
+//
+//     Responses:
+//       200: MuteTimings
+
+// swagger:route GET /api/v1/provisioning/mute-timings/{name} provisioning stable RouteGetMuteTiming
+//
+// Get a mute timing.
+//
+//     Responses:
+//       200: MuteTimeInterval
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/mute-timings provisioning stable RoutePostMuteTiming
+//
+// Create a new mute timing.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: MuteTimeInterval
+//       400: ValidationError
+
+// swagger:route PUT /api/v1/provisioning/mute-timings/{name} provisioning stable RoutePutMuteTiming
+//
+// Replace an existing mute timing.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: MuteTimeInterval
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route DELETE /api/v1/provisioning/mute-timings/{name} provisioning stable RouteDeleteMuteTiming
+//
+// Delete a mute timing.
+//
+//     Responses:
+//       204: description: The mute timing was deleted successfully.
+//       404: description: Not found.
+//       409: description: The mute timing is used by a notification policy.
+
+// swagger:parameters RouteGetMuteTiming RoutePutMuteTiming RouteDeleteMuteTiming
+type RouteGetMuteTimingParam struct {
+	// Mute timing name
+	// in:path
+	Name string `json:"name"`
+}
+
+// swagger:parameters RoutePostMuteTiming RoutePutMuteTiming
+type MuteTimingPayload struct {
+	// in:body
+	Body MuteTimeInterval
+}
+
+// swagger:model
+type MuteTimings []MuteTimeInterval
+
+// swagger:model
+type MuteTimeInterval struct {
+	config.MuteTimeInterval `json:",inline" yaml:",inline"`
+	Provenance              models.Provenance `json:"provenance,omitempty"`
+}
+
+func (mt *MuteTimeInterval) ResourceType() string {
+	return "muteTimeInterval"
+}
+
+func (mt *MuteTimeInterval) ResourceID() string {
+	return mt.MuteTimeInterval.Name
+}
+
+// Validate checks the name and the time ranges, weekdays, days of month, months and years of the mute timing.
+// The Alertmanager validates them when it unmarshals its configuration, so the mute timing is validated by
+// marshalling and unmarshalling it again.
+func (mt *MuteTimeInterval) Validate() error {
+	s, err := yaml.Marshal(mt.MuteTimeInterval)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(s, &(mt.MuteTimeInterval))
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
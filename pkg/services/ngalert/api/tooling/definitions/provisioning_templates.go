@@ -0,0 +1,103 @@
+package definitions
+
+// LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// swagger:route GET /api/v1/provisioning/templates provisioning stable RouteGetTemplates
+//
+// Get all message templates.
+//
+//     Responses:
+//       200: MessageTemplates
+
+// swagger:route GET /api/v1/provisioning/templates/{name} provisioning stable RouteGetTemplate
+//
+// Get a message template.
+//
+//     Responses:
+//       200: MessageTemplate
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/templates provisioning stable RoutePostTemplate
+//
+// Create a new message template.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: MessageTemplate
+//       400: ValidationError
+
+// swagger:route PUT /api/v1/provisioning/templates/{name} provisioning stable RoutePutTemplate
+//
+// Replace an existing message template.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: MessageTemplate
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route DELETE /api/v1/provisioning/templates/{name} provisioning stable RouteDeleteTemplate
+//
+// Delete a message template.
+//
+//     Responses:
+//       204: description: The message template was deleted successfully.
+
+// swagger:parameters RouteGetTemplate RoutePutTemplate RouteDeleteTemplate
+type RouteGetTemplateParam struct {
+	// Template name
+	// in:path
+	Name string `json:"name"`
+}
+
+// swagger:parameters RoutePostTemplate RoutePutTemplate
+type MessageTemplatePayload struct {
+	// in:body
+	Body MessageTemplate
+}
+
+// swagger:model
+type MessageTemplates []MessageTemplate
+
+// swagger:model
+type MessageTemplate struct {
+	Name       string            `json:"name"`
+	Template   string            `json:"template"`
+	Provenance models.Provenance `json:"provenance,omitempty"`
+}
+
+func (t *MessageTemplate) ResourceType() string {
+	return "template"
+}
+
+func (t *MessageTemplate) ResourceID() string {
+	return t.Name
+}
+
+// Validate checks that the template has a name that can be used as a file name and a non-empty content.
+// Parsing the content is left to the caller, as it depends on the templates the content is used with.
+func (t *MessageTemplate) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("template must have a name")
+	}
+	if t.Name != filepath.Base(filepath.Clean(t.Name)) {
+		return fmt.Errorf("template name '%s' is not valid", t.Name)
+	}
+	if t.Template == "" {
+		return fmt.Errorf("template must have content")
+	}
+	return nil
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
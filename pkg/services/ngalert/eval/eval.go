@@ -144,6 +144,7 @@ type AlertExecCtx struct {
 	ExpressionsEnabled bool
 	Log                log.Logger
 	LogzioEvalContext  *models.LogzioAlertRuleEvalContext // LOGZ.IO GRAFANA CHANGE :: Pass headers and custom datasource to evaluate alerts
+	LogzioHeaders      *setting.LogzioHeadersSettings     // LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
 
 	Ctx context.Context
 }
@@ -160,15 +161,11 @@ func GetExprRequest(ctx AlertExecCtx, data []models.AlertQuery, now time.Time, d
 	}
 	// LOGZ.IO GRAFANA CHANGE :: Upgrade to 8.4.0
 	logzioEvalContext := ctx.LogzioEvalContext
-	logzioHeaders := m.LogzIoHeaders{RequestHeaders: logzioEvalContext.LogzioHeaders}
+	logzioHeaders := m.LogzIoHeaders{RequestHeaders: logzioEvalContext.LogzioHeaders, Settings: ctx.LogzioHeaders}
 	requestHeaders := make(map[string][]string, len(req.Headers))
 
 	for k, v := range req.Headers {
 		requestHeaders[k] = []string{v}
-	}
-
-	for k, v := range logzioHeaders.GetDatasourceQueryHeaders(requestHeaders) {
-		req.Headers[k] = v[0]
 	} // LOGZ.IO GRAFANA CHANGE :: Upgrade to 8.4.0
 
 	datasources := make(map[string]*m.DataSource, len(data))
@@ -202,8 +199,14 @@ func GetExprRequest(ctx AlertExecCtx, data []models.AlertQuery, now time.Time, d
 					return nil, err
 				}
 				if dsOverride, found := logzioEvalContext.DsOverrideByDsUid[q.DatasourceUID]; found {
-					ds.Url = dsOverride.UrlOverride
+					// LOGZ.IO GRAFANA CHANGE :: Datasource overrides beyond URL
+					ds, err = applyDatasourceOverride(ctx.Ctx, ds, dsOverride, secretsService)
+					if err != nil {
+						return nil, err
+					}
+					// LOGZ.IO GRAFANA CHANGE :: end
 				}
+				logzioHeaders.DatasourceWhitelist = append(logzioHeaders.DatasourceWhitelist, m.LogzioHeadersWhitelistFromJsonData(ds.JsonData)...) // LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
 			}
 			datasources[q.DatasourceUID] = ds
 		}
@@ -234,6 +237,14 @@ func GetExprRequest(ctx AlertExecCtx, data []models.AlertQuery, now time.Time, d
 			QueryType:     q.QueryType,
 		})
 	}
+
+	// LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
+	// the whitelist depends on the queried datasources, the request headers take precedence over their custom headers
+	for k, v := range logzioHeaders.GetDatasourceQueryHeaders(requestHeaders) {
+		req.Headers[k] = v[0]
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
 	return req, nil
 }
 
@@ -602,10 +613,19 @@ func (evalResults Results) AsDataFrame() data.Frame {
 
 // ConditionEval executes conditions and evaluates the result.
 func (e *evaluatorImpl) ConditionEval(condition *models.Condition, now time.Time, expressionService *expr.Service, ctx *models.LogzioAlertRuleEvalContext) (Results, error) { // LOGZ.IO GRAFANA CHANGES
-	alertCtx, cancelFn := context.WithTimeout(context.Background(), e.cfg.UnifiedAlerting.EvaluationTimeout)
+	// LOGZ.IO GRAFANA CHANGE :: Batch evaluation endpoint, expose intermediate expression node results
+	timeout := e.cfg.UnifiedAlerting.EvaluationTimeout
+	if ctx != nil && ctx.EvaluationTimeout > 0 {
+		timeout = ctx.EvaluationTimeout
+	}
+	alertCtx, cancelFn := context.WithTimeout(context.Background(), timeout)
+	if ctx != nil && ctx.DecorateContext != nil {
+		alertCtx = ctx.DecorateContext(alertCtx)
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
 	defer cancelFn()
 
-	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.cfg.ExpressionsEnabled, Log: e.log, LogzioEvalContext: ctx} // LOGZ.IO GRAFANA CHANGES
+	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.cfg.ExpressionsEnabled, Log: e.log, LogzioEvalContext: ctx, LogzioHeaders: &e.cfg.LogzioHeaders} // LOGZ.IO GRAFANA CHANGES
 
 	execResult := executeCondition(alertExecCtx, condition, now, expressionService, e.dataSourceCache, e.secretsService)
 
@@ -619,7 +639,7 @@ func (e *evaluatorImpl) QueriesAndExpressionsEval(orgID int64, data []models.Ale
 	defer cancelFn()
 
 	// LOGZ.IO GRAFANA CHANGE :: Upgrade to 8.4.0
-	alertExecCtx := AlertExecCtx{OrgID: orgID, Ctx: alertCtx, ExpressionsEnabled: e.cfg.ExpressionsEnabled, Log: e.log,
+	alertExecCtx := AlertExecCtx{OrgID: orgID, Ctx: alertCtx, ExpressionsEnabled: e.cfg.ExpressionsEnabled, Log: e.log, LogzioHeaders: &e.cfg.LogzioHeaders,
 		LogzioEvalContext: &models.LogzioAlertRuleEvalContext{
 			DsOverrideByDsUid: map[string]models.EvaluationDatasourceOverride{},
 			LogzioHeaders:     logzIoHeaders,
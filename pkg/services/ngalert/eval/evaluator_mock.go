@@ -6,6 +6,8 @@ import (
 	backend "github.com/grafana/grafana-plugin-sdk-go/backend"
 	expr "github.com/grafana/grafana/pkg/expr"
 
+	http "net/http"
+
 	mock "github.com/stretchr/testify/mock"
 
 	models "github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -26,13 +28,13 @@ func (_m *FakeEvaluator) EXPECT() *FakeEvaluator_Expecter {
 	return &FakeEvaluator_Expecter{mock: &_m.Mock}
 }
 
-// ConditionEval provides a mock function with given fields: condition, now, expressionService
-func (_m *FakeEvaluator) ConditionEval(condition *models.Condition, now time.Time, expressionService *expr.Service) (Results, error) {
-	ret := _m.Called(condition, now, expressionService)
+// ConditionEval provides a mock function with given fields: condition, now, expressionService, ctx
+func (_m *FakeEvaluator) ConditionEval(condition *models.Condition, now time.Time, expressionService *expr.Service, ctx *models.LogzioAlertRuleEvalContext) (Results, error) {
+	ret := _m.Called(condition, now, expressionService, ctx)
 
 	var r0 Results
-	if rf, ok := ret.Get(0).(func(*models.Condition, time.Time, *expr.Service) Results); ok {
-		r0 = rf(condition, now, expressionService)
+	if rf, ok := ret.Get(0).(func(*models.Condition, time.Time, *expr.Service, *models.LogzioAlertRuleEvalContext) Results); ok {
+		r0 = rf(condition, now, expressionService, ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(Results)
@@ -40,8 +42,8 @@ func (_m *FakeEvaluator) ConditionEval(condition *models.Condition, now time.Tim
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*models.Condition, time.Time, *expr.Service) error); ok {
-		r1 = rf(condition, now, expressionService)
+	if rf, ok := ret.Get(1).(func(*models.Condition, time.Time, *expr.Service, *models.LogzioAlertRuleEvalContext) error); ok {
+		r1 = rf(condition, now, expressionService, ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -58,13 +60,14 @@ type FakeEvaluator_ConditionEval_Call struct {
 //  - condition *models.Condition
 //  - now time.Time
 //  - expressionService *expr.Service
-func (_e *FakeEvaluator_Expecter) ConditionEval(condition interface{}, now interface{}, expressionService interface{}) *FakeEvaluator_ConditionEval_Call {
-	return &FakeEvaluator_ConditionEval_Call{Call: _e.mock.On("ConditionEval", condition, now, expressionService)}
+//  - ctx *models.LogzioAlertRuleEvalContext
+func (_e *FakeEvaluator_Expecter) ConditionEval(condition interface{}, now interface{}, expressionService interface{}, ctx interface{}) *FakeEvaluator_ConditionEval_Call {
+	return &FakeEvaluator_ConditionEval_Call{Call: _e.mock.On("ConditionEval", condition, now, expressionService, ctx)}
 }
 
-func (_c *FakeEvaluator_ConditionEval_Call) Run(run func(condition *models.Condition, now time.Time, expressionService *expr.Service)) *FakeEvaluator_ConditionEval_Call {
+func (_c *FakeEvaluator_ConditionEval_Call) Run(run func(condition *models.Condition, now time.Time, expressionService *expr.Service, ctx *models.LogzioAlertRuleEvalContext)) *FakeEvaluator_ConditionEval_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*models.Condition), args[1].(time.Time), args[2].(*expr.Service))
+		run(args[0].(*models.Condition), args[1].(time.Time), args[2].(*expr.Service), args[3].(*models.LogzioAlertRuleEvalContext))
 	})
 	return _c
 }
@@ -74,13 +77,13 @@ func (_c *FakeEvaluator_ConditionEval_Call) Return(_a0 Results, _a1 error) *Fake
 	return _c
 }
 
-// QueriesAndExpressionsEval provides a mock function with given fields: orgID, data, now, expressionService
-func (_m *FakeEvaluator) QueriesAndExpressionsEval(orgID int64, data []models.AlertQuery, now time.Time, expressionService *expr.Service) (*backend.QueryDataResponse, error) {
-	ret := _m.Called(orgID, data, now, expressionService)
+// QueriesAndExpressionsEval provides a mock function with given fields: orgID, data, now, expressionService, logzIoHeaders
+func (_m *FakeEvaluator) QueriesAndExpressionsEval(orgID int64, data []models.AlertQuery, now time.Time, expressionService *expr.Service, logzIoHeaders http.Header) (*backend.QueryDataResponse, error) {
+	ret := _m.Called(orgID, data, now, expressionService, logzIoHeaders)
 
 	var r0 *backend.QueryDataResponse
-	if rf, ok := ret.Get(0).(func(int64, []models.AlertQuery, time.Time, *expr.Service) *backend.QueryDataResponse); ok {
-		r0 = rf(orgID, data, now, expressionService)
+	if rf, ok := ret.Get(0).(func(int64, []models.AlertQuery, time.Time, *expr.Service, http.Header) *backend.QueryDataResponse); ok {
+		r0 = rf(orgID, data, now, expressionService, logzIoHeaders)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*backend.QueryDataResponse)
@@ -88,8 +91,8 @@ func (_m *FakeEvaluator) QueriesAndExpressionsEval(orgID int64, data []models.Al
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int64, []models.AlertQuery, time.Time, *expr.Service) error); ok {
-		r1 = rf(orgID, data, now, expressionService)
+	if rf, ok := ret.Get(1).(func(int64, []models.AlertQuery, time.Time, *expr.Service, http.Header) error); ok {
+		r1 = rf(orgID, data, now, expressionService, logzIoHeaders)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -107,13 +110,14 @@ type FakeEvaluator_QueriesAndExpressionsEval_Call struct {
 //  - data []models.AlertQuery
 //  - now time.Time
 //  - expressionService *expr.Service
-func (_e *FakeEvaluator_Expecter) QueriesAndExpressionsEval(orgID interface{}, data interface{}, now interface{}, expressionService interface{}) *FakeEvaluator_QueriesAndExpressionsEval_Call {
-	return &FakeEvaluator_QueriesAndExpressionsEval_Call{Call: _e.mock.On("QueriesAndExpressionsEval", orgID, data, now, expressionService)}
+//  - logzIoHeaders http.Header
+func (_e *FakeEvaluator_Expecter) QueriesAndExpressionsEval(orgID interface{}, data interface{}, now interface{}, expressionService interface{}, logzIoHeaders interface{}) *FakeEvaluator_QueriesAndExpressionsEval_Call {
+	return &FakeEvaluator_QueriesAndExpressionsEval_Call{Call: _e.mock.On("QueriesAndExpressionsEval", orgID, data, now, expressionService, logzIoHeaders)}
 }
 
-func (_c *FakeEvaluator_QueriesAndExpressionsEval_Call) Run(run func(orgID int64, data []models.AlertQuery, now time.Time, expressionService *expr.Service)) *FakeEvaluator_QueriesAndExpressionsEval_Call {
+func (_c *FakeEvaluator_QueriesAndExpressionsEval_Call) Run(run func(orgID int64, data []models.AlertQuery, now time.Time, expressionService *expr.Service, logzIoHeaders http.Header)) *FakeEvaluator_QueriesAndExpressionsEval_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int64), args[1].([]models.AlertQuery), args[2].(time.Time), args[3].(*expr.Service))
+		run(args[0].(int64), args[1].([]models.AlertQuery), args[2].(time.Time), args[3].(*expr.Service), args[4].(http.Header))
 	})
 	return _c
 }
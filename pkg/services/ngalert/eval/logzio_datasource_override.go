@@ -0,0 +1,181 @@
+package eval
+
+// LOGZ.IO GRAFANA CHANGE :: Datasource overrides beyond URL
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	m "github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// datasourceOverrideSecureJsonDataKey is the secure jsonData key that carries the override of a datasource from the
+// evaluator to the LogzioInstanceProvider of its backend. The override holds secrets, so it is encrypted with the
+// secure jsonData of the datasource, and the instance provider removes it before creating the datasource instance.
+const datasourceOverrideSecureJsonDataKey = "logzioDatasourceOverride"
+
+// datasourceOverrideTypes are the types of the datasources whose backends apply the overrides with the
+// LogzioInstanceProvider.
+var datasourceOverrideTypes = map[string]struct{}{
+	m.DS_PROMETHEUS: {},
+	m.DS_ES:         {},
+	m.DS_LOKI:       {},
+}
+
+// applyDatasourceOverride returns a copy of the datasource with the override applied. The datasource itself is
+// shared with the datasource cache and must not be modified.
+func applyDatasourceOverride(ctx context.Context, ds *m.DataSource, override models.EvaluationDatasourceOverride, secretsService secrets.Service) (*m.DataSource, error) {
+	if err := override.Validate(); err != nil {
+		return nil, err
+	}
+	if _, ok := datasourceOverrideTypes[ds.Type]; !ok {
+		return nil, fmt.Errorf("datasource override %s is not supported by datasources of type %q", override.DsUid, ds.Type)
+	}
+
+	overridden := *ds
+	if override.UrlOverride != "" {
+		overridden.Url = override.UrlOverride
+	}
+
+	raw, err := json.Marshal(override)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := secretsService.Encrypt(ctx, raw, secrets.WithoutScope())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt datasource override %s: %w", override.DsUid, err)
+	}
+	overridden.SecureJsonData = make(map[string][]byte, len(ds.SecureJsonData)+1)
+	for k, v := range ds.SecureJsonData {
+		overridden.SecureJsonData[k] = v
+	}
+	overridden.SecureJsonData[datasourceOverrideSecureJsonDataKey] = encrypted
+
+	return &overridden, nil
+}
+
+// datasourceOverrideFromSettings returns the override carried by the instance settings and the settings without it.
+func datasourceOverrideFromSettings(settings backend.DataSourceInstanceSettings) (*models.EvaluationDatasourceOverride, backend.DataSourceInstanceSettings, error) {
+	rawOverride, ok := settings.DecryptedSecureJSONData[datasourceOverrideSecureJsonDataKey]
+	if !ok {
+		return nil, settings, nil
+	}
+
+	override := &models.EvaluationDatasourceOverride{}
+	if err := json.Unmarshal([]byte(rawOverride), override); err != nil {
+		return nil, settings, fmt.Errorf("error reading datasource override: %w", err)
+	}
+
+	secureJsonData := make(map[string]string, len(settings.DecryptedSecureJSONData))
+	for k, v := range settings.DecryptedSecureJSONData {
+		if k != datasourceOverrideSecureJsonDataKey {
+			secureJsonData[k] = v
+		}
+	}
+	settings.DecryptedSecureJSONData = secureJsonData
+
+	return override, settings, nil
+}
+
+// datasourceOverrideKey identifies the override carried by the instance settings without exposing its secrets.
+func datasourceOverrideKey(settings backend.DataSourceInstanceSettings) string {
+	rawOverride, ok := settings.DecryptedSecureJSONData[datasourceOverrideSecureJsonDataKey]
+	if !ok {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(rawOverride))
+	return hex.EncodeToString(sum[:])
+}
+
+// applyInstanceSettingsOverride moves the override carried by the instance settings into the settings the
+// datasource backends build their HTTP client from (see backend.DataSourceInstanceSettings.HTTPClientOptions).
+func applyInstanceSettingsOverride(settings backend.DataSourceInstanceSettings) (backend.DataSourceInstanceSettings, error) {
+	override, settings, err := datasourceOverrideFromSettings(settings)
+	if err != nil || override == nil {
+		return settings, err
+	}
+
+	var jsonData map[string]interface{}
+	if len(settings.JSONData) > 0 {
+		if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+			return settings, fmt.Errorf("error reading settings: %w", err)
+		}
+	}
+	if jsonData == nil {
+		jsonData = make(map[string]interface{})
+	}
+	secureJsonData := make(map[string]string, len(settings.DecryptedSecureJSONData))
+	for k, v := range settings.DecryptedSecureJSONData {
+		secureJsonData[k] = v
+	}
+
+	headers := make(map[string]string, len(override.Headers)+1)
+	for k, v := range override.Headers {
+		headers[k] = v
+	}
+	if override.BearerToken != "" {
+		headers["Authorization"] = "Bearer " + override.BearerToken
+		// the bearer token replaces any basic authentication configured on the datasource
+		settings.BasicAuthEnabled = false
+		settings.User = ""
+		jsonData["basicAuth"] = false
+	}
+	addCustomHeaders(jsonData, secureJsonData, headers)
+
+	if override.BasicAuth != nil {
+		settings.BasicAuthEnabled = true
+		settings.BasicAuthUser = override.BasicAuth.User
+		secureJsonData["basicAuthPassword"] = override.BasicAuth.Password
+	}
+	if override.TLSSkipVerify != nil {
+		jsonData["tlsSkipVerify"] = *override.TLSSkipVerify
+	}
+	if override.QueryTimeoutSeconds > 0 {
+		jsonData["timeout"] = override.QueryTimeoutSeconds
+	}
+
+	settings.JSONData, err = json.Marshal(jsonData)
+	if err != nil {
+		return settings, err
+	}
+	settings.DecryptedSecureJSONData = secureJsonData
+	return settings, nil
+}
+
+// addCustomHeaders adds the headers as custom HTTP headers of the datasource, replacing the configured headers with the same name.
+func addCustomHeaders(jsonData map[string]interface{}, secureJsonData map[string]string, headers map[string]string) {
+	index := 1
+	for {
+		headerName := fmt.Sprintf("httpHeaderName%d", index)
+		name, ok := jsonData[headerName].(string)
+		if !ok || name == "" {
+			break
+		}
+		if value, found := headers[name]; found {
+			secureJsonData[fmt.Sprintf("httpHeaderValue%d", index)] = value
+			delete(headers, name)
+		}
+		index++
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		jsonData[fmt.Sprintf("httpHeaderName%d", index)] = name
+		secureJsonData[fmt.Sprintf("httpHeaderValue%d", index)] = headers[name]
+		index++
+	}
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
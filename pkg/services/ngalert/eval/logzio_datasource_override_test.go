@@ -0,0 +1,204 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	m "github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins/adapters"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+type fakeInstanceProvider struct {
+	settings []backend.DataSourceInstanceSettings
+}
+
+func (p *fakeInstanceProvider) GetKey(pluginContext backend.PluginContext) (interface{}, error) {
+	return pluginContext.DataSourceInstanceSettings.ID, nil
+}
+
+func (p *fakeInstanceProvider) NeedsUpdate(backend.PluginContext, instancemgmt.CachedInstance) bool {
+	return false
+}
+
+func (p *fakeInstanceProvider) NewInstance(pluginContext backend.PluginContext) (instancemgmt.Instance, error) {
+	p.settings = append(p.settings, *pluginContext.DataSourceInstanceSettings)
+	return struct{}{}, nil
+}
+
+func TestDatasourceOverride(t *testing.T) {
+	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+	newDatasource := func() *m.DataSource {
+		return &m.DataSource{
+			Id:   1,
+			Uid:  "ds",
+			Type: m.DS_PROMETHEUS,
+			Url:  "http://original",
+			JsonData: simplejson.NewFromAny(map[string]interface{}{
+				"httpHeaderName1": "X-Existing",
+				"timeout":         30,
+			}),
+		}
+	}
+	instanceSettings := func(t *testing.T, ds *m.DataSource) backend.DataSourceInstanceSettings {
+		settings, err := adapters.ModelToInstanceSettings(ds, func(secureJsonData map[string][]byte) map[string]string {
+			decrypted, err := secretsService.DecryptJsonData(context.Background(), secureJsonData)
+			require.NoError(t, err)
+			decrypted["httpHeaderValue1"] = "existing"
+			return decrypted
+		})
+		require.NoError(t, err)
+		return *settings
+	}
+
+	t.Run("does not modify the datasource from the cache", func(t *testing.T) {
+		ds := newDatasource()
+		overridden, err := applyDatasourceOverride(context.Background(), ds, models.EvaluationDatasourceOverride{DsUid: "ds", UrlOverride: "http://override"}, secretsService)
+		require.NoError(t, err)
+
+		require.Equal(t, "http://override", overridden.Url)
+		require.Equal(t, "http://original", ds.Url)
+		require.NotContains(t, ds.SecureJsonData, datasourceOverrideSecureJsonDataKey)
+	})
+
+	t.Run("keeps the override secrets out of the jsonData", func(t *testing.T) {
+		overridden, err := applyDatasourceOverride(context.Background(), newDatasource(), models.EvaluationDatasourceOverride{DsUid: "ds", BearerToken: "secret-token"}, secretsService)
+		require.NoError(t, err)
+
+		settings := instanceSettings(t, overridden)
+		require.NotContains(t, string(settings.JSONData), "secret-token")
+		require.NotContains(t, string(overridden.SecureJsonData[datasourceOverrideSecureJsonDataKey]), "secret-token")
+		require.Contains(t, settings.DecryptedSecureJSONData[datasourceOverrideSecureJsonDataKey], "secret-token")
+	})
+
+	t.Run("rejects overrides of datasources that do not support them", func(t *testing.T) {
+		ds := newDatasource()
+		ds.Type = "graphite"
+		_, err := applyDatasourceOverride(context.Background(), ds, models.EvaluationDatasourceOverride{DsUid: "ds", BearerToken: "token"}, secretsService)
+		require.EqualError(t, err, `datasource override ds is not supported by datasources of type "graphite"`)
+	})
+
+	t.Run("rejects basic auth together with bearer token", func(t *testing.T) {
+		_, err := applyDatasourceOverride(context.Background(), newDatasource(), models.EvaluationDatasourceOverride{
+			DsUid:       "ds",
+			BasicAuth:   &models.DatasourceBasicAuthOverride{User: "user", Password: "password"},
+			BearerToken: "token",
+		}, secretsService)
+		require.Error(t, err)
+	})
+
+	t.Run("applies headers, auth, TLS and timeout to the instance settings", func(t *testing.T) {
+		skipVerify := true
+		overridden, err := applyDatasourceOverride(context.Background(), newDatasource(), models.EvaluationDatasourceOverride{
+			DsUid:               "ds",
+			Headers:             map[string]string{"X-Existing": "replaced", "X-Account": "123"},
+			BasicAuth:           &models.DatasourceBasicAuthOverride{User: "user", Password: "password"},
+			TLSSkipVerify:       &skipVerify,
+			QueryTimeoutSeconds: 5,
+		}, secretsService)
+		require.NoError(t, err)
+
+		settings, err := applyInstanceSettingsOverride(instanceSettings(t, overridden))
+		require.NoError(t, err)
+		require.NotContains(t, settings.DecryptedSecureJSONData, datasourceOverrideSecureJsonDataKey)
+
+		opts, err := settings.HTTPClientOptions()
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"X-Existing": "replaced", "X-Account": "123"}, opts.Headers)
+		require.Equal(t, "user", opts.BasicAuth.User)
+		require.Equal(t, "password", opts.BasicAuth.Password)
+		require.True(t, opts.TLS.InsecureSkipVerify)
+		require.Equal(t, int64(5), int64(opts.Timeouts.Timeout.Seconds()))
+	})
+
+	t.Run("applies bearer token as authorization header", func(t *testing.T) {
+		ds := newDatasource()
+		ds.BasicAuth = true
+		ds.BasicAuthUser = "configured"
+		overridden, err := applyDatasourceOverride(context.Background(), ds, models.EvaluationDatasourceOverride{DsUid: "ds", BearerToken: "token"}, secretsService)
+		require.NoError(t, err)
+
+		settings, err := applyInstanceSettingsOverride(instanceSettings(t, overridden))
+		require.NoError(t, err)
+
+		opts, err := settings.HTTPClientOptions()
+		require.NoError(t, err)
+		require.Equal(t, "Bearer token", opts.Headers["Authorization"])
+		require.Nil(t, opts.BasicAuth)
+	})
+
+	t.Run("instance provider keys instances by override", func(t *testing.T) {
+		delegate := &fakeInstanceProvider{}
+		ip := &LogzioInstanceProvider{Delegate: delegate}
+
+		plain := instanceSettings(t, newDatasource())
+		first, err := applyDatasourceOverride(context.Background(), newDatasource(), models.EvaluationDatasourceOverride{DsUid: "ds", Headers: map[string]string{"X-Account": "1"}}, secretsService)
+		require.NoError(t, err)
+		second, err := applyDatasourceOverride(context.Background(), newDatasource(), models.EvaluationDatasourceOverride{DsUid: "ds", Headers: map[string]string{"X-Account": "2"}}, secretsService)
+		require.NoError(t, err)
+
+		keys := make(map[interface{}]struct{})
+		for _, settings := range []backend.DataSourceInstanceSettings{plain, instanceSettings(t, first), instanceSettings(t, second)} {
+			settings := settings
+			key, err := ip.GetKey(backend.PluginContext{DataSourceInstanceSettings: &settings})
+			require.NoError(t, err)
+			keys[key] = struct{}{}
+
+			_, err = ip.NewInstance(backend.PluginContext{DataSourceInstanceSettings: &settings})
+			require.NoError(t, err)
+		}
+		require.Len(t, keys, 3)
+
+		require.Len(t, delegate.settings, 3)
+		var jsonData map[string]interface{}
+		require.NoError(t, json.Unmarshal(delegate.settings[2].JSONData, &jsonData))
+		require.Equal(t, "X-Account", jsonData["httpHeaderName2"])
+		require.Equal(t, "2", delegate.settings[2].DecryptedSecureJSONData["httpHeaderValue2"])
+	})
+
+	t.Run("instance provider bounds the instances of the overrides", func(t *testing.T) {
+		delegate := &fakeInstanceProvider{}
+		ip := &LogzioInstanceProvider{Delegate: delegate}
+		im := instancemgmt.New(ip)
+
+		overrideSettings := func(account int) backend.DataSourceInstanceSettings {
+			overridden, err := applyDatasourceOverride(context.Background(), newDatasource(), models.EvaluationDatasourceOverride{
+				DsUid:   "ds",
+				Headers: map[string]string{"X-Account": strconv.Itoa(account)},
+			}, secretsService)
+			require.NoError(t, err)
+			return instanceSettings(t, overridden)
+		}
+
+		keys := make(map[interface{}]struct{})
+		for account := 0; account < 2*maxOverrideInstances; account++ {
+			settings := overrideSettings(account)
+			pluginContext := backend.PluginContext{DataSourceInstanceSettings: &settings}
+			key, err := ip.GetKey(pluginContext)
+			require.NoError(t, err)
+			keys[key] = struct{}{}
+			_, err = im.Get(pluginContext)
+			require.NoError(t, err)
+		}
+		require.Len(t, keys, maxOverrideInstances)
+		require.Len(t, delegate.settings, 2*maxOverrideInstances)
+
+		// the instance of a recent override is reused, the evicted override gets a new instance
+		for _, account := range []int{2*maxOverrideInstances - 1, 0} {
+			settings := overrideSettings(account)
+			_, err := im.Get(backend.PluginContext{DataSourceInstanceSettings: &settings})
+			require.NoError(t, err)
+		}
+		require.Len(t, delegate.settings, 2*maxOverrideInstances+1)
+		require.Equal(t, "0", delegate.settings[len(delegate.settings)-1].DecryptedSecureJSONData["httpHeaderValue2"])
+	})
+}
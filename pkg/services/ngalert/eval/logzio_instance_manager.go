@@ -6,16 +6,33 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"strconv"
+	"sync" // LOGZ.IO GRAFANA CHANGE :: Datasource overrides beyond URL
 )
 
+// LOGZ.IO GRAFANA CHANGE :: Datasource overrides beyond URL
+// maxOverrideInstances is the number of instances with an override kept per datasource. Once it is reached, the
+// instance of the least recently used override is replaced by the instance of the new one.
+const maxOverrideInstances = 32
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 // LogzioInstanceProvider is the implementation of instancemgmt.InstanceProvider that overrides GetKey function to take
-// into consideration Datasource URL to build a key. The reason for that is that for the sake of alert evaluation we need
-// to override Datasource URL which causes stale values in the cache if we need to update URL to a different one as the default
-// implementation of instance provider uses only datasource ID as a key.
+// into consideration Datasource URL and datasource override to build a key. The reason for that is that for the sake of
+// alert evaluation we need to override Datasource URL which causes stale values in the cache if we need to update URL to
+// a different one as the default implementation of instance provider uses only datasource ID as a key.
 //
-// For the rest of operation (apart from GetKey) it delegates the call to the original instance provider.
+// The instances of the datasource overrides are keyed by one of maxOverrideInstances slots per datasource, so that the
+// evaluations with ever-changing overrides do not grow the instance cache without bounds.
+//
+// NewInstance applies the headers, authentication, TLS and timeout overrides of the evaluation to the instance settings
+// before delegating the call to the original instance provider, as does the rest of operations.
 type LogzioInstanceProvider struct {
 	Delegate instancemgmt.InstanceProvider
+
+	// LOGZ.IO GRAFANA CHANGE :: Datasource overrides beyond URL
+	mtx           sync.Mutex
+	overrideSlots map[int64]*overrideSlots
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 func (ip *LogzioInstanceProvider) GetKey(pluginContext backend.PluginContext) (interface{}, error) {
@@ -23,15 +40,97 @@ func (ip *LogzioInstanceProvider) GetKey(pluginContext backend.PluginContext) (i
 		return nil, fmt.Errorf("data source instance settings cannot be nil")
 	}
 
-	return strconv.FormatInt(pluginContext.DataSourceInstanceSettings.ID, 10) + ":" + pluginContext.DataSourceInstanceSettings.URL, nil
+	// LOGZ.IO GRAFANA CHANGE :: Datasource overrides beyond URL
+	settings := pluginContext.DataSourceInstanceSettings
+	override := overrideIdentity(*settings)
+	if override == "" {
+		return strconv.FormatInt(settings.ID, 10) + ":" + settings.URL, nil
+	}
+	return strconv.FormatInt(settings.ID, 10) + ":override:" + strconv.Itoa(ip.overrideSlot(settings.ID, override)), nil
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 func (ip *LogzioInstanceProvider) NeedsUpdate(pluginContext backend.PluginContext, cachedInstance instancemgmt.CachedInstance) bool {
+	// LOGZ.IO GRAFANA CHANGE :: Datasource overrides beyond URL
+	// the slot of the override was taken over by another override
+	if pluginContext.DataSourceInstanceSettings != nil && cachedInstance.PluginContext.DataSourceInstanceSettings != nil &&
+		overrideIdentity(*pluginContext.DataSourceInstanceSettings) != overrideIdentity(*cachedInstance.PluginContext.DataSourceInstanceSettings) {
+		return true
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
 	return ip.Delegate.NeedsUpdate(pluginContext, cachedInstance)
 }
 
 func (ip *LogzioInstanceProvider) NewInstance(pluginContext backend.PluginContext) (instancemgmt.Instance, error) {
+	// LOGZ.IO GRAFANA CHANGE :: Datasource overrides beyond URL
+	if pluginContext.DataSourceInstanceSettings != nil {
+		settings, err := applyInstanceSettingsOverride(*pluginContext.DataSourceInstanceSettings)
+		if err != nil {
+			return nil, err
+		}
+		pluginContext.DataSourceInstanceSettings = &settings
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
 	return ip.Delegate.NewInstance(pluginContext)
 }
 
+// LOGZ.IO GRAFANA CHANGE :: Datasource overrides beyond URL
+// overrideSlots are the overrides of a datasource that have an instance, with the last time they were used.
+type overrideSlots struct {
+	overrides []string
+	lastUsed  []uint64
+	uses      uint64
+}
+
+// overrideIdentity identifies the URL and the override of the instance settings, it is empty without an override.
+func overrideIdentity(settings backend.DataSourceInstanceSettings) string {
+	overrideKey := datasourceOverrideKey(settings)
+	if overrideKey == "" {
+		return ""
+	}
+	return settings.URL + ":" + overrideKey
+}
+
+// overrideSlot returns the slot of the instance of the override of the datasource. A new override takes a free slot,
+// or the slot of the least recently used override.
+func (ip *LogzioInstanceProvider) overrideSlot(datasourceID int64, override string) int {
+	ip.mtx.Lock()
+	defer ip.mtx.Unlock()
+	if ip.overrideSlots == nil {
+		ip.overrideSlots = make(map[int64]*overrideSlots)
+	}
+	slots, ok := ip.overrideSlots[datasourceID]
+	if !ok {
+		slots = &overrideSlots{}
+		ip.overrideSlots[datasourceID] = slots
+	}
+	slots.uses++
+
+	slot := -1
+	for i, o := range slots.overrides {
+		if o == override {
+			slot = i
+			break
+		}
+	}
+	if slot == -1 && len(slots.overrides) < maxOverrideInstances {
+		slot = len(slots.overrides)
+		slots.overrides = append(slots.overrides, override)
+		slots.lastUsed = append(slots.lastUsed, 0)
+	}
+	if slot == -1 {
+		slot = 0
+		for i, lastUsed := range slots.lastUsed {
+			if lastUsed < slots.lastUsed[slot] {
+				slot = i
+			}
+		}
+		slots.overrides[slot] = override
+	}
+	slots.lastUsed[slot] = slots.uses
+	return slot
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 // LOGZ.IO GRAFANA CHANGE :: end
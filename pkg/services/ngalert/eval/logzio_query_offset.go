@@ -0,0 +1,29 @@
+package eval
+
+// LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ConditionEvalWithQueryOffset evaluates the condition with the evaluation time, and so the relative time range of
+// every query, shifted back by the query offset. The results are reported at the true evaluation time.
+func ConditionEvalWithQueryOffset(e Evaluator, condition *models.Condition, now time.Time, offset time.Duration, expressionService *expr.Service, ctx *models.LogzioAlertRuleEvalContext) (Results, error) {
+	if offset <= 0 {
+		return e.ConditionEval(condition, now, expressionService, ctx)
+	}
+	results, err := e.ConditionEval(condition, now.Add(-offset), expressionService, ctx)
+	for i := range results {
+		results[i].EvaluatedAt = now
+		// the duration is measured from the shifted evaluation time
+		if results[i].EvaluationDuration > offset {
+			results[i].EvaluationDuration -= offset
+		}
+	}
+	return results, err
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -0,0 +1,48 @@
+package eval
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type nowRecordingEvaluator struct {
+	evaluatedAt []time.Time
+}
+
+func (e *nowRecordingEvaluator) ConditionEval(_ *models.Condition, now time.Time, _ *expr.Service, _ *models.LogzioAlertRuleEvalContext) (Results, error) {
+	e.evaluatedAt = append(e.evaluatedAt, now)
+	return Results{{State: Alerting, EvaluatedAt: now, EvaluationDuration: 5*time.Minute + time.Second}}, nil
+}
+
+func (e *nowRecordingEvaluator) QueriesAndExpressionsEval(_ int64, _ []models.AlertQuery, _ time.Time, _ *expr.Service, _ http.Header) (*backend.QueryDataResponse, error) {
+	return nil, nil
+}
+
+func TestConditionEvalWithQueryOffset(t *testing.T) {
+	now := time.Unix(10000, 0)
+
+	t.Run("shifts the evaluation back by the offset and reports the true evaluation time", func(t *testing.T) {
+		e := &nowRecordingEvaluator{}
+		results, err := ConditionEvalWithQueryOffset(e, &models.Condition{}, now, 5*time.Minute, nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, []time.Time{now.Add(-5 * time.Minute)}, e.evaluatedAt)
+		require.Len(t, results, 1)
+		require.Equal(t, now, results[0].EvaluatedAt)
+		require.Equal(t, time.Second, results[0].EvaluationDuration)
+	})
+
+	t.Run("evaluates at the evaluation time without an offset", func(t *testing.T) {
+		e := &nowRecordingEvaluator{}
+		results, err := ConditionEvalWithQueryOffset(e, &models.Condition{}, now, 0, nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, []time.Time{now}, e.evaluatedAt)
+		require.Equal(t, now, results[0].EvaluatedAt)
+	})
+}
@@ -0,0 +1,185 @@
+package image
+
+// LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/grafana/grafana/pkg/components/imguploader"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	screenshotWidth  = 1000
+	screenshotHeight = 500
+	// defaultTimeRange is the time range rendered for rules without a query with a relative time range.
+	defaultTimeRange = 5 * time.Minute
+)
+
+// ErrNoPanel is returned for rules that are not linked to a panel.
+var ErrNoPanel = errors.New("the rule is not linked to a panel")
+
+// Image is the screenshot of the panel linked to a rule.
+type Image struct {
+	// Path is the path of the image on the disk of the instance that rendered it.
+	Path string
+	// URL is where the image was uploaded to. It is empty when no external image storage is configured.
+	URL string
+}
+
+// ImageService takes the screenshots attached to the notifications of the alerts.
+type ImageService interface {
+	// NewImage returns the screenshot of the panel linked to the rule with the ngmodels.DashboardUIDAnnotation and
+	// ngmodels.PanelIDAnnotation annotations, over the time range the rule queried at the given evaluation.
+	NewImage(ctx context.Context, rule *ngmodels.AlertRule, evaluatedAt time.Time) (*Image, error)
+	// Forget drops what is kept about the screenshots of the rule, e.g. when the rule is deleted.
+	Forget(key ngmodels.AlertRuleKey)
+}
+
+// ScreenshotImageService renders the panels with the image renderer and uploads them to the external image storage.
+// The screenshot of the latest evaluation of every rule is cached, so that its alerts share a single screenshot.
+type ScreenshotImageService struct {
+	log      log.Logger
+	renderer rendering.Service
+	uploader imguploader.ImageUploader
+	timeout  time.Duration
+
+	// screenshots makes the alerts of an evaluation wait for the screenshot of the first one, while the screenshots of
+	// other rules or evaluations are taken concurrently
+	screenshots singleflight.Group
+
+	mtx   sync.Mutex
+	cache map[ngmodels.AlertRuleKey]cachedImage
+}
+
+type cachedImage struct {
+	evaluatedAt time.Time
+	image       *Image
+	err         error
+}
+
+func NewScreenshotImageService(renderer rendering.Service, uploader imguploader.ImageUploader, timeout time.Duration) *ScreenshotImageService {
+	return &ScreenshotImageService{
+		log:      log.New("ngalert.image"),
+		renderer: renderer,
+		uploader: uploader,
+		timeout:  timeout,
+		cache:    make(map[ngmodels.AlertRuleKey]cachedImage),
+	}
+}
+
+func (s *ScreenshotImageService) NewImage(ctx context.Context, rule *ngmodels.AlertRule, evaluatedAt time.Time) (*Image, error) {
+	dashboardUID := rule.Annotations[ngmodels.DashboardUIDAnnotation]
+	panelID := rule.Annotations[ngmodels.PanelIDAnnotation]
+	if dashboardUID == "" || panelID == "" {
+		return nil, ErrNoPanel
+	}
+	if _, err := strconv.ParseInt(panelID, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid panel ID %q: %w", panelID, err)
+	}
+
+	key := rule.GetKey()
+	if cached, ok := s.cached(key, evaluatedAt); ok {
+		return cached.image, cached.err
+	}
+	v, err, _ := s.screenshots.Do(fmt.Sprintf("%s/%d", key, evaluatedAt.UnixNano()), func() (interface{}, error) {
+		// the screenshot may have been cached by a call that completed since the cache was checked
+		if cached, ok := s.cached(key, evaluatedAt); ok {
+			return cached.image, cached.err
+		}
+		image, err := s.takeScreenshot(ctx, rule, dashboardUID, panelID, evaluatedAt)
+		s.mtx.Lock()
+		if cached, ok := s.cache[key]; !ok || !cached.evaluatedAt.After(evaluatedAt) {
+			s.cache[key] = cachedImage{evaluatedAt: evaluatedAt, image: image, err: err}
+		}
+		s.mtx.Unlock()
+		return image, err
+	})
+	image, _ := v.(*Image)
+	return image, err
+}
+
+func (s *ScreenshotImageService) Forget(key ngmodels.AlertRuleKey) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.cache, key)
+}
+
+// cached returns the screenshot cached for the evaluation of the rule.
+func (s *ScreenshotImageService) cached(key ngmodels.AlertRuleKey, evaluatedAt time.Time) (cachedImage, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	cached, ok := s.cache[key]
+	return cached, ok && cached.evaluatedAt.Equal(evaluatedAt)
+}
+
+func (s *ScreenshotImageService) takeScreenshot(ctx context.Context, rule *ngmodels.AlertRule, dashboardUID, panelID string, evaluatedAt time.Time) (*Image, error) {
+	from, to := TimeRange(rule, evaluatedAt)
+	opts := rendering.Opts{
+		TimeoutOpts: rendering.TimeoutOpts{
+			Timeout: s.timeout,
+		},
+		AuthOpts: rendering.AuthOpts{
+			OrgID:   rule.OrgID,
+			OrgRole: models.ROLE_ADMIN,
+		},
+		Width:           screenshotWidth,
+		Height:          screenshotHeight,
+		Path:            fmt.Sprintf("d-solo/%s/_?orgId=%d&panelId=%s&from=%d&to=%d", dashboardUID, rule.OrgID, panelID, from.UnixMilli(), to.UnixMilli()),
+		ConcurrentLimit: setting.AlertingRenderLimit,
+		Theme:           models.ThemeDark,
+	}
+
+	start := time.Now()
+	result, err := s.renderer.Render(ctx, opts, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the panel: %w", err)
+	}
+	s.log.Debug("rendered alert panel image", "orgID", rule.OrgID, "alertRuleUID", rule.UID, "path", result.FilePath, "took", time.Since(start))
+
+	image := &Image{Path: result.FilePath}
+	start = time.Now()
+	if image.URL, err = s.uploader.Upload(ctx, result.FilePath); err != nil {
+		return nil, fmt.Errorf("failed to upload the panel image: %w", err)
+	}
+	if image.URL != "" {
+		s.log.Debug("uploaded alert panel image", "orgID", rule.OrgID, "alertRuleUID", rule.UID, "url", image.URL, "took", time.Since(start))
+	}
+	return image, nil
+}
+
+// TimeRange returns the time range the queries of the rule covered at the given evaluation, shifted back by the
+// query offset of the rule.
+func TimeRange(rule *ngmodels.AlertRule, evaluatedAt time.Time) (time.Time, time.Time) {
+	now := evaluatedAt.Add(-rule.QueryOffset)
+	var from, to time.Time
+	for _, query := range rule.Data {
+		if query.RelativeTimeRange.From == 0 && query.RelativeTimeRange.To == 0 {
+			continue
+		}
+		tr := query.RelativeTimeRange.ToTimeRange(now)
+		if from.IsZero() || tr.From.Before(from) {
+			from = tr.From
+		}
+		if to.IsZero() || tr.To.After(to) {
+			to = tr.To
+		}
+	}
+	if from.IsZero() {
+		return now.Add(-defaultTimeRange), now
+	}
+	return from, to
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
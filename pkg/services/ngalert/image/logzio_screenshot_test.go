@@ -0,0 +1,156 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/rendering"
+)
+
+type fakeRenderer struct {
+	rendering.Service
+	mtx   sync.Mutex
+	paths []string
+	err   error
+	// block, if set, blocks the rendering of the paths of the dashboard until it is closed
+	block     chan struct{}
+	blockPath string
+}
+
+func (f *fakeRenderer) Render(_ context.Context, opts rendering.Opts, _ rendering.Session) (*rendering.RenderResult, error) {
+	f.mtx.Lock()
+	f.paths = append(f.paths, opts.Path)
+	block := f.block
+	f.mtx.Unlock()
+	if block != nil && strings.Contains(opts.Path, f.blockPath) {
+		<-block
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &rendering.RenderResult{FilePath: "/tmp/panel.png"}, nil
+}
+
+func (f *fakeRenderer) renderedPaths() []string {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return append([]string(nil), f.paths...)
+}
+
+type fakeUploader struct{}
+
+func (fakeUploader) Upload(_ context.Context, path string) (string, error) {
+	return "https://images.example.com" + path, nil
+}
+
+func TestTimeRange(t *testing.T) {
+	evaluatedAt := time.Unix(10000, 0)
+
+	from, to := TimeRange(&ngmodels.AlertRule{}, evaluatedAt)
+	require.Equal(t, evaluatedAt.Add(-defaultTimeRange), from)
+	require.Equal(t, evaluatedAt, to)
+
+	rule := &ngmodels.AlertRule{
+		QueryOffset: time.Minute,
+		Data: []ngmodels.AlertQuery{
+			{RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(10 * time.Minute), To: ngmodels.Duration(2 * time.Minute)}},
+			{RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(30 * time.Minute)}},
+			{},
+		},
+	}
+	from, to = TimeRange(rule, evaluatedAt)
+	require.Equal(t, evaluatedAt.Add(-31*time.Minute), from)
+	require.Equal(t, evaluatedAt.Add(-time.Minute), to)
+}
+
+func TestScreenshotImageService(t *testing.T) {
+	renderer := &fakeRenderer{}
+	s := NewScreenshotImageService(renderer, fakeUploader{}, time.Second)
+	evaluatedAt := time.Unix(10000, 0)
+	rule := &ngmodels.AlertRule{OrgID: 1, UID: "rule", Annotations: map[string]string{
+		ngmodels.DashboardUIDAnnotation: "dashboard",
+		ngmodels.PanelIDAnnotation:      "2",
+	}}
+
+	t.Run("rules without a panel have no image", func(t *testing.T) {
+		_, err := s.NewImage(context.Background(), &ngmodels.AlertRule{OrgID: 1, UID: "other"}, evaluatedAt)
+		require.ErrorIs(t, err, ErrNoPanel)
+		require.Empty(t, renderer.paths)
+	})
+
+	t.Run("the image of an evaluation is rendered once", func(t *testing.T) {
+		image, err := s.NewImage(context.Background(), rule, evaluatedAt)
+		require.NoError(t, err)
+		require.Equal(t, &Image{Path: "/tmp/panel.png", URL: "https://images.example.com/tmp/panel.png"}, image)
+		require.Equal(t, []string{"d-solo/dashboard/_?orgId=1&panelId=2&from=9700000&to=10000000"}, renderer.paths)
+
+		cached, err := s.NewImage(context.Background(), rule, evaluatedAt)
+		require.NoError(t, err)
+		require.Same(t, image, cached)
+		require.Len(t, renderer.paths, 1)
+	})
+
+	t.Run("the image is rendered again at the next evaluation", func(t *testing.T) {
+		renderer.err = errors.New("renderer unavailable")
+		_, err := s.NewImage(context.Background(), rule, evaluatedAt.Add(time.Minute))
+		require.EqualError(t, err, "failed to render the panel: renderer unavailable")
+		require.Len(t, renderer.paths, 2)
+	})
+
+	t.Run("forgotten rules are rendered again", func(t *testing.T) {
+		renderer.err = nil
+		s.Forget(rule.GetKey())
+		_, err := s.NewImage(context.Background(), rule, evaluatedAt.Add(time.Minute))
+		require.NoError(t, err)
+		require.Len(t, renderer.paths, 3)
+	})
+}
+
+func TestScreenshotImageServiceConcurrency(t *testing.T) {
+	panelRule := func(uid, dashboardUID string) *ngmodels.AlertRule {
+		return &ngmodels.AlertRule{OrgID: 1, UID: uid, Annotations: map[string]string{
+			ngmodels.DashboardUIDAnnotation: dashboardUID,
+			ngmodels.PanelIDAnnotation:      "2",
+		}}
+	}
+	renderer := &fakeRenderer{block: make(chan struct{}), blockPath: "d-solo/slow/"}
+	s := NewScreenshotImageService(renderer, fakeUploader{}, time.Second)
+	evaluatedAt := time.Unix(10000, 0)
+	slow := panelRule("slow", "slow")
+
+	var wg sync.WaitGroup
+	images := make([]*Image, 3)
+	for i := range images {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			image, err := s.NewImage(context.Background(), slow, evaluatedAt)
+			require.NoError(t, err)
+			images[i] = image
+		}(i)
+	}
+
+	// the screenshots of other rules are not held back by the slow one
+	_, err := s.NewImage(context.Background(), panelRule("fast", "fast"), evaluatedAt)
+	require.NoError(t, err)
+
+	close(renderer.block)
+	wg.Wait()
+	for _, image := range images {
+		require.Same(t, images[0], image)
+	}
+	slowRenders := 0
+	for _, path := range renderer.renderedPaths() {
+		if strings.Contains(path, "d-solo/slow/") {
+			slowRenders++
+		}
+	}
+	require.Equal(t, 1, slowRenders, "the alerts of an evaluation share a single screenshot")
+}
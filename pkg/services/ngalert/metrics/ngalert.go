@@ -52,6 +52,10 @@ type Scheduler struct {
 	EvalDuration             *prometheus.SummaryVec
 	GetAlertRulesDuration    prometheus.Histogram
 	SchedulePeriodicDuration prometheus.Histogram
+	// LOGZ.IO GRAFANA CHANGE :: Rule evaluation sharding across HA scheduler instances
+	ShardMembers prometheus.Gauge
+	OwnedRules   prometheus.Gauge
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 type MultiOrgAlertmanager struct {
@@ -71,8 +75,12 @@ type Alertmanager struct {
 }
 
 type State struct {
-	GroupRules *prometheus.GaugeVec
-	AlertState *prometheus.GaugeVec
+	GroupRules    *prometheus.GaugeVec
+	AlertState    *prometheus.GaugeVec
+	EvictedStates *prometheus.CounterVec // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+	// LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+	InstanceLimitExceeded *prometheus.CounterVec
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 func (ng *NGAlert) GetSchedulerMetrics() *Scheduler {
@@ -179,6 +187,20 @@ func newSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 				Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10},
 			},
 		),
+		// LOGZ.IO GRAFANA CHANGE :: Rule evaluation sharding across HA scheduler instances
+		ShardMembers: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "scheduler_shard_members",
+			Help:      "The number of live instances the alert rules are sharded between.",
+		}),
+		OwnedRules: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "scheduler_owned_rules",
+			Help:      "The number of alert rules evaluated by this instance.",
+		}),
+		// LOGZ.IO GRAFANA CHANGE :: end
 	}
 }
 
@@ -201,6 +223,22 @@ func newStateMetrics(r prometheus.Registerer) *State {
 			Name:      "alerts",
 			Help:      "How many alerts by state.",
 		}, []string{"state"}),
+		// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+		EvictedStates: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "evicted_states_total",
+			Help:      "The number of alert states evicted from the state cache.",
+		}, []string{"org"}),
+		// LOGZ.IO GRAFANA CHANGE :: end
+		// LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+		InstanceLimitExceeded: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "rule_instance_limit_exceeded_total",
+			Help:      "The number of rule evaluations whose results exceeded the maximum number of alert instances of the rule.",
+		}, []string{"org"}),
+		// LOGZ.IO GRAFANA CHANGE :: end
 	}
 }
 
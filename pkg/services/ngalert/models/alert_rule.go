@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -90,6 +91,13 @@ const (
 	PanelIDAnnotation            = "__panelId__"
 	AlertRuleStateAnnotationType = "unified_alert_rule"  // LOGZ.IO GRAFANA CHANGE :: DEV-31760 - Retrieve annotations for migrated unified alerts
 	LogzioAccountIdAnnotation    = "__logzioAccountId__" //LOGZ.IO GRAFANA CHANGE :: DEV-37746: Add switch to account query param
+
+	// LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+	// LogzioImageURLAnnotation is the URL of the screenshot of the panel of the rule attached to the notifications of its alerts.
+	LogzioImageURLAnnotation = "__logzioImageURL__"
+	// LogzioImagePathAnnotation is the path of the screenshot of the panel of the rule on the disk of the instance that took it.
+	LogzioImagePathAnnotation = "__logzioImagePath__"
+	// LOGZ.IO GRAFANA CHANGE :: end
 )
 
 // AlertRule is the model for alert rules in unified alerting.
@@ -114,8 +122,58 @@ type AlertRule struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// Record makes the rule a recording rule when set.
+	Record *Record `xorm:"record JSON"` // LOGZ.IO GRAFANA CHANGE :: Recording rules
+	// LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+	// QueryOffset shifts the queries of the rule back in time, to leave time for delayed data to be ingested.
+	QueryOffset time.Duration
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+	// IsPaused stops the evaluation of the rule without deleting it.
+	IsPaused bool
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+	// KeepFiringFor is how long an alert keeps firing after its condition cleared.
+	KeepFiringFor time.Duration
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+	// MaxInstances overrides the maximum number of alert instances of the rule when it is set. Zero is unlimited.
+	MaxInstances *int64
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+	// EvictionDelay overrides how long after the last evaluation of the rule its states are evicted from the state cache
+	// when it is not zero.
+	EvictionDelay time.Duration
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Optional rule fields
+	// UnsetFields are the optional fields the rule was submitted without, so that PatchPartialAlertRule takes them from
+	// the existing rule. They are not stored.
+	UnsetFields AlertRuleFields `xorm:"-"`
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
+// LOGZ.IO GRAFANA CHANGE :: Optional rule fields
+// AlertRuleFields is a set of the optional fields of an alert rule whose zero value is a valid value, so that they are
+// told apart from the fields that are not set.
+type AlertRuleFields uint8
+
+const (
+	QueryOffsetField AlertRuleFields = 1 << iota
+	KeepFiringForField
+	EvictionDelayField
+	MaxInstancesField
+)
+
+// Has returns true if the set contains the field.
+func (f AlertRuleFields) Has(field AlertRuleFields) bool {
+	return f&field != 0
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 type LabelOption func(map[string]string)
 
 func WithoutInternalLabels() LabelOption {
@@ -220,6 +278,31 @@ type AlertRuleVersion struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// Record makes the rule a recording rule when set.
+	Record *Record `xorm:"record JSON"` // LOGZ.IO GRAFANA CHANGE :: Recording rules
+	// LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+	// QueryOffset shifts the queries of the rule back in time, to leave time for delayed data to be ingested.
+	QueryOffset time.Duration
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+	// IsPaused stops the evaluation of the rule without deleting it.
+	IsPaused bool
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+	// KeepFiringFor is how long an alert keeps firing after its condition cleared.
+	KeepFiringFor time.Duration
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+	// MaxInstances overrides the maximum number of alert instances of the rule when it is set. Zero is unlimited.
+	MaxInstances *int64
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+	// EvictionDelay overrides how long after the last evaluation of the rule its states are evicted from the state cache
+	// when it is not zero.
+	EvictionDelay time.Duration
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -296,18 +379,54 @@ func (c Condition) IsValid() bool {
 type LogzioAlertRuleEvalContext struct {
 	LogzioHeaders     http.Header
 	DsOverrideByDsUid map[string]EvaluationDatasourceOverride `json:"dsOverride"`
+	// EvaluationTimeout overrides the configured evaluation timeout when greater than zero.
+	EvaluationTimeout time.Duration `json:"-"` // LOGZ.IO GRAFANA CHANGE :: Batch evaluation endpoint
+	// DecorateContext, when set, returns the context the evaluation runs with from the one it is given, e.g. to record
+	// the outcome of every query and expression node of the evaluation.
+	DecorateContext func(ctx context.Context) context.Context `json:"-"` // LOGZ.IO GRAFANA CHANGE :: Expose intermediate expression node results
 }
 
 type EvaluationDatasourceOverride struct {
 	DsUid       string `json:"dsUid"`
 	UrlOverride string `json:"urlOverride"`
+	// LOGZ.IO GRAFANA CHANGE :: Datasource overrides beyond URL
+	// Headers are sent with every request to the datasource in addition to the headers configured on it.
+	Headers map[string]string `json:"headers,omitempty"`
+	// BasicAuth replaces the basic authentication configured on the datasource.
+	BasicAuth *DatasourceBasicAuthOverride `json:"basicAuth,omitempty"`
+	// BearerToken authenticates the requests to the datasource with an Authorization bearer header.
+	BearerToken string `json:"bearerToken,omitempty"`
+	// TLSSkipVerify replaces the TLS verification setting of the datasource when set.
+	TLSSkipVerify *bool `json:"tlsSkipVerify,omitempty"`
+	// QueryTimeoutSeconds replaces the HTTP request timeout of the datasource when greater than zero.
+	QueryTimeoutSeconds int64 `json:"queryTimeoutSeconds,omitempty"`
+	// LOGZ.IO GRAFANA CHANGE :: end
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Datasource overrides beyond URL
+type DatasourceBasicAuthOverride struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+// Validate checks that the override can be applied to a datasource.
+func (o EvaluationDatasourceOverride) Validate() error {
+	if o.BasicAuth != nil && o.BearerToken != "" {
+		return fmt.Errorf("datasource override %s cannot set both basic auth and bearer token", o.DsUid)
+	}
+	if o.QueryTimeoutSeconds < 0 {
+		return fmt.Errorf("datasource override %s has a negative query timeout", o.DsUid)
+	}
+	return nil
 }
 
 // LOGZ.IO GRAFANA CHANGE :: end
 
 // PatchPartialAlertRule patches `ruleToPatch` by `existingRule` following the rule that if a field of `ruleToPatch` is empty or has the default value, it is populated by the value of the corresponding field from `existingRule`.
 // There are several exceptions:
-// 1. Following fields are not patched and therefore will be ignored: AlertRule.ID, AlertRule.OrgID, AlertRule.Updated, AlertRule.Version, AlertRule.UID, AlertRule.DashboardUID, AlertRule.PanelID, AlertRule.Annotations and AlertRule.Labels
+// 1. Following fields are not patched and therefore will be ignored: AlertRule.ID, AlertRule.OrgID, AlertRule.Updated, AlertRule.Version, AlertRule.UID, AlertRule.DashboardUID, AlertRule.PanelID, AlertRule.Annotations, AlertRule.Labels, AlertRule.Record and AlertRule.IsPaused
 // 2. There are fields that are patched together:
 //    - AlertRule.Condition and AlertRule.Data
 // If either of the pair is specified, neither is patched.
@@ -337,6 +456,21 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRule) {
 	if ruleToPatch.For == 0 {
 		ruleToPatch.For = existingRule.For
 	}
+	// LOGZ.IO GRAFANA CHANGE :: Optional rule fields
+	if ruleToPatch.UnsetFields.Has(QueryOffsetField) {
+		ruleToPatch.QueryOffset = existingRule.QueryOffset
+	}
+	if ruleToPatch.UnsetFields.Has(KeepFiringForField) {
+		ruleToPatch.KeepFiringFor = existingRule.KeepFiringFor
+	}
+	if ruleToPatch.UnsetFields.Has(EvictionDelayField) {
+		ruleToPatch.EvictionDelay = existingRule.EvictionDelay
+	}
+	if ruleToPatch.UnsetFields.Has(MaxInstancesField) {
+		ruleToPatch.MaxInstances = existingRule.MaxInstances
+	}
+	ruleToPatch.UnsetFields = 0
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 func ValidateRuleGroupInterval(intervalSeconds, baseIntervalSeconds int64) error {
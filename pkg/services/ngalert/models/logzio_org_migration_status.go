@@ -0,0 +1,35 @@
+package models
+
+// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrOrgMigrationStatusNotFound is an error for an organization whose alerts were never migrated.
+var ErrOrgMigrationStatusNotFound = errors.New("could not find alert migration status of organization")
+
+type OrgMigrationState string
+
+const (
+	OrgMigrationStarted  OrgMigrationState = "started"
+	OrgMigrationFinished OrgMigrationState = "finished"
+	OrgMigrationFailed   OrgMigrationState = "failed"
+)
+
+// OrgMigrationStatus is the status of the last migration of the legacy alerts of an organization.
+type OrgMigrationStatus struct {
+	ID                 int64             `xorm:"pk autoincr 'id'" json:"-"`
+	OrgID              int64             `xorm:"org_id" json:"orgId"`
+	Status             OrgMigrationState `xorm:"status" json:"status"`
+	StartedAt          time.Time         `xorm:"started_at" json:"startedAt"`
+	FinishedAt         *time.Time        `xorm:"finished_at" json:"finishedAt,omitempty"`
+	Alerts             int               `xorm:"alerts" json:"alerts"`
+	ContactPoints      int               `xorm:"contact_points" json:"contactPoints"`
+	EmailContactPoints int               `xorm:"email_contact_points" json:"emailContactPoints"`
+	Unsupported        int               `xorm:"unsupported" json:"unsupported"`
+	Error              string            `xorm:"error" json:"error,omitempty"`
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -0,0 +1,40 @@
+package models
+
+// LOGZ.IO GRAFANA CHANGE :: Recording rules
+
+import (
+	"fmt"
+
+	"github.com/prometheus/common/model"
+)
+
+// Record makes an alert rule a recording rule. A recording rule is evaluated on the cadence of its group like any
+// other rule, but instead of producing alerts, the series returned by one of its queries or expressions are written
+// as a metric to the configured Prometheus remote-write endpoint.
+type Record struct {
+	// Metric is the name of the metric the series are written as.
+	Metric string `json:"metric" yaml:"metric"`
+	// From is the RefID of the query or expression whose series are written.
+	From string `json:"from" yaml:"from"`
+}
+
+// Validate checks that the metric name is a valid Prometheus metric name and that From is one of the queries or
+// expressions of the rule.
+func (r *Record) Validate(data []AlertQuery) error {
+	if !model.IsValidMetricName(model.LabelValue(r.Metric)) {
+		return fmt.Errorf("%w: %q is not a valid metric name", ErrAlertRuleFailedValidation, r.Metric)
+	}
+	for _, q := range data {
+		if q.RefID == r.From {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: recorded query or expression %q is not found", ErrAlertRuleFailedValidation, r.From)
+}
+
+// IsRecordingRule returns true if the rule writes its series as a metric instead of producing alerts.
+func (alertRule *AlertRule) IsRecordingRule() bool {
+	return alertRule.Record != nil
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
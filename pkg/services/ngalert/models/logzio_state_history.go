@@ -0,0 +1,41 @@
+package models
+
+// LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+
+import (
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+// StateHistoryEntry is a transition of an alert from one state to another.
+type StateHistoryEntry struct {
+	ID            int64             `xorm:"pk autoincr 'id'" json:"-"`
+	OrgID         int64             `xorm:"org_id" json:"orgId"`
+	RuleUID       string            `xorm:"rule_uid" json:"ruleUid"`
+	LabelsHash    string            `xorm:"labels_hash" json:"labelsHash"`
+	Labels        map[string]string `xorm:"labels" json:"labels"`
+	PreviousState string            `xorm:"previous_state" json:"previousState"`
+	State         string            `xorm:"state" json:"state"`
+	// Values are the values of the reduce and math expressions of the evaluation that caused the transition.
+	// Values that are not numbers, such as NaN, are nil.
+	Values map[string]*float64 `xorm:"eval_values" json:"values"`
+	// EvaluatedAt is the time of the evaluation that caused the transition, in milliseconds since the Unix epoch.
+	EvaluatedAt int64 `xorm:"evaluated_at" json:"evaluatedAt"`
+}
+
+// GetStateHistoryQuery is the query for the state transitions of the alerts of an organization. Every filter but the
+// organization is optional. Transitions are returned latest first.
+type GetStateHistoryQuery struct {
+	OrgID    int64
+	RuleUID  string
+	Matchers labels.Matchers
+	State    string
+	From     time.Time
+	To       time.Time
+	Limit    int
+
+	Result []*StateHistoryEntry
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -2,29 +2,36 @@ package ngalert
 
 import (
 	"context"
+	"fmt"
 	"net/url"
+	"time" // LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
 
 	"github.com/benbjohnson/clock"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/imguploader"
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/image"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer" // LOGZ.IO GRAFANA CHANGE :: Recording rules
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
@@ -33,7 +40,8 @@ import (
 func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService, routeRegister routing.RouteRegister,
 	sqlStore *sqlstore.SQLStore, kvStore kvstore.KVStore, expressionService *expr.Service, dataProxy *datasourceproxy.DataSourceProxyService,
 	quotaService *quota.QuotaService, secretsService secrets.Service, notificationService notifications.Service, m *metrics.NGAlert,
-	folderService dashboards.FolderService, ac accesscontrol.AccessControl) (*AlertNG, error) {
+	folderService dashboards.FolderService, ac accesscontrol.AccessControl, remoteCache *remotecache.RemoteCache,
+	renderService rendering.Service) (*AlertNG, error) { // LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
 	ng := &AlertNG{
 		Cfg:                 cfg,
 		DataSourceCache:     dataSourceCache,
@@ -49,6 +57,8 @@ func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService,
 		NotificationService: notificationService,
 		folderService:       folderService,
 		accesscontrol:       ac,
+		RemoteCache:         remoteCache,   // LOGZ.IO GRAFANA CHANGE :: Idempotent alert processing
+		RenderService:       renderService, // LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
 	}
 
 	if ng.IsDisabled() {
@@ -75,6 +85,8 @@ type AlertNG struct {
 	SecretsService      secrets.Service
 	Metrics             *metrics.NGAlert
 	NotificationService notifications.Service
+	RemoteCache         *remotecache.RemoteCache // LOGZ.IO GRAFANA CHANGE :: Idempotent alert processing
+	RenderService       rendering.Service        // LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
 	Log                 log.Logger
 	schedule            schedule.ScheduleService
 	stateManager        *state.Manager
@@ -88,14 +100,9 @@ type AlertNG struct {
 func (ng *AlertNG) init() error {
 	var err error
 
-	store := &store.DBstore{
-		BaseInterval:    ng.Cfg.UnifiedAlerting.BaseInterval,
-		DefaultInterval: ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval,
-		SQLStore:        ng.SQLStore,
-		Logger:          ng.Log,
-		FolderService:   ng.folderService,
-		AccessControl:   ng.accesscontrol,
-	}
+	store := store.NewDBstore(ng.Cfg.UnifiedAlerting, ng.SQLStore, ng.Log, state.ValidateRuleTemplates) // LOGZ.IO GRAFANA CHANGE :: Dynamic labels and annotations from query results
+	store.FolderService = ng.folderService
+	store.AccessControl = ng.accesscontrol
 
 	decryptFn := ng.SecretsService.GetDecryptedValue
 	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
@@ -126,7 +133,28 @@ func (ng *AlertNG) init() error {
 		AdminConfigPollInterval: ng.Cfg.UnifiedAlerting.AdminConfigPollInterval,
 		DisabledOrgs:            ng.Cfg.UnifiedAlerting.DisabledOrgs,
 		MinRuleInterval:         ng.Cfg.UnifiedAlerting.MinInterval,
+		KeepPausedRuleStates:    ng.Cfg.UnifiedAlerting.PausedRuleStatePolicy == setting.PausedRuleStatePolicyKeep, // LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+	}
+	// LOGZ.IO GRAFANA CHANGE :: Recording rules
+	if ng.Cfg.UnifiedAlerting.RecordingRulesRemoteWriteURL != "" {
+		schedCfg.RecordingWriter = writer.NewRemoteWriter(
+			ng.Cfg.UnifiedAlerting.RecordingRulesRemoteWriteURL,
+			ng.Cfg.UnifiedAlerting.RecordingRulesRemoteWriteUser,
+			ng.Cfg.UnifiedAlerting.RecordingRulesRemoteWritePassword,
+			ng.Cfg.UnifiedAlerting.RecordingRulesRemoteWriteTimeout,
+			log.New("ngalert.writer"),
+		)
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Rule evaluation sharding across HA scheduler instances
+	if ng.Cfg.UnifiedAlerting.SchedulerShardingEnabled {
+		if membership, ok := ng.MultiOrgAlertmanager.ClusterMembership(); ok {
+			schedCfg.ShardMembership = membership
+		} else {
+			ng.Log.Warn("scheduler sharding is enabled but high availability is not configured, every alert rule is evaluated by this instance")
+		}
 	}
+	// LOGZ.IO GRAFANA CHANGE :: end
 
 	appUrl, err := url.Parse(ng.Cfg.AppURL)
 	if err != nil {
@@ -134,7 +162,33 @@ func (ng *AlertNG) init() error {
 		appUrl = nil
 	}
 	appUrl = ng.Cfg.ParsedAppURL // LOGZ.IO GRAFANA CHANGE :: DEV-31554 - Set APP url to logzio grafana for alert notification URLs
-	stateManager := state.NewManager(ng.Log, ng.Metrics.GetStateMetrics(), appUrl, store, store, ng.SQLStore)
+	stateOpts := []state.ManagerOption{
+		state.WithStateEviction(ng.Cfg.UnifiedAlerting.StateEvictionDelay, ng.Cfg.UnifiedAlerting.StateEvictionFrequency),    // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+		state.WithInstanceLimit(ng.Cfg.UnifiedAlerting.MaxInstancesPerRule, ng.Cfg.UnifiedAlerting.MaxInstancesPerRuleByOrg), // LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+	}
+	// LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+	if ng.Cfg.UnifiedAlerting.StateHistoryEnabled {
+		stateOpts = append(stateOpts, state.WithStateHistory(ng.stateHistoryStore()))
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+	if ng.Cfg.UnifiedAlerting.ScreenshotsEnabled {
+		images, err := ng.imageService()
+		if err != nil {
+			return err
+		}
+		stateOpts = append(stateOpts, state.WithImageService(images))
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+	stateManager := state.NewManager(ng.Log, ng.Metrics.GetStateMetrics(), appUrl, store, store, ng.SQLStore, stateOpts...) // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+	// LOGZ.IO GRAFANA CHANGE :: Shared alert state cache across HA peers
+	if ng.Cfg.UnifiedAlerting.StateCacheBackend == setting.StateCacheBackendRemoteCache {
+		if ng.RemoteCache == nil {
+			return fmt.Errorf("state cache backend %q requires the remote cache", setting.StateCacheBackendRemoteCache)
+		}
+		stateManager.SetSharedStateStore(state.NewRemoteCacheStateStore(ng.RemoteCache, ng.Cfg.UnifiedAlerting.StateCacheTTL))
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
 	scheduler := schedule.NewScheduler(schedCfg, ng.ExpressionService, appUrl, stateManager)
 
 	ng.stateManager = stateManager
@@ -146,6 +200,8 @@ func (ng *AlertNG) init() error {
 	alertRuleService := provisioning.NewAlertRuleService(store, store, store,
 		int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
 		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()), ng.Log)
+	muteTimingService := provisioning.NewMuteTimingService(store, store, store, ng.Log) // LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+	templateService := provisioning.NewTemplateService(store, store, store, ng.Log)     // LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
 
 	api := api.API{
 		Cfg:                  ng.Cfg,
@@ -168,7 +224,14 @@ func (ng *AlertNG) init() error {
 		Policies:             policyService,
 		ContactPointService:  contactPointService,
 		AlertRules:           alertRuleService,
+		MuteTimings:          muteTimingService, // LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+		Templates:            templateService,   // LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
+	}
+	// LOGZ.IO GRAFANA CHANGE :: Idempotent alert processing
+	if ng.RemoteCache != nil {
+		api.ProcessedRequestsCache = ng.RemoteCache
 	}
+	// LOGZ.IO GRAFANA CHANGE :: end
 	api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
 	return DeclareFixedRoles(ng.accesscontrol)
@@ -193,9 +256,64 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	} else {
 		ng.Log.Debug("Alert manager is disabled")
 	}
+	// LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+	if ng.Cfg.UnifiedAlerting.StateHistoryEnabled && ng.Cfg.UnifiedAlerting.StateHistoryRetention > 0 {
+		children.Go(func() error {
+			return ng.runStateHistoryCleanup(subCtx)
+		})
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
 	return children.Wait()
 }
 
+// LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+const stateHistoryCleanupInterval = time.Hour
+
+func (ng *AlertNG) stateHistoryStore() store.LogzioStateHistoryStore {
+	return store.LogzioStateHistoryStore{SQLStore: ng.SQLStore}
+}
+
+// runStateHistoryCleanup periodically removes the state transitions older than the retention.
+func (ng *AlertNG) runStateHistoryCleanup(ctx context.Context) error {
+	ticker := time.NewTicker(stateHistoryCleanupInterval)
+	defer ticker.Stop()
+	for {
+		deleted, err := ng.stateHistoryStore().DeleteStateHistoryBefore(ctx, time.Now().Add(-ng.Cfg.UnifiedAlerting.StateHistoryRetention))
+		if err != nil {
+			ng.Log.Error("failed to clean up the alert state history", "error", err)
+		} else if deleted > 0 {
+			ng.Log.Debug("cleaned up the alert state history", "deleted", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+// imageService returns the service that takes the screenshots of the panels with the image renderer and uploads them
+// to the configured external image storage.
+func (ng *AlertNG) imageService() (image.ImageService, error) {
+	if ng.RenderService == nil {
+		return nil, fmt.Errorf("screenshots of alert panels require the rendering service")
+	}
+	if !ng.RenderService.IsAvailable() {
+		ng.Log.Warn("screenshots of alert panels are enabled but no image renderer is available")
+	}
+	uploader, err := imguploader.NewImageUploader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the image uploader: %w", err)
+	}
+	return image.NewScreenshotImageService(ng.RenderService, uploader, ng.Cfg.UnifiedAlerting.ScreenshotsTimeout), nil
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 // IsDisabled returns true if the alerting service is disable for this instance.
 func (ng *AlertNG) IsDisabled() bool {
 	if ng.Cfg == nil {
@@ -125,6 +125,8 @@ type Alertmanager struct {
 	orgID           int64
 
 	decryptFn channels.GetDecryptedValueFn
+
+	deliveryLog *deliveryLog // LOGZ.IO GRAFANA CHANGE :: Notification delivery log
 }
 
 func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store store.AlertingStore, kvStore kvstore.KVStore,
@@ -143,6 +145,7 @@ func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store s
 		NotificationService: ns,
 		orgID:               orgID,
 		decryptFn:           decryptFn,
+		deliveryLog:         newDeliveryLog(cfg.UnifiedAlerting.NotificationDeliveryLogSize), // LOGZ.IO GRAFANA CHANGE :: Notification delivery log
 	}
 
 	am.fileStore = NewFileStore(am.orgID, kvStore, am.WorkingDirPath())
@@ -392,6 +395,7 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 	if err != nil {
 		return fmt.Errorf("failed to build integration map: %w", err)
 	}
+	am.deliveryLog.retain(cfg.AlertmanagerConfig.Receivers) // LOGZ.IO GRAFANA CHANGE :: Notification delivery log
 
 	// Now, let's put together our notification pipeline
 	routingStage := make(notify.RoutingStage, len(integrationsMap))
@@ -463,7 +467,10 @@ func (am *Alertmanager) buildReceiverIntegrations(receiver *apimodels.PostableAp
 		if err != nil {
 			return nil, err
 		}
-		integrations = append(integrations, notify.NewIntegration(n, n, r.Type, i))
+		// LOGZ.IO GRAFANA CHANGE :: Notification delivery log
+		recorder := am.deliveryLog.recorder(receiver.Name, r, i, n)
+		integrations = append(integrations, notify.NewIntegration(recorder, recorder, r.Type, i))
+		// LOGZ.IO GRAFANA CHANGE :: end
 	}
 	return integrations, nil
 }
@@ -71,6 +71,11 @@ func (moa *MultiOrgAlertmanager) GetAlertmanagerConfiguration(ctx context.Contex
 				Settings:              pr.Settings,
 				SecureFields:          secureFields,
 			}
+			// LOGZ.IO GRAFANA CHANGE :: Notification delivery log
+			if lastDelivery, ok := moa.LastDelivery(org, pr.UID); ok {
+				gr.LastDelivery = &lastDelivery
+			}
+			// LOGZ.IO GRAFANA CHANGE :: end
 			receivers = append(receivers, &gr)
 		}
 		gettableApiReceiver := definitions.GettableApiReceiver{
@@ -651,6 +651,45 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					InputType:    alerting.InputTypeText,
 					PropertyName: "maxAlerts",
 				},
+				// LOGZ.IO GRAFANA CHANGE :: Signed and templated webhook payloads
+				{
+					Label:        "Body",
+					Description:  "Templated body of the request, instead of the default JSON payload. You can use template variables, e.g. .Alerts or .GroupKey.",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "body",
+				},
+				{
+					Label:        "Headers",
+					Description:  "Custom headers of the request, one 'Name: value' per line. You can use template variables in the values.",
+					Element:      alerting.ElementTypeTextArea,
+					Placeholder:  "X-Team: infra",
+					PropertyName: "headers",
+				},
+				{
+					Label:        "HMAC Secret",
+					Description:  "Signs the body with HMAC-SHA256 when set. The signature is the hex-encoded HMAC of '<timestamp>.<body>'.",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypePassword,
+					PropertyName: "hmacSecret",
+					Secure:       true,
+				},
+				{
+					Label:        "Signature Header",
+					Description:  "Header of the HMAC-SHA256 signature of the body.",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  channels.DefaultWebhookSignatureHeader,
+					PropertyName: "hmacSignatureHeader",
+				},
+				{
+					Label:        "Timestamp Header",
+					Description:  "Header of the Unix timestamp in seconds included in the signature.",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  channels.DefaultWebhookTimestampHeader,
+					PropertyName: "hmacTimestampHeader",
+				},
+				// LOGZ.IO GRAFANA CHANGE :: end
 			},
 		},
 		{
@@ -118,6 +118,11 @@ func (d DiscordNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 	ruleURL := joinUrlPath(basePath, "/alerting/list", d.log)
 	//LOGZ.IO GRAFANA CHANGE :: end
 	embed.Set("url", ToLogzioAppPath(ruleURL)) // LOGZ.IO GRAFANA CHANGE :: DEV-31554 - Set APP url to logzio grafana for alert notification URLs
+	// LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+	if imageURL := firstImageURL(as...); imageURL != "" {
+		embed.Set("image", map[string]interface{}{"url": imageURL})
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
 
 	bodyJSON.Set("embeds", []interface{}{embed})
 
@@ -118,9 +118,10 @@ func (en *EmailNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 				"RuleUrl":           ToLogzioAppPath(ruleURL),          // LOGZ.IO GRAFANA CHANGE :: DEV-31554 - Set APP url to logzio grafana for alert notification URLs
 				"AlertPageUrl":      ToLogzioAppPath(alertPageURL),     // LOGZ.IO GRAFANA CHANGE :: DEV-31554 - Set APP url to logzio grafana for alert notification URLs
 			},
-			To:          en.Addresses,
-			SingleEmail: en.SingleEmail,
-			Template:    "ng_alert_notification",
+			To:            en.Addresses,
+			SingleEmail:   en.SingleEmail,
+			Template:      "ng_alert_notification",
+			EmbeddedFiles: embedImages(data.Alerts), // LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
 		},
 	}
 
@@ -0,0 +1,64 @@
+package channels
+
+// LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// imageURLs returns the distinct URLs of the screenshots of the panels of the alerts. The alerts of a rule share the
+// screenshot of its evaluation.
+func imageURLs(as ...*types.Alert) []string {
+	seen := make(map[string]struct{})
+	var urls []string
+	for _, alert := range as {
+		u := string(alert.Annotations[model.LabelName(ngmodels.LogzioImageURLAnnotation)])
+		if u == "" {
+			continue
+		}
+		if _, ok := seen[u]; ok {
+			continue
+		}
+		seen[u] = struct{}{}
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// firstImageURL returns the URL of the first screenshot of the alerts, for the channels that show a single image.
+func firstImageURL(as ...*types.Alert) string {
+	if urls := imageURLs(as...); len(urls) > 0 {
+		return urls[0]
+	}
+	return ""
+}
+
+// embedImages sets the embedded image of the alerts whose screenshot was not uploaded but is on the local disk, and
+// returns the distinct paths of the files to embed in the email.
+func embedImages(alerts ExtendedAlerts) []string {
+	seen := make(map[string]struct{})
+	var files []string
+	for i := range alerts {
+		alert := &alerts[i]
+		if alert.ImageURL != "" || alert.ImagePath == "" {
+			continue
+		}
+		if _, ok := seen[alert.ImagePath]; !ok {
+			if _, err := os.Stat(alert.ImagePath); err != nil {
+				continue
+			}
+			seen[alert.ImagePath] = struct{}{}
+			files = append(files, alert.ImagePath)
+		}
+		alert.EmbeddedImage = filepath.Base(alert.ImagePath)
+	}
+	return files
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -0,0 +1,46 @@
+package channels
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestImageURLs(t *testing.T) {
+	alertWithImage := func(url string) *types.Alert {
+		a := &types.Alert{}
+		a.Annotations = model.LabelSet{}
+		if url != "" {
+			a.Annotations[ngmodels.LogzioImageURLAnnotation] = model.LabelValue(url)
+		}
+		return a
+	}
+
+	alerts := []*types.Alert{alertWithImage(""), alertWithImage("https://a"), alertWithImage("https://b"), alertWithImage("https://a")}
+	require.Equal(t, []string{"https://a", "https://b"}, imageURLs(alerts...))
+	require.Equal(t, "https://a", firstImageURL(alerts...))
+	require.Empty(t, firstImageURL(alertWithImage("")))
+}
+
+func TestEmbedImages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "panel.png")
+	require.NoError(t, os.WriteFile(path, []byte("png"), 0600))
+
+	alerts := ExtendedAlerts{
+		{ImagePath: path},
+		{ImagePath: path},
+		{ImagePath: path, ImageURL: "https://a"},
+		{ImagePath: filepath.Join(t.TempDir(), "missing.png")},
+	}
+	require.Equal(t, []string{path}, embedImages(alerts))
+	require.Equal(t, "panel.png", alerts[0].EmbeddedImage)
+	require.Equal(t, "panel.png", alerts[1].EmbeddedImage)
+	require.Empty(t, alerts[2].EmbeddedImage)
+	require.Empty(t, alerts[3].EmbeddedImage)
+}
@@ -0,0 +1,145 @@
+package channels
+
+// LOGZ.IO GRAFANA CHANGE :: Signed and templated webhook payloads
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	tmpltext "text/template"
+	"time"
+
+	"github.com/prometheus/alertmanager/template"
+	"golang.org/x/net/http/httpguts"
+)
+
+const (
+	// DefaultWebhookSignatureHeader is the header of the HMAC-SHA256 signature of the webhook body.
+	DefaultWebhookSignatureHeader = "X-Grafana-Alerting-Signature"
+	// DefaultWebhookTimestampHeader is the header of the Unix timestamp in seconds included in the signature.
+	DefaultWebhookTimestampHeader = "X-Grafana-Alerting-Timestamp"
+)
+
+// webhookSigning is the HMAC-SHA256 signing of the webhook body.
+type webhookSigning struct {
+	Secret          string
+	SignatureHeader string
+	TimestampHeader string
+}
+
+// readWebhookLogzioSettings reads the body template, custom headers and signing settings of the webhook.
+func readWebhookLogzioSettings(config *NotificationChannelConfig, decryptFunc GetDecryptedValueFn, cfg *WebhookConfig) error {
+	cfg.Body = config.Settings.Get("body").MustString()
+	if cfg.Body != "" {
+		if _, err := tmpltext.New("body").Funcs(tmpltext.FuncMap(template.DefaultFuncs)).Parse(cfg.Body); err != nil {
+			return fmt.Errorf("invalid body template: %w", err)
+		}
+	}
+
+	headers, err := parseWebhookHeaders(config.Settings.Get("headers").Interface())
+	if err != nil {
+		return err
+	}
+	cfg.Headers = headers
+
+	secret := decryptFunc(context.Background(), config.SecureSettings, "hmacSecret", config.Settings.Get("hmacSecret").MustString())
+	if secret == "" {
+		return nil
+	}
+	cfg.Signing = &webhookSigning{
+		Secret:          secret,
+		SignatureHeader: config.Settings.Get("hmacSignatureHeader").MustString(DefaultWebhookSignatureHeader),
+		TimestampHeader: config.Settings.Get("hmacTimestampHeader").MustString(DefaultWebhookTimestampHeader),
+	}
+	for _, name := range []string{cfg.Signing.SignatureHeader, cfg.Signing.TimestampHeader} {
+		if !httpguts.ValidHeaderFieldName(name) {
+			return fmt.Errorf("invalid signing header name %q", name)
+		}
+		for header := range cfg.Headers {
+			if strings.EqualFold(header, name) {
+				return fmt.Errorf("custom header %q conflicts with the signing headers", header)
+			}
+		}
+	}
+	return nil
+}
+
+// parseWebhookHeaders parses the custom headers of the webhook, either an object of header names to values or a
+// text with a "Name: value" header per line.
+func parseWebhookHeaders(raw interface{}) (map[string]string, error) {
+	headers := make(map[string]string)
+	switch v := raw.(type) {
+	case nil:
+	case map[string]interface{}:
+		for name, value := range v {
+			s, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("value of header %q is not a string", name)
+			}
+			headers[name] = s
+		}
+	case string:
+		for _, line := range strings.Split(v, "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			parts := strings.SplitN(line, ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid header %q, expected Name: value", line)
+			}
+			headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	default:
+		return nil, fmt.Errorf("invalid headers, expected an object or a text with a header per line")
+	}
+
+	for name, value := range headers {
+		if !httpguts.ValidHeaderFieldName(name) {
+			return nil, fmt.Errorf("invalid header name %q", name)
+		}
+		if !httpguts.ValidHeaderFieldValue(value) {
+			return nil, fmt.Errorf("invalid value of header %q", name)
+		}
+	}
+	return headers, nil
+}
+
+// executeBody executes the body template of the webhook with the webhook message as data, so that the template can use
+// the fields of the default payload, e.g. .Alerts or .GroupKey, and the notification templates.
+func (wn *WebhookNotifier) executeBody(msg *webhookMessage) (string, error) {
+	return wn.tmpl.ExecuteTextString(wn.Body, msg)
+}
+
+// headers returns the custom headers of the webhook, with their values templated, and the signing headers when the
+// webhook is signed.
+func (wn *WebhookNotifier) headers(tmpl func(string) string, body string, now time.Time) map[string]string {
+	if len(wn.Headers) == 0 && wn.Signing == nil {
+		return nil
+	}
+	headers := make(map[string]string, len(wn.Headers)+2)
+	for name, value := range wn.Headers {
+		headers[name] = tmpl(value)
+	}
+	if wn.Signing != nil {
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		headers[wn.Signing.TimestampHeader] = timestamp
+		headers[wn.Signing.SignatureHeader] = signWebhookBody(wn.Signing.Secret, timestamp, body)
+	}
+	return headers
+}
+
+// signWebhookBody returns the hex-encoded HMAC-SHA256 of "<timestamp>.<body>" with the secret. The timestamp is signed
+// with the body so that receivers can reject replayed requests.
+func signWebhookBody(secret, timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
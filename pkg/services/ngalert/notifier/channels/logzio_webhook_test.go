@@ -0,0 +1,120 @@
+package channels
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+)
+
+func TestWebhookNotifierTemplatedAndSigned(t *testing.T) {
+	tmpl := templateForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	settings, err := simplejson.NewJson([]byte(`{
+		"url": "http://localhost/test",
+		"body": "{\"group\": \"{{ .GroupKey }}\", \"count\": {{ len .Alerts }}, \"status\": \"{{ .Status }}\"}",
+		"headers": {"Content-Type": "application/vnd.alerts+json", "X-Alert-Status": "{{ .Status }}"}
+	}`))
+	require.NoError(t, err)
+	cfg, err := NewWebHookConfig(&NotificationChannelConfig{
+		OrgID:          1,
+		Name:           "webhook_testing",
+		Type:           "webhook",
+		Settings:       settings,
+		SecureSettings: map[string][]byte{"hmacSecret": []byte("secret")},
+	}, fakes.NewFakeSecretsService().GetDecryptedValue)
+	require.NoError(t, err)
+
+	webhookSender := mockNotificationService()
+	pn := NewWebHookNotifier(cfg, webhookSender, tmpl)
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	before := time.Now().Unix()
+	ok, err := pn.Notify(ctx, &types.Alert{
+		Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}},
+	})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	sent := webhookSender.Webhook
+	require.JSONEq(t, `{"group": "alertname", "count": 1, "status": "firing"}`, sent.Body)
+	require.Equal(t, "application/vnd.alerts+json", sent.HttpHeader["Content-Type"])
+	require.Equal(t, "firing", sent.HttpHeader["X-Alert-Status"])
+
+	timestamp := sent.HttpHeader[DefaultWebhookTimestampHeader]
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, ts, before)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(timestamp + "." + sent.Body))
+	require.Equal(t, hex.EncodeToString(mac.Sum(nil)), sent.HttpHeader[DefaultWebhookSignatureHeader])
+}
+
+func TestNewWebHookConfigLogzioSettings(t *testing.T) {
+	newConfig := func(settings string, secureSettings map[string][]byte) (*WebhookConfig, error) {
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		return NewWebHookConfig(&NotificationChannelConfig{
+			Name:           "webhook_testing",
+			Type:           "webhook",
+			Settings:       settingsJSON,
+			SecureSettings: secureSettings,
+		}, fakes.NewFakeSecretsService().GetDecryptedValue)
+	}
+
+	t.Run("headers can be set as a header per line", func(t *testing.T) {
+		cfg, err := newConfig(`{"url": "http://localhost", "headers": "X-Team: infra\n\nX-Env: prod"}`, nil)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"X-Team": "infra", "X-Env": "prod"}, cfg.Headers)
+		require.Nil(t, cfg.Signing)
+	})
+
+	t.Run("signing headers can be renamed", func(t *testing.T) {
+		cfg, err := newConfig(`{"url": "http://localhost", "hmacSignatureHeader": "X-Signature", "hmacTimestampHeader": "X-Timestamp"}`,
+			map[string][]byte{"hmacSecret": []byte("secret")})
+		require.NoError(t, err)
+		require.Equal(t, &webhookSigning{Secret: "secret", SignatureHeader: "X-Signature", TimestampHeader: "X-Timestamp"}, cfg.Signing)
+	})
+
+	for name, tc := range map[string]struct {
+		settings string
+		expErr   string
+	}{
+		"invalid body template": {
+			settings: `{"url": "http://localhost", "body": "{{ .Alerts"}`,
+			expErr:   "invalid body template: template: body:1: unclosed action",
+		},
+		"invalid header line": {
+			settings: `{"url": "http://localhost", "headers": "X-Team"}`,
+			expErr:   `invalid header "X-Team", expected Name: value`,
+		},
+		"invalid header name": {
+			settings: `{"url": "http://localhost", "headers": {"X Team": "infra"}}`,
+			expErr:   `invalid header name "X Team"`,
+		},
+		"header conflicting with the signature": {
+			settings: `{"url": "http://localhost", "hmacSecret": "secret", "headers": {"x-grafana-alerting-signature": "forged"}}`,
+			expErr:   `custom header "x-grafana-alerting-signature" conflicts with the signing headers`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := newConfig(tc.settings, nil)
+			require.EqualError(t, err, tc.expErr)
+		})
+	}
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
@@ -172,6 +173,7 @@ type attachment struct {
 	FooterIcon string              `json:"footer_icon"`
 	Color      string              `json:"color,omitempty"`
 	Ts         int64               `json:"ts,omitempty"`
+	ImageURL   string              `json:"image_url,omitempty"` // LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
 }
 
 // Notify sends an alert notification to Slack.
@@ -235,6 +237,7 @@ var sendSlackRequest = func(request *http.Request, logger log.Logger) error {
 	if err != nil {
 		return err
 	}
+	notifications.RecordResponseStatus(request.Context(), resp.StatusCode) // LOGZ.IO GRAFANA CHANGE :: Notification delivery log
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
 			logger.Warn("Failed to close response body", "err", err)
@@ -301,6 +304,9 @@ func (sn *SlackNotifier) buildSlackMessage(ctx context.Context, as []*types.Aler
 				TitleLink:  ruleURL,
 				Text:       tmpl(sn.Text),
 				Fields:     nil, // TODO. Should be a config.
+				// LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+				ImageURL: firstImageURL(as...),
+				// LOGZ.IO GRAFANA CHANGE :: end
 			},
 		},
 	}
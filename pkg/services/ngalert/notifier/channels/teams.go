@@ -108,6 +108,19 @@ func (tn *TeamsNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 		},
 	}
 
+	// LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+	if urls := imageURLs(as...); len(urls) > 0 {
+		images := make([]map[string]interface{}, 0, len(urls))
+		for _, u := range urls {
+			images = append(images, map[string]interface{}{"image": u})
+		}
+		body["sections"] = append(body["sections"].([]map[string]interface{}), map[string]interface{}{
+			"title":  "Images",
+			"images": images,
+		})
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
 	if tmplErr != nil {
 		tn.log.Warn("failed to template Teams message", "err", tmplErr.Error())
 		tmplErr = nil
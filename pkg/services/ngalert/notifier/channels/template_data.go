@@ -48,6 +48,14 @@ type ExtendedAlert struct {
 	PanelURL     string      `json:"panelURL"`
 	ValueString  string      `json:"valueString"`
 	EvalValues   []EvalValue `json:"evalValues"` // LOGZ.IO GRAFANA CHANGE :: DEV-37882 - Access evaluation results in grafana alert template
+	// LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+	// ImageURL is the URL of the screenshot of the panel of the rule, when it was uploaded to an external image storage.
+	ImageURL string `json:"imageURL,omitempty"`
+	// ImagePath is the path of the screenshot on the local disk, used to embed it in emails when it was not uploaded.
+	ImagePath string `json:"-"`
+	// EmbeddedImage is the name of the screenshot embedded in the email, set by the email notifier.
+	EmbeddedImage string `json:"-"`
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 // LOGZ.IO GRAFANA CHANGE :: DEV-37882 - Access evaluation results in grafana alert template
@@ -104,6 +112,10 @@ func extendAlert(alert template.Alert, externalURL string, logger log.Logger) *E
 		EndsAt:       alert.EndsAt,
 		GeneratorURL: generatorUrl, //LOGZ.IO GRAFANA CHANGE :: DEV-37746: Add switch to account query param
 		Fingerprint:  alert.Fingerprint,
+		// LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+		ImageURL:  alert.Annotations[ngmodels.LogzioImageURLAnnotation],
+		ImagePath: alert.Annotations[ngmodels.LogzioImagePathAnnotation],
+		// LOGZ.IO GRAFANA CHANGE :: end
 	}
 
 	// fill in some grafana-specific urls
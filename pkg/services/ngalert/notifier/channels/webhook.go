@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"time"
+
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/notifications"
@@ -26,6 +29,11 @@ type WebhookNotifier struct {
 	ns         notifications.WebhookSender
 	tmpl       *template.Template
 	orgID      int64
+	// LOGZ.IO GRAFANA CHANGE :: Signed and templated webhook payloads
+	Body    string
+	Headers map[string]string
+	Signing *webhookSigning
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 type WebhookConfig struct {
@@ -35,6 +43,14 @@ type WebhookConfig struct {
 	Password   string
 	HTTPMethod string
 	MaxAlerts  int
+	// LOGZ.IO GRAFANA CHANGE :: Signed and templated webhook payloads
+	// Body is the template of the body, the default JSON payload when empty.
+	Body string
+	// Headers are the custom headers of the requests, whose values are templated.
+	Headers map[string]string
+	// Signing signs the body with HMAC-SHA256 when set.
+	Signing *webhookSigning
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 func WebHookFactory(fc FactoryConfig) (NotificationChannel, error) {
@@ -53,14 +69,20 @@ func NewWebHookConfig(config *NotificationChannelConfig, decryptFunc GetDecrypte
 	if url == "" {
 		return nil, errors.New("could not find url property in settings")
 	}
-	return &WebhookConfig{
+	cfg := &WebhookConfig{
 		NotificationChannelConfig: config,
 		URL:                       url,
 		User:                      config.Settings.Get("username").MustString(),
 		Password:                  decryptFunc(context.Background(), config.SecureSettings, "password", config.Settings.Get("password").MustString()),
 		HTTPMethod:                config.Settings.Get("httpMethod").MustString("POST"),
 		MaxAlerts:                 config.Settings.Get("maxAlerts").MustInt(0),
-	}, nil
+	}
+	// LOGZ.IO GRAFANA CHANGE :: Signed and templated webhook payloads
+	if err := readWebhookLogzioSettings(config, decryptFunc, cfg); err != nil {
+		return nil, err
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+	return cfg, nil
 }
 
 // NewWebHookNotifier is the constructor for
@@ -83,6 +105,11 @@ func NewWebHookNotifier(config *WebhookConfig, ns notifications.WebhookSender, t
 		log:        log.New("alerting.notifier.webhook"),
 		ns:         ns,
 		tmpl:       t,
+		// LOGZ.IO GRAFANA CHANGE :: Signed and templated webhook payloads
+		Body:    config.Body,
+		Headers: config.Headers,
+		Signing: config.Signing,
+		// LOGZ.IO GRAFANA CHANGE :: end
 	}
 }
 
@@ -130,19 +157,36 @@ func (wn *WebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool
 
 	if tmplErr != nil {
 		wn.log.Warn("failed to template webhook message", "err", tmplErr.Error())
+		tmplErr = nil // LOGZ.IO GRAFANA CHANGE :: Signed and templated webhook payloads
 	}
 
-	body, err := json.Marshal(msg)
-	if err != nil {
-		return false, err
+	// LOGZ.IO GRAFANA CHANGE :: Signed and templated webhook payloads
+	var body string
+	if wn.Body != "" {
+		if body, err = wn.executeBody(msg); err != nil {
+			return false, fmt.Errorf("failed to template webhook body: %w", err)
+		}
+	} else {
+		b, err := json.Marshal(msg)
+		if err != nil {
+			return false, err
+		}
+		body = string(b)
+	}
+
+	headers := wn.headers(tmpl, body, time.Now())
+	if tmplErr != nil {
+		wn.log.Warn("failed to template webhook headers", "err", tmplErr.Error())
 	}
+	// LOGZ.IO GRAFANA CHANGE :: end
 
 	cmd := &models.SendWebhookSync{
 		Url:        wn.URL,
 		User:       wn.User,
 		Password:   wn.Password,
-		Body:       string(body),
+		Body:       body,
 		HttpMethod: wn.HTTPMethod,
+		HttpHeader: headers, // LOGZ.IO GRAFANA CHANGE :: Signed and templated webhook payloads
 	}
 
 	if err := wn.ns.SendWebhookSync(ctx, cmd); err != nil {
@@ -0,0 +1,45 @@
+package notifier
+
+// LOGZ.IO GRAFANA CHANGE :: Rule evaluation sharding across HA scheduler instances
+
+import (
+	"github.com/prometheus/alertmanager/cluster"
+)
+
+// ClusterMembership is the membership of the live peers of the Alertmanager high availability cluster.
+type ClusterMembership struct {
+	peer *cluster.Peer
+}
+
+// Self returns the name of this peer.
+func (m *ClusterMembership) Self() string {
+	return m.peer.Name()
+}
+
+// Members returns the names of the live peers, including this one.
+func (m *ClusterMembership) Members() []string {
+	self := m.peer.Name()
+	peers := m.peer.Peers()
+	members := make([]string, 0, len(peers)+1)
+	found := false
+	for _, p := range peers {
+		members = append(members, p.Name())
+		found = found || p.Name() == self
+	}
+	if !found {
+		members = append(members, self)
+	}
+	return members
+}
+
+// ClusterMembership returns the membership of the high availability cluster, or false if high availability is not
+// configured.
+func (moa *MultiOrgAlertmanager) ClusterMembership() (*ClusterMembership, bool) {
+	p, ok := moa.peer.(*cluster.Peer)
+	if !ok {
+		return nil, false
+	}
+	return &ClusterMembership{peer: p}, true
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
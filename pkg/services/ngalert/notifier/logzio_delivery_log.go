@@ -0,0 +1,242 @@
+package notifier
+
+// LOGZ.IO GRAFANA CHANGE :: Notification delivery log
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+const (
+	// failedGroupsLimit is the maximum number of alert groups whose consecutive failed attempts a recorder counts.
+	failedGroupsLimit = 1000
+	// failedGroupRetention is how long the failed attempts of an alert group are counted after the latest one.
+	failedGroupRetention = 24 * time.Hour
+)
+
+// DeliveryLogQuery filters the entries of the notification delivery log. Empty fields match every entry.
+type DeliveryLogQuery struct {
+	Receiver       string
+	IntegrationUID string
+	// Failed only matches the failed attempts when true.
+	Failed bool
+	// Limit is the maximum number of entries returned, zero is unlimited.
+	Limit int
+}
+
+// deliveryLog keeps the latest notification attempts of the integrations of an Alertmanager, up to its size.
+type deliveryLog struct {
+	mtx     sync.RWMutex
+	entries []apimodels.NotificationDelivery
+	next    int
+	full    bool
+	// last is the latest entry of every integration by UID, kept even when the entry left the log.
+	last map[string]apimodels.NotificationDelivery
+}
+
+func newDeliveryLog(size int) *deliveryLog {
+	return &deliveryLog{
+		entries: make([]apimodels.NotificationDelivery, size),
+		last:    make(map[string]apimodels.NotificationDelivery),
+	}
+}
+
+func (l *deliveryLog) add(entry apimodels.NotificationDelivery) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if len(l.entries) == 0 {
+		return
+	}
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+	if entry.IntegrationUID != "" {
+		l.last[entry.IntegrationUID] = entry
+	}
+}
+
+// retain forgets the latest entries of the integrations that are not in the receivers.
+func (l *deliveryLog) retain(receivers []*apimodels.PostableApiReceiver) {
+	uids := make(map[string]struct{})
+	for _, receiver := range receivers {
+		for _, integration := range receiver.GrafanaManagedReceivers {
+			uids[integration.UID] = struct{}{}
+		}
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for uid := range l.last {
+		if _, ok := uids[uid]; !ok {
+			delete(l.last, uid)
+		}
+	}
+}
+
+// query returns the entries that match the query, latest first.
+func (l *deliveryLog) query(q DeliveryLogQuery) []apimodels.NotificationDelivery {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+
+	count := l.next
+	if l.full {
+		count = len(l.entries)
+	}
+	result := make([]apimodels.NotificationDelivery, 0)
+	for i := 1; i <= count; i++ {
+		entry := l.entries[(l.next-i+len(l.entries))%len(l.entries)]
+		if q.Receiver != "" && entry.Receiver != q.Receiver {
+			continue
+		}
+		if q.IntegrationUID != "" && entry.IntegrationUID != q.IntegrationUID {
+			continue
+		}
+		if q.Failed && entry.Error == "" {
+			continue
+		}
+		result = append(result, entry)
+		if q.Limit > 0 && len(result) == q.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// lastDelivery returns the latest entry of the integration with the given UID.
+func (l *deliveryLog) lastDelivery(integrationUID string) (apimodels.NotificationDelivery, bool) {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+	entry, ok := l.last[integrationUID]
+	return entry, ok
+}
+
+// deliveryRecorder is a notifier that records every attempt of the notifier it wraps in the delivery log.
+type deliveryRecorder struct {
+	channels.NotificationChannel
+	log         *deliveryLog
+	receiver    string
+	integration *apimodels.PostableGrafanaReceiver
+	index       int
+	now         func() time.Time
+
+	mtx sync.Mutex
+	// failures are the consecutive failed attempts by alert group key, up to failedGroupsLimit groups.
+	failures map[string]groupFailures
+}
+
+type groupFailures struct {
+	count int
+	last  time.Time
+}
+
+func (l *deliveryLog) recorder(receiver string, integration *apimodels.PostableGrafanaReceiver, index int, n channels.NotificationChannel) *deliveryRecorder {
+	return &deliveryRecorder{
+		NotificationChannel: n,
+		log:                 l,
+		receiver:            receiver,
+		integration:         integration,
+		index:               index,
+		now:                 time.Now,
+		failures:            make(map[string]groupFailures),
+	}
+}
+
+func (r *deliveryRecorder) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	var statusCode int
+	start := r.now()
+	retry, err := r.NotificationChannel.Notify(notifications.WithResponseStatusRecorder(ctx, &statusCode), alerts...)
+
+	entry := apimodels.NotificationDelivery{
+		Time:             start,
+		Receiver:         r.receiver,
+		Integration:      r.integration.Type,
+		IntegrationUID:   r.integration.UID,
+		IntegrationIndex: r.index,
+		Fingerprints:     make([]string, 0, len(alerts)),
+		StatusCode:       statusCode,
+		Duration:         model.Duration(r.now().Sub(start)),
+		Retry:            r.nextRetry(ctx, err == nil),
+	}
+	for _, alert := range alerts {
+		entry.Fingerprints = append(entry.Fingerprints, alert.Fingerprint().String())
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	r.log.add(entry)
+
+	return retry, err
+}
+
+// nextRetry returns the number of failed attempts to deliver the alert group of the context before the current one.
+func (r *deliveryRecorder) nextRetry(ctx context.Context, succeeded bool) int {
+	key, _ := notify.GroupKey(ctx)
+
+	now := r.now()
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	failures := r.failures[key]
+	if failures.count > 0 && now.Sub(failures.last) > failedGroupRetention {
+		failures.count = 0
+	}
+	retry := failures.count
+	if succeeded {
+		delete(r.failures, key)
+		return retry
+	}
+	if _, ok := r.failures[key]; !ok && len(r.failures) >= failedGroupsLimit {
+		r.pruneFailures(now)
+	}
+	r.failures[key] = groupFailures{count: retry + 1, last: now}
+	return retry
+}
+
+// pruneFailures forgets the alert groups whose latest failed attempt is older than failedGroupRetention, or the
+// group with the oldest one when there are none, so that the recorder does not count more than failedGroupsLimit groups.
+func (r *deliveryRecorder) pruneFailures(now time.Time) {
+	oldestKey, oldest := "", now
+	for key, failures := range r.failures {
+		if now.Sub(failures.last) > failedGroupRetention {
+			delete(r.failures, key)
+			continue
+		}
+		if !failures.last.After(oldest) {
+			oldestKey, oldest = key, failures.last
+		}
+	}
+	if len(r.failures) >= failedGroupsLimit {
+		delete(r.failures, oldestKey)
+	}
+}
+
+// DeliveryLog returns the notification attempts of the integrations of the Alertmanager that match the query, latest first.
+func (am *Alertmanager) DeliveryLog(q DeliveryLogQuery) []apimodels.NotificationDelivery {
+	return am.deliveryLog.query(q)
+}
+
+// LastDelivery returns the latest notification attempt of the integration with the given UID.
+func (am *Alertmanager) LastDelivery(integrationUID string) (apimodels.NotificationDelivery, bool) {
+	return am.deliveryLog.lastDelivery(integrationUID)
+}
+
+// LastDelivery returns the latest notification attempt of the integration with the given UID of the org.
+func (moa *MultiOrgAlertmanager) LastDelivery(orgID int64, integrationUID string) (apimodels.NotificationDelivery, bool) {
+	am, err := moa.AlertmanagerFor(orgID)
+	if err != nil {
+		return apimodels.NotificationDelivery{}, false
+	}
+	return am.LastDelivery(integrationUID)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
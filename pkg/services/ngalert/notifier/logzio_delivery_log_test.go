@@ -0,0 +1,132 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+type fakeDeliveryChannel struct {
+	statusCode int
+	err        error
+}
+
+func (f *fakeDeliveryChannel) Notify(ctx context.Context, _ ...*types.Alert) (bool, error) {
+	notifications.RecordResponseStatus(ctx, f.statusCode)
+	return f.err != nil, f.err
+}
+
+func (f *fakeDeliveryChannel) SendResolved() bool {
+	return true
+}
+
+func TestDeliveryLog(t *testing.T) {
+	l := newDeliveryLog(3)
+	for i, receiver := range []string{"a", "b", "a", "b"} {
+		l.add(apimodels.NotificationDelivery{Receiver: receiver, IntegrationUID: receiver + "-uid", Retry: i})
+	}
+
+	all := l.query(DeliveryLogQuery{})
+	require.Len(t, all, 3)
+	require.Equal(t, []int{3, 2, 1}, []int{all[0].Retry, all[1].Retry, all[2].Retry})
+
+	onlyA := l.query(DeliveryLogQuery{Receiver: "a"})
+	require.Len(t, onlyA, 1)
+	require.Equal(t, 2, onlyA[0].Retry)
+
+	require.Len(t, l.query(DeliveryLogQuery{Limit: 2}), 2)
+
+	last, ok := l.lastDelivery("b-uid")
+	require.True(t, ok)
+	require.Equal(t, 3, last.Retry)
+	_, ok = l.lastDelivery("c-uid")
+	require.False(t, ok)
+}
+
+func TestDeliveryRecorder(t *testing.T) {
+	l := newDeliveryLog(10)
+	channel := &fakeDeliveryChannel{statusCode: 500, err: errors.New("webhook response status 500")}
+	r := l.recorder("team", &apimodels.PostableGrafanaReceiver{UID: "uid", Type: "webhook"}, 1, channel)
+	ctx := notify.WithGroupKey(context.Background(), "group")
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test"}}}
+
+	_, err := r.Notify(ctx, alert)
+	require.Error(t, err)
+	_, err = r.Notify(ctx, alert)
+	require.Error(t, err)
+	channel.statusCode, channel.err = 200, nil
+	_, err = r.Notify(ctx, alert)
+	require.NoError(t, err)
+
+	entries := l.query(DeliveryLogQuery{})
+	require.Len(t, entries, 3)
+	require.Equal(t, "team", entries[0].Receiver)
+	require.Equal(t, "webhook", entries[0].Integration)
+	require.Equal(t, "uid", entries[0].IntegrationUID)
+	require.Equal(t, 1, entries[0].IntegrationIndex)
+	require.Equal(t, []string{alert.Fingerprint().String()}, entries[0].Fingerprints)
+	require.Equal(t, 200, entries[0].StatusCode)
+	require.Empty(t, entries[0].Error)
+	require.Equal(t, 2, entries[0].Retry)
+	require.Equal(t, 500, entries[1].StatusCode)
+	require.Equal(t, "webhook response status 500", entries[1].Error)
+	require.Equal(t, 1, entries[1].Retry)
+	require.Equal(t, 0, entries[2].Retry)
+
+	require.Len(t, l.query(DeliveryLogQuery{Failed: true}), 2)
+}
+
+func TestDeliveryLogRetain(t *testing.T) {
+	l := newDeliveryLog(10)
+	l.add(apimodels.NotificationDelivery{Receiver: "a", IntegrationUID: "a-uid"})
+	l.add(apimodels.NotificationDelivery{Receiver: "b", IntegrationUID: "b-uid"})
+
+	l.retain([]*apimodels.PostableApiReceiver{{
+		PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+			GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{{UID: "a-uid"}},
+		},
+	}})
+
+	_, ok := l.lastDelivery("a-uid")
+	require.True(t, ok)
+	_, ok = l.lastDelivery("b-uid")
+	require.False(t, ok)
+	require.Len(t, l.query(DeliveryLogQuery{}), 2)
+}
+
+func TestDeliveryRecorderFailures(t *testing.T) {
+	now := time.Unix(1000, 0)
+	channel := &fakeDeliveryChannel{statusCode: 500, err: errors.New("webhook response status 500")}
+	r := newDeliveryLog(10).recorder("team", &apimodels.PostableGrafanaReceiver{UID: "uid", Type: "webhook"}, 0, channel)
+	r.now = func() time.Time { return now }
+	fail := func(group string) int {
+		return r.nextRetry(notify.WithGroupKey(context.Background(), group), false)
+	}
+
+	t.Run("the failures of a group are forgotten after the retention", func(t *testing.T) {
+		require.Equal(t, 0, fail("group"))
+		require.Equal(t, 1, fail("group"))
+		now = now.Add(failedGroupRetention + time.Second)
+		require.Equal(t, 0, fail("group"))
+	})
+
+	t.Run("the number of groups is bounded", func(t *testing.T) {
+		for i := 0; i < failedGroupsLimit+10; i++ {
+			now = now.Add(time.Second)
+			fail(fmt.Sprintf("group-%d", i))
+		}
+		require.Len(t, r.failures, failedGroupsLimit)
+		require.NotContains(t, r.failures, "group-0")
+		require.Contains(t, r.failures, fmt.Sprintf("group-%d", failedGroupsLimit+9))
+	})
+}
@@ -0,0 +1,342 @@
+package promrules
+
+// LOGZ.IO GRAFANA CHANGE :: Prometheus rule-file export and import of alert rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// RuleFile is a Prometheus rule file.
+type RuleFile struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+// RuleGroup is a group of rules of a Prometheus rule file.
+type RuleGroup struct {
+	Name     string         `yaml:"name"`
+	Interval model.Duration `yaml:"interval,omitempty"`
+	Rules    []Rule         `yaml:"rules"`
+}
+
+// Rule is a rule of a Prometheus rule file. Rules that are backed by a single PromQL query are exported as native
+// Prometheus alerting rules, with the query in Expr. Every other rule carries its Grafana definition in the
+// grafana_alert extension block, and keeps its title, labels, annotations and pending period in the native fields.
+// Recording rules have a Record, which is the name of their metric. Native recording rules are named by their metric,
+// while the recording rules with a grafana_alert extension block keep their title in Alert.
+type Rule struct {
+	Record        string            `yaml:"record,omitempty"`
+	Alert         string            `yaml:"alert,omitempty"`
+	Expr          string            `yaml:"expr,omitempty"`
+	For           model.Duration    `yaml:"for,omitempty"`
+	KeepFiringFor model.Duration    `yaml:"keep_firing_for,omitempty"` // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+	Labels        map[string]string `yaml:"labels,omitempty"`
+	Annotations   map[string]string `yaml:"annotations,omitempty"`
+	GrafanaAlert  *GrafanaAlert     `yaml:"grafana_alert,omitempty"`
+}
+
+// GrafanaAlert is the extension block of the rules that cannot be expressed as a single PromQL query.
+//
+//	grafana_alert:
+//	  uid: <string>
+//	  condition: <refId of the query or expression that is the condition>
+//	  no_data_state: <NoData | Alerting | OK>
+//	  exec_err_state: <Alerting | Error>
+//	  dashboard_uid: <string>
+//	  panel_id: <int>
+//	  record_from: <refId of the query or expression recorded by a recording rule, defaults to the condition>
+//	  query_offset: <duration the queries are shifted back by>
+//	  data:
+//	    - refId: <string>
+//	      queryType: <string>
+//	      relativeTimeRange: {from: <duration>, to: <duration>}
+//	      datasourceUid: <string>
+//	      model: <the query or expression, as in the rule API>
+type GrafanaAlert struct {
+	UID          string         `yaml:"uid,omitempty"`
+	Condition    string         `yaml:"condition"`
+	NoDataState  string         `yaml:"no_data_state,omitempty"`
+	ExecErrState string         `yaml:"exec_err_state,omitempty"`
+	DashboardUID string         `yaml:"dashboard_uid,omitempty"`
+	PanelID      int64          `yaml:"panel_id,omitempty"`
+	RecordFrom   string         `yaml:"record_from,omitempty"`  // LOGZ.IO GRAFANA CHANGE :: Recording rules
+	QueryOffset  model.Duration `yaml:"query_offset,omitempty"` // LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+	Data         []Query        `yaml:"data"`
+}
+
+// Query is a query or expression of a rule in the grafana_alert extension block.
+type Query struct {
+	RefID             string                 `yaml:"refId"`
+	QueryType         string                 `yaml:"queryType,omitempty"`
+	RelativeTimeRange RelativeTimeRange      `yaml:"relativeTimeRange"`
+	DatasourceUID     string                 `yaml:"datasourceUid"`
+	Model             map[string]interface{} `yaml:"model"`
+}
+
+// RelativeTimeRange is the time range of a query, relative to the evaluation time.
+type RelativeTimeRange struct {
+	From model.Duration `yaml:"from"`
+	To   model.Duration `yaml:"to"`
+}
+
+// nativeQueryRange is the time range of the query of an imported native rule.
+const nativeQueryRange = 10 * time.Minute
+
+// Parse reads a Prometheus rule file.
+func Parse(data []byte) (RuleFile, error) {
+	var file RuleFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return RuleFile{}, fmt.Errorf("invalid rule file: %w", err)
+	}
+	return file, nil
+}
+
+// Marshal writes a Prometheus rule file.
+func Marshal(file RuleFile) ([]byte, error) {
+	return yaml.Marshal(file)
+}
+
+// FromAlertRules converts rules to a rule file with a group per rule group. Groups and rules keep the order of rules.
+func FromAlertRules(rules []*ngmodels.AlertRule) (RuleFile, error) {
+	var file RuleFile
+	groups := map[string]int{}
+	for _, r := range rules {
+		idx, ok := groups[r.RuleGroup]
+		if !ok {
+			idx = len(file.Groups)
+			groups[r.RuleGroup] = idx
+			file.Groups = append(file.Groups, RuleGroup{
+				Name:     r.RuleGroup,
+				Interval: model.Duration(time.Duration(r.IntervalSeconds) * time.Second),
+			})
+		}
+		rule, err := fromAlertRule(r)
+		if err != nil {
+			return RuleFile{}, fmt.Errorf("failed to export rule %q: %w", r.UID, err)
+		}
+		file.Groups[idx].Rules = append(file.Groups[idx].Rules, rule)
+	}
+	return file, nil
+}
+
+func fromAlertRule(r *ngmodels.AlertRule) (Rule, error) {
+	rule := Rule{
+		Alert:         r.Title,
+		For:           model.Duration(r.For),
+		KeepFiringFor: model.Duration(r.KeepFiringFor), // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+		Labels:        r.Labels,
+		Annotations:   r.Annotations,
+	}
+	// LOGZ.IO GRAFANA CHANGE :: Recording rules
+	if r.IsRecordingRule() {
+		rule.Record = r.Record.Metric
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+	if expr, ok := promQLExpr(r); ok {
+		rule.Expr = expr
+		// LOGZ.IO GRAFANA CHANGE :: Recording rules
+		if r.IsRecordingRule() {
+			rule.Alert = ""
+		}
+		// LOGZ.IO GRAFANA CHANGE :: end
+		return rule, nil
+	}
+
+	alert := &GrafanaAlert{
+		UID:          r.UID,
+		Condition:    r.Condition,
+		NoDataState:  string(r.NoDataState),
+		ExecErrState: string(r.ExecErrState),
+		QueryOffset:  model.Duration(r.QueryOffset), // LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+	}
+	if r.DashboardUID != nil {
+		alert.DashboardUID = *r.DashboardUID
+	}
+	if r.PanelID != nil {
+		alert.PanelID = *r.PanelID
+	}
+	// LOGZ.IO GRAFANA CHANGE :: Recording rules
+	if r.IsRecordingRule() && r.Record.From != r.Condition {
+		alert.RecordFrom = r.Record.From
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+	for _, q := range r.Data {
+		var queryModel map[string]interface{}
+		if err := json.Unmarshal(q.Model, &queryModel); err != nil {
+			return Rule{}, fmt.Errorf("invalid model of query %q: %w", q.RefID, err)
+		}
+		alert.Data = append(alert.Data, Query{
+			RefID:     q.RefID,
+			QueryType: q.QueryType,
+			RelativeTimeRange: RelativeTimeRange{
+				From: model.Duration(q.RelativeTimeRange.From),
+				To:   model.Duration(q.RelativeTimeRange.To),
+			},
+			DatasourceUID: q.DatasourceUID,
+			Model:         queryModel,
+		})
+	}
+	rule.GrafanaAlert = alert
+	return rule, nil
+}
+
+// promQLExpr returns the PromQL expression of a rule whose condition is its only query, and that query is a
+// PromQL query. Recording rules must also be named by their metric and record that query. Rules with a query offset
+// are not native rules, as the offset of a native rule is set on its whole group.
+func promQLExpr(r *ngmodels.AlertRule) (string, bool) {
+	if len(r.Data) != 1 || r.Data[0].RefID != r.Condition {
+		return "", false
+	}
+	// LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+	if r.QueryOffset != 0 {
+		return "", false
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Recording rules
+	if r.IsRecordingRule() && (r.Record.Metric != r.Title || r.Record.From != r.Condition) {
+		return "", false
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+	q := r.Data[0]
+	if isExpr, err := q.IsExpression(); err != nil || isExpr {
+		return "", false
+	}
+	var queryModel struct {
+		Expr string `json:"expr"`
+	}
+	if err := json.Unmarshal(q.Model, &queryModel); err != nil || queryModel.Expr == "" {
+		return "", false
+	}
+	return queryModel.Expr, true
+}
+
+// toAlertRule converts a rule of a rule file to an alert rule. Native rules query the data source datasourceUID.
+// The caller sets the org, folder, group and interval of the rule.
+func toAlertRule(rule Rule, datasourceUID string) (ngmodels.AlertRule, error) {
+	// LOGZ.IO GRAFANA CHANGE :: Recording rules
+	if rule.Alert == "" && rule.Record == "" {
+		return ngmodels.AlertRule{}, errors.New("either alert or record is required")
+	}
+	title := rule.Alert
+	if title == "" {
+		title = rule.Record
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	r := ngmodels.AlertRule{
+		Title:         title, // LOGZ.IO GRAFANA CHANGE :: Recording rules
+		For:           time.Duration(rule.For),
+		KeepFiringFor: time.Duration(rule.KeepFiringFor), // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+		Labels:        rule.Labels,
+		Annotations:   rule.Annotations,
+		NoDataState:   ngmodels.NoData,
+		ExecErrState:  ngmodels.AlertingErrState,
+	}
+
+	if rule.GrafanaAlert == nil {
+		if rule.Expr == "" {
+			return ngmodels.AlertRule{}, errors.New("either expr or grafana_alert is required")
+		}
+		if datasourceUID == "" {
+			return ngmodels.AlertRule{}, errors.New("a data source is required to import rules with an expr")
+		}
+		queryModel, err := json.Marshal(map[string]interface{}{
+			"refId":   "A",
+			"expr":    rule.Expr,
+			"instant": true,
+			"range":   false,
+		})
+		if err != nil {
+			return ngmodels.AlertRule{}, err
+		}
+		r.Condition = "A"
+		r.Data = []ngmodels.AlertQuery{{
+			RefID:             "A",
+			DatasourceUID:     datasourceUID,
+			RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(nativeQueryRange)},
+			Model:             queryModel,
+		}}
+		// LOGZ.IO GRAFANA CHANGE :: Recording rules
+		if rule.Record != "" {
+			r.Record = &ngmodels.Record{Metric: rule.Record, From: r.Condition}
+			if err := r.Record.Validate(r.Data); err != nil {
+				return ngmodels.AlertRule{}, err
+			}
+		}
+		// LOGZ.IO GRAFANA CHANGE :: end
+		return r, nil
+	}
+
+	if rule.Expr != "" {
+		return ngmodels.AlertRule{}, errors.New("expr and grafana_alert are mutually exclusive")
+	}
+	alert := rule.GrafanaAlert
+	r.UID = alert.UID
+	r.Condition = alert.Condition
+	r.QueryOffset = time.Duration(alert.QueryOffset) // LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+	if alert.DashboardUID != "" {
+		panelID := alert.PanelID
+		r.DashboardUID = &alert.DashboardUID
+		r.PanelID = &panelID
+	}
+	var err error
+	if alert.NoDataState != "" {
+		if r.NoDataState, err = ngmodels.NoDataStateFromString(alert.NoDataState); err != nil {
+			return ngmodels.AlertRule{}, err
+		}
+	}
+	if alert.ExecErrState != "" {
+		if r.ExecErrState, err = ngmodels.ErrStateFromString(alert.ExecErrState); err != nil {
+			return ngmodels.AlertRule{}, err
+		}
+	}
+
+	if len(alert.Data) == 0 {
+		return ngmodels.AlertRule{}, errors.New("grafana_alert.data is required")
+	}
+	hasCondition := false
+	for _, q := range alert.Data {
+		if q.RefID == "" {
+			return ngmodels.AlertRule{}, errors.New("refId of every query is required")
+		}
+		queryModel, err := json.Marshal(q.Model)
+		if err != nil {
+			return ngmodels.AlertRule{}, fmt.Errorf("invalid model of query %q: %w", q.RefID, err)
+		}
+		r.Data = append(r.Data, ngmodels.AlertQuery{
+			RefID:     q.RefID,
+			QueryType: q.QueryType,
+			RelativeTimeRange: ngmodels.RelativeTimeRange{
+				From: ngmodels.Duration(q.RelativeTimeRange.From),
+				To:   ngmodels.Duration(q.RelativeTimeRange.To),
+			},
+			DatasourceUID: q.DatasourceUID,
+			Model:         queryModel,
+		})
+		hasCondition = hasCondition || q.RefID == alert.Condition
+	}
+	if !hasCondition {
+		return ngmodels.AlertRule{}, fmt.Errorf("condition %q is not the refId of any query", alert.Condition)
+	}
+	// LOGZ.IO GRAFANA CHANGE :: Recording rules
+	if rule.Record != "" {
+		r.Record = &ngmodels.Record{Metric: rule.Record, From: alert.RecordFrom}
+		if r.Record.From == "" {
+			r.Record.From = alert.Condition
+		}
+		if err := r.Record.Validate(r.Data); err != nil {
+			return ngmodels.AlertRule{}, err
+		}
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+	return r, nil
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
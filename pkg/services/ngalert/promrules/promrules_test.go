@@ -0,0 +1,316 @@
+package promrules
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func TestExport(t *testing.T) {
+	native := promQLRule("native", "group-1")
+	mathRule := ngmodels.AlertRuleGen(withGroup("group-2"))()
+	mathRule.Data = append(mathRule.Data, ngmodels.AlertQuery{
+		RefID:         "B",
+		DatasourceUID: "-100",
+		Model:         json.RawMessage(`{"type":"math","expression":"$A > 1"}`),
+	})
+	mathRule.Condition = "B"
+
+	file, err := FromAlertRules([]*ngmodels.AlertRule{native, mathRule})
+	require.NoError(t, err)
+	require.Len(t, file.Groups, 2)
+
+	require.Equal(t, "group-1", file.Groups[0].Name)
+	exported := file.Groups[0].Rules[0]
+	require.Equal(t, "up == 0", exported.Expr)
+	require.Equal(t, native.Title, exported.Alert)
+	require.Equal(t, native.Labels, exported.Labels)
+	require.Nil(t, exported.GrafanaAlert)
+
+	exported = file.Groups[1].Rules[0]
+	require.Empty(t, exported.Expr)
+	require.NotNil(t, exported.GrafanaAlert)
+	require.Equal(t, mathRule.UID, exported.GrafanaAlert.UID)
+	require.Len(t, exported.GrafanaAlert.Data, 2)
+
+	t.Run("exported rules survive a round trip", func(t *testing.T) {
+		data, err := Marshal(file)
+		require.NoError(t, err)
+		parsed, err := Parse(data)
+		require.NoError(t, err)
+
+		imported, err := toAlertRule(parsed.Groups[1].Rules[0], "")
+		require.NoError(t, err)
+		require.Equal(t, mathRule.UID, imported.UID)
+		require.Equal(t, mathRule.Condition, imported.Condition)
+		require.Equal(t, mathRule.For, imported.For)
+		require.Equal(t, mathRule.NoDataState, imported.NoDataState)
+		require.Equal(t, mathRule.ExecErrState, imported.ExecErrState)
+		require.Equal(t, mathRule.DashboardUID, imported.DashboardUID)
+		for i, q := range imported.Data {
+			require.Equal(t, mathRule.Data[i].RefID, q.RefID)
+			require.Equal(t, mathRule.Data[i].DatasourceUID, q.DatasourceUID)
+			require.Equal(t, mathRule.Data[i].RelativeTimeRange, q.RelativeTimeRange)
+			require.JSONEq(t, string(mathRule.Data[i].Model), string(q.Model))
+		}
+
+		imported, err = toAlertRule(parsed.Groups[0].Rules[0], "prometheus")
+		require.NoError(t, err)
+		expr, ok := promQLExpr(&imported)
+		require.True(t, ok)
+		require.Equal(t, "up == 0", expr)
+		require.Equal(t, "prometheus", imported.Data[0].DatasourceUID)
+	})
+}
+
+func TestImport(t *testing.T) {
+	t.Run("reports the errors of every invalid rule and imports nothing", func(t *testing.T) {
+		ruleStore := store.NewFakeRuleStore(t)
+		sut := createServiceSut(ruleStore)
+
+		file, err := Parse([]byte(`
+groups:
+  - name: group
+    interval: 1m
+    rules:
+      - record: job up sum
+        expr: sum by (job) (up)
+      - alert: NoExpr
+      - alert: BadCondition
+        grafana_alert:
+          condition: C
+          data:
+            - refId: A
+              datasourceUid: prometheus
+              model: {expr: up}
+      - alert: Valid
+        expr: up == 0
+  - name: bad-interval
+    interval: 15s
+    rules:
+      - alert: Valid
+        expr: up == 0
+`))
+		require.NoError(t, err)
+
+		result, err := sut.Import(context.Background(), 1, "folder", file, ImportOptions{DatasourceUID: "prometheus"})
+		require.ErrorIs(t, err, ErrInvalidRules)
+		require.Len(t, result.Errors, 4)
+		require.Equal(t, "job up sum", result.Errors[0].Rule)
+		require.Equal(t, "NoExpr", result.Errors[1].Rule)
+		require.Equal(t, "BadCondition", result.Errors[2].Rule)
+		require.Equal(t, "bad-interval", result.Errors[3].Group)
+		require.Empty(t, result.Errors[3].Rule)
+
+		for _, op := range ruleStore.RecordedOps {
+			switch op.(type) {
+			case []ngmodels.AlertRule, []store.UpdateRule:
+				t.Fatalf("unexpected write %v", op)
+			}
+		}
+	})
+
+	t.Run("imports native recording rules", func(t *testing.T) {
+		ruleStore := store.NewFakeRuleStore(t)
+		sut := createServiceSut(ruleStore)
+		file := RuleFile{Groups: []RuleGroup{{Name: "group", Rules: []Rule{{Record: "job:up:sum", Expr: "sum by (job) (up)"}}}}}
+
+		result, err := sut.Import(context.Background(), 1, "folder", file, ImportOptions{DatasourceUID: "prometheus"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"job:up:sum"}, result.Created)
+		for _, op := range ruleStore.RecordedOps {
+			if rules, ok := op.([]ngmodels.AlertRule); ok {
+				require.Equal(t, &ngmodels.Record{Metric: "job:up:sum", From: "A"}, rules[0].Record)
+			}
+		}
+	})
+
+	t.Run("native rules require a data source", func(t *testing.T) {
+		sut := createServiceSut(store.NewFakeRuleStore(t))
+		file := RuleFile{Groups: []RuleGroup{{Name: "group", Rules: []Rule{{Alert: "Down", Expr: "up == 0"}}}}}
+
+		result, err := sut.Import(context.Background(), 1, "folder", file, ImportOptions{})
+		require.ErrorIs(t, err, ErrInvalidRules)
+		require.Len(t, result.Errors, 1)
+	})
+
+	t.Run("creates new rules and updates existing ones", func(t *testing.T) {
+		ruleStore := store.NewFakeRuleStore(t)
+		existingNative := promQLRule("Down", "group")
+		existingGrafana := ngmodels.AlertRuleGen(withGroup("group"))()
+		existingGrafana.Condition = existingGrafana.Data[0].RefID
+		ruleStore.PutRule(context.Background(), existingNative, existingGrafana)
+		sut := createServiceSut(ruleStore)
+
+		exported, err := FromAlertRules([]*ngmodels.AlertRule{existingGrafana})
+		require.NoError(t, err)
+		rules := append(exported.Groups[0].Rules,
+			Rule{Alert: "Down", Expr: "up < 1"},
+			Rule{Alert: "HighLatency", Expr: "latency > 1"},
+		)
+		file := RuleFile{Groups: []RuleGroup{{Name: "group", Rules: rules}}}
+
+		result, err := sut.Import(context.Background(), 1, "folder", file, ImportOptions{DatasourceUID: "prometheus"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"HighLatency"}, result.Created)
+		require.ElementsMatch(t, []string{existingGrafana.Title, "Down"}, result.Updated)
+
+		var inserts []ngmodels.AlertRule
+		var updates []store.UpdateRule
+		for _, op := range ruleStore.RecordedOps {
+			switch op := op.(type) {
+			case []ngmodels.AlertRule:
+				inserts = append(inserts, op...)
+			case []store.UpdateRule:
+				updates = append(updates, op...)
+			}
+		}
+		require.Len(t, inserts, 1)
+		require.Equal(t, int64(60), inserts[0].IntervalSeconds)
+		require.Equal(t, "folder", inserts[0].NamespaceUID)
+		require.Len(t, updates, 2)
+		for _, u := range updates {
+			require.Equal(t, u.Existing.UID, u.New.UID)
+		}
+	})
+
+	t.Run("rejects the uids of rules of other folders", func(t *testing.T) {
+		ruleStore := store.NewFakeRuleStore(t)
+		other := ngmodels.AlertRuleGen(withGroup("group"))()
+		other.Condition = other.Data[0].RefID
+		other.NamespaceUID = "other-folder"
+		ruleStore.PutRule(context.Background(), other)
+		sut := createServiceSut(ruleStore)
+
+		exported, err := FromAlertRules([]*ngmodels.AlertRule{other})
+		require.NoError(t, err)
+
+		result, err := sut.Import(context.Background(), 1, "folder", exported, ImportOptions{DatasourceUID: "prometheus"})
+		require.ErrorIs(t, err, ErrInvalidRules)
+		require.Len(t, result.Errors, 1)
+		require.Equal(t, "uid "+other.UID+" belongs to a rule of another folder", result.Errors[0].Error)
+		for _, op := range ruleStore.RecordedOps {
+			switch op.(type) {
+			case []ngmodels.AlertRule, []store.UpdateRule:
+				t.Fatalf("unexpected write %v", op)
+			}
+		}
+	})
+
+	t.Run("fails when the quota is reached", func(t *testing.T) {
+		sut := createServiceSut(store.NewFakeRuleStore(t))
+		file := RuleFile{Groups: []RuleGroup{{Name: "group", Rules: []Rule{{Alert: "Down", Expr: "up == 0"}}}}}
+
+		_, err := sut.Import(context.Background(), 1, "folder", file, ImportOptions{
+			DatasourceUID: "prometheus",
+			QuotaReached:  func(context.Context) (bool, error) { return true, nil },
+		})
+		require.ErrorIs(t, err, ErrQuotaReached)
+	})
+
+	t.Run("reports the updated rules", func(t *testing.T) {
+		ruleStore := store.NewFakeRuleStore(t)
+		existing := promQLRule("Down", "group")
+		ruleStore.PutRule(context.Background(), existing)
+		sut := createServiceSut(ruleStore)
+		file := RuleFile{Groups: []RuleGroup{{Name: "group", Rules: []Rule{
+			{Alert: "Down", Expr: "up < 1"},
+			{Alert: "HighLatency", Expr: "latency > 1"},
+		}}}}
+
+		var updated []ngmodels.AlertRuleKey
+		_, err := sut.Import(context.Background(), 1, "folder", file, ImportOptions{
+			DatasourceUID: "prometheus",
+			QuotaReached:  func(context.Context) (bool, error) { return false, nil },
+			RuleUpdated:   func(key ngmodels.AlertRuleKey) { updated = append(updated, key) },
+		})
+		require.NoError(t, err)
+		require.Equal(t, []ngmodels.AlertRuleKey{existing.GetKey()}, updated)
+	})
+}
+
+func TestExportRecordingRules(t *testing.T) {
+	native := promQLRule("job:up:sum", "group")
+	native.Record = &ngmodels.Record{Metric: "job:up:sum", From: "A"}
+	renamed := promQLRule("Jobs up", "group")
+	renamed.Record = &ngmodels.Record{Metric: "job:up:sum", From: "A"}
+
+	file, err := FromAlertRules([]*ngmodels.AlertRule{native, renamed})
+	require.NoError(t, err)
+	exported := file.Groups[0].Rules
+	require.Equal(t, Rule{Record: "job:up:sum", Expr: "up == 0", For: exported[0].For, Labels: native.Labels, Annotations: native.Annotations}, exported[0])
+	require.Equal(t, "Jobs up", exported[1].Alert)
+	require.Equal(t, "job:up:sum", exported[1].Record)
+	require.NotNil(t, exported[1].GrafanaAlert)
+
+	for i, rule := range []*ngmodels.AlertRule{native, renamed} {
+		imported, err := toAlertRule(exported[i], "prometheus")
+		require.NoError(t, err)
+		require.Equal(t, rule.Title, imported.Title)
+		require.Equal(t, rule.Record, imported.Record)
+	}
+}
+
+func TestExportQueryOffset(t *testing.T) {
+	rule := promQLRule("Instance down", "group")
+	rule.QueryOffset = 5 * time.Minute
+
+	file, err := FromAlertRules([]*ngmodels.AlertRule{rule})
+	require.NoError(t, err)
+	exported := file.Groups[0].Rules[0]
+	require.Empty(t, exported.Expr)
+	require.NotNil(t, exported.GrafanaAlert)
+	require.EqualValues(t, 5*time.Minute, exported.GrafanaAlert.QueryOffset)
+
+	imported, err := toAlertRule(exported, "prometheus")
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Minute, imported.QueryOffset)
+}
+
+func TestExportKeepFiringFor(t *testing.T) {
+	rule := promQLRule("Instance down", "group")
+	rule.KeepFiringFor = 10 * time.Minute
+
+	file, err := FromAlertRules([]*ngmodels.AlertRule{rule})
+	require.NoError(t, err)
+	exported := file.Groups[0].Rules[0]
+	require.Equal(t, "up == 0", exported.Expr)
+	require.EqualValues(t, 10*time.Minute, exported.KeepFiringFor)
+
+	imported, err := toAlertRule(exported, "prometheus")
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Minute, imported.KeepFiringFor)
+}
+
+func createServiceSut(ruleStore RuleStore) *Service {
+	return NewService(ruleStore, store.NewFakeRuleStore(nil), 10*time.Second, time.Minute, log.NewNopLogger())
+}
+
+func promQLRule(title, group string) *ngmodels.AlertRule {
+	return ngmodels.AlertRuleGen(withGroup(group), func(r *ngmodels.AlertRule) {
+		r.Title = title
+		r.Condition = "A"
+		r.Data = []ngmodels.AlertQuery{{
+			RefID:         "A",
+			DatasourceUID: "prometheus",
+			Model:         json.RawMessage(`{"refId":"A","expr":"up == 0"}`),
+		}}
+		r.Labels = map[string]string{"severity": "critical"}
+	})()
+}
+
+func withGroup(group string) func(*ngmodels.AlertRule) {
+	return func(r *ngmodels.AlertRule) {
+		r.OrgID = 1
+		r.NamespaceUID = "folder"
+		r.RuleGroup = group
+		r.IntervalSeconds = 60
+	}
+}
@@ -0,0 +1,231 @@
+package promrules
+
+// LOGZ.IO GRAFANA CHANGE :: Prometheus rule-file export and import of alert rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+var (
+	// ErrInvalidRules is returned by Import when any rule of the rule file is invalid. Nothing is imported then.
+	ErrInvalidRules = errors.New("invalid rules")
+	// ErrQuotaReached is returned by Import when creating the new rules would exceed the alert rule quota.
+	ErrQuotaReached = errors.New("quota has been exceeded")
+)
+
+// RuleStore is the store of the alert rules the service exports and imports.
+type RuleStore interface {
+	ListAlertRules(ctx context.Context, query *ngmodels.ListAlertRulesQuery) error
+	InsertAlertRules(ctx context.Context, rule []ngmodels.AlertRule) (map[string]int64, error)
+	UpdateAlertRules(ctx context.Context, rule []store.UpdateRule) error
+}
+
+// TransactionManager runs the writes of an import in a single transaction.
+type TransactionManager interface {
+	InTransaction(ctx context.Context, work func(ctx context.Context) error) error
+}
+
+// Service exports the alert rules of a folder as a Prometheus rule file, and imports rule files into a folder.
+type Service struct {
+	store           RuleStore
+	xact            TransactionManager
+	baseInterval    time.Duration
+	defaultInterval time.Duration
+	log             log.Logger
+}
+
+func NewService(store RuleStore, xact TransactionManager, baseInterval, defaultInterval time.Duration, log log.Logger) *Service {
+	return &Service{
+		store:           store,
+		xact:            xact,
+		baseInterval:    baseInterval,
+		defaultInterval: defaultInterval,
+		log:             log,
+	}
+}
+
+// ImportOptions configures an import.
+type ImportOptions struct {
+	// DatasourceUID is the data source queried by the rules with a native expr.
+	DatasourceUID string
+	// Authorize, if set, rejects the rules it returns false for.
+	Authorize func(rule *ngmodels.AlertRule) bool
+	// QuotaReached, if set, is checked after the new rules are created, the import is rolled back with ErrQuotaReached
+	// if it returns true.
+	QuotaReached func(ctx context.Context) (bool, error)
+	// RuleUpdated, if set, is called for every updated rule once the import is committed, e.g. to have the scheduler
+	// evaluate the new definition of the rule.
+	RuleUpdated func(key ngmodels.AlertRuleKey)
+}
+
+// Export returns the rules of the folder that pass filter as a rule file. A nil filter exports every rule.
+func (s *Service) Export(ctx context.Context, orgID int64, folderUID string, filter func(rule *ngmodels.AlertRule) bool) (RuleFile, error) {
+	q := ngmodels.ListAlertRulesQuery{
+		OrgID:         orgID,
+		NamespaceUIDs: []string{folderUID},
+	}
+	if err := s.store.ListAlertRules(ctx, &q); err != nil {
+		return RuleFile{}, err
+	}
+
+	rules := make([]*ngmodels.AlertRule, 0, len(q.Result))
+	for _, r := range q.Result {
+		if filter == nil || filter(r) {
+			rules = append(rules, r)
+		}
+	}
+	return FromAlertRules(rules)
+}
+
+// Import creates the rules of the rule file in the folder, or updates them if they already exist. Rules are matched
+// by their uid, or, for native rules, by their title in the same group. A uid of a rule of another folder is invalid.
+// Rules of the folder that are not in the file are left untouched. If any rule is invalid, ErrInvalidRules is returned together with the errors of every rule.
+func (s *Service) Import(ctx context.Context, orgID int64, folderUID string, file RuleFile, opts ImportOptions) (definitions.PrometheusRulesImportResult, error) {
+	result := definitions.PrometheusRulesImportResult{Created: []string{}, Updated: []string{}}
+
+	q := ngmodels.ListAlertRulesQuery{OrgID: orgID}
+	if err := s.store.ListAlertRules(ctx, &q); err != nil {
+		return result, err
+	}
+	byUID := make(map[string]*ngmodels.AlertRule, len(q.Result))
+	byTitle := map[string]*ngmodels.AlertRule{}
+	for _, r := range q.Result {
+		byUID[r.UID] = r
+		if r.NamespaceUID == folderUID {
+			byTitle[r.RuleGroup+"/"+r.Title] = r
+		}
+	}
+
+	var (
+		inserts []ngmodels.AlertRule
+		updates []store.UpdateRule
+	)
+	seen := map[string]struct{}{}
+	for _, group := range file.Groups {
+		interval := time.Duration(group.Interval)
+		if interval == 0 {
+			interval = s.defaultInterval
+		}
+		if err := s.validateGroup(group, interval); err != nil {
+			result.Errors = append(result.Errors, definitions.PrometheusRuleImportError{Group: group.Name, Error: err.Error()})
+			continue
+		}
+
+		for _, rule := range group.Rules {
+			ruleErr := func(err error) {
+				result.Errors = append(result.Errors, definitions.PrometheusRuleImportError{Group: group.Name, Rule: ruleName(rule), Error: err.Error()})
+			}
+
+			r, err := toAlertRule(rule, opts.DatasourceUID)
+			if err != nil {
+				ruleErr(err)
+				continue
+			}
+			r.OrgID = orgID
+			r.NamespaceUID = folderUID
+			r.RuleGroup = group.Name
+			r.IntervalSeconds = int64(interval.Seconds())
+
+			if opts.Authorize != nil && !opts.Authorize(&r) {
+				ruleErr(errors.New("not authorized to query the data sources of the rule"))
+				continue
+			}
+
+			existing := byUID[r.UID]
+			if r.UID == "" {
+				existing = byTitle[group.Name+"/"+r.Title]
+			} else if existing != nil && existing.NamespaceUID != folderUID {
+				ruleErr(fmt.Errorf("uid %s belongs to a rule of another folder", r.UID))
+				continue
+			}
+			key := group.Name + "/" + r.Title
+			if existing != nil {
+				key = existing.UID
+			}
+			if _, ok := seen[key]; ok {
+				ruleErr(errors.New("rule is declared more than once"))
+				continue
+			}
+			seen[key] = struct{}{}
+
+			if existing == nil {
+				inserts = append(inserts, r)
+				continue
+			}
+			r.UID = existing.UID
+			updates = append(updates, store.UpdateRule{Existing: existing, New: r})
+		}
+	}
+	if len(result.Errors) > 0 {
+		return result, ErrInvalidRules
+	}
+
+	err := s.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if len(inserts) > 0 {
+			if _, err := s.store.InsertAlertRules(ctx, inserts); err != nil {
+				return fmt.Errorf("failed to create rules: %w", err)
+			}
+			if opts.QuotaReached != nil {
+				limitReached, err := opts.QuotaReached(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to get alert rules quota: %w", err)
+				}
+				if limitReached {
+					return ErrQuotaReached
+				}
+			}
+		}
+		if len(updates) > 0 {
+			if err := s.store.UpdateAlertRules(ctx, updates); err != nil {
+				return fmt.Errorf("failed to update rules: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for _, r := range inserts {
+		result.Created = append(result.Created, r.Title)
+	}
+	for _, u := range updates {
+		result.Updated = append(result.Updated, u.New.Title)
+		if opts.RuleUpdated != nil {
+			opts.RuleUpdated(u.Existing.GetKey())
+		}
+	}
+	s.log.Info("Imported Prometheus rule file", "org", orgID, "folder", folderUID, "created", len(inserts), "updated", len(updates))
+	return result, nil
+}
+
+func (s *Service) validateGroup(group RuleGroup, interval time.Duration) error {
+	if group.Name == "" {
+		return errors.New("name is required")
+	}
+	if interval <= 0 || interval%s.baseInterval != 0 {
+		return fmt.Errorf("interval %s should be a multiple of the scheduler interval %s", interval, s.baseInterval)
+	}
+	if len(group.Name) > store.AlertRuleMaxRuleGroupNameLength {
+		return fmt.Errorf("name should not be longer than %d characters", store.AlertRuleMaxRuleGroupNameLength)
+	}
+	return nil
+}
+
+// ruleName is the name of a rule in a rule file, which is the alert of alerting rules and the record of recording rules.
+func ruleName(rule Rule) string {
+	if rule.Alert != "" {
+		return rule.Alert
+	}
+	return rule.Record
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
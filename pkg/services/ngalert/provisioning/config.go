@@ -51,3 +51,20 @@ func getLastConfiguration(ctx context.Context, orgID int64, store AMConfigStore)
 		version:          q.Result.ConfigurationVersion,
 	}, nil
 }
+
+// LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
+func saveAlertmanagerConfiguration(ctx context.Context, store AMConfigStore, orgID int64, revision *cfgRevision) error {
+	serialized, err := serializeAlertmanagerConfig(*revision.cfg)
+	if err != nil {
+		return err
+	}
+	return store.UpdateAlertmanagerConfiguration(ctx, &models.SaveAlertmanagerConfigurationCmd{
+		AlertmanagerConfiguration: string(serialized),
+		ConfigurationVersion:      revision.version,
+		FetchedConfigurationHash:  revision.concurrencyToken,
+		Default:                   false,
+		OrgID:                     orgID,
+	})
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -4,3 +4,4 @@ import "fmt"
 
 var ErrValidation = fmt.Errorf("invalid object specification")
 var ErrNotFound = fmt.Errorf("object not found")
+var ErrInUse = fmt.Errorf("object is in use") // LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
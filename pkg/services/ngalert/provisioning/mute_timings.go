@@ -0,0 +1,194 @@
+package provisioning
+
+// LOGZ.IO GRAFANA CHANGE :: Provisioning API for mute timings
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/config"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type MuteTimingService struct {
+	config AMConfigStore
+	prov   ProvisioningStore
+	xact   TransactionManager
+	log    log.Logger
+}
+
+func NewMuteTimingService(config AMConfigStore, prov ProvisioningStore, xact TransactionManager, log log.Logger) *MuteTimingService {
+	return &MuteTimingService{
+		config: config,
+		prov:   prov,
+		xact:   xact,
+		log:    log,
+	}
+}
+
+// GetMuteTimings returns all the mute timings of the org.
+func (svc *MuteTimingService) GetMuteTimings(ctx context.Context, orgID int64) ([]definitions.MuteTimeInterval, error) {
+	revision, err := getLastConfiguration(ctx, orgID, svc.config)
+	if err != nil {
+		return nil, err
+	}
+
+	provenances, err := svc.prov.GetProvenances(ctx, orgID, (&definitions.MuteTimeInterval{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]definitions.MuteTimeInterval, 0, len(revision.cfg.AlertmanagerConfig.MuteTimeIntervals))
+	for _, interval := range revision.cfg.AlertmanagerConfig.MuteTimeIntervals {
+		result = append(result, definitions.MuteTimeInterval{MuteTimeInterval: interval, Provenance: provenances[interval.Name]})
+	}
+	return result, nil
+}
+
+// GetMuteTiming returns the mute timing of the org with the given name.
+func (svc *MuteTimingService) GetMuteTiming(ctx context.Context, name string, orgID int64) (definitions.MuteTimeInterval, error) {
+	revision, err := getLastConfiguration(ctx, orgID, svc.config)
+	if err != nil {
+		return definitions.MuteTimeInterval{}, err
+	}
+
+	idx := muteTimingIndex(revision.cfg.AlertmanagerConfig.MuteTimeIntervals, name)
+	if idx < 0 {
+		return definitions.MuteTimeInterval{}, fmt.Errorf("%w: mute timing %s", ErrNotFound, name)
+	}
+
+	result := definitions.MuteTimeInterval{MuteTimeInterval: revision.cfg.AlertmanagerConfig.MuteTimeIntervals[idx]}
+	result.Provenance, err = svc.prov.GetProvenance(ctx, &result, orgID)
+	if err != nil {
+		return definitions.MuteTimeInterval{}, err
+	}
+	return result, nil
+}
+
+// CreateMuteTiming adds the mute timing to the org and returns it.
+func (svc *MuteTimingService) CreateMuteTiming(ctx context.Context, mt definitions.MuteTimeInterval, orgID int64) (definitions.MuteTimeInterval, error) {
+	if err := mt.Validate(); err != nil {
+		return definitions.MuteTimeInterval{}, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+
+	revision, err := getLastConfiguration(ctx, orgID, svc.config)
+	if err != nil {
+		return definitions.MuteTimeInterval{}, err
+	}
+
+	if muteTimingIndex(revision.cfg.AlertmanagerConfig.MuteTimeIntervals, mt.Name) >= 0 {
+		return definitions.MuteTimeInterval{}, fmt.Errorf("%w: a mute timing with the name %s already exists", ErrValidation, mt.Name)
+	}
+	revision.cfg.AlertmanagerConfig.MuteTimeIntervals = append(revision.cfg.AlertmanagerConfig.MuteTimeIntervals, mt.MuteTimeInterval)
+
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := saveAlertmanagerConfiguration(ctx, svc.config, orgID, revision); err != nil {
+			return err
+		}
+		return svc.prov.SetProvenance(ctx, &mt, orgID, mt.Provenance)
+	})
+	if err != nil {
+		return definitions.MuteTimeInterval{}, err
+	}
+	return mt, nil
+}
+
+// UpdateMuteTiming replaces the mute timing of the org that has the same name and returns it.
+func (svc *MuteTimingService) UpdateMuteTiming(ctx context.Context, mt definitions.MuteTimeInterval, orgID int64) (definitions.MuteTimeInterval, error) {
+	if err := mt.Validate(); err != nil {
+		return definitions.MuteTimeInterval{}, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+
+	revision, err := getLastConfiguration(ctx, orgID, svc.config)
+	if err != nil {
+		return definitions.MuteTimeInterval{}, err
+	}
+
+	idx := muteTimingIndex(revision.cfg.AlertmanagerConfig.MuteTimeIntervals, mt.Name)
+	if idx < 0 {
+		return definitions.MuteTimeInterval{}, fmt.Errorf("%w: mute timing %s", ErrNotFound, mt.Name)
+	}
+	storedProvenance, err := svc.prov.GetProvenance(ctx, &mt, orgID)
+	if err != nil {
+		return definitions.MuteTimeInterval{}, err
+	}
+	if storedProvenance != mt.Provenance && storedProvenance != models.ProvenanceNone {
+		return definitions.MuteTimeInterval{}, fmt.Errorf("cannot changed provenance from '%s' to '%s'", storedProvenance, mt.Provenance)
+	}
+	revision.cfg.AlertmanagerConfig.MuteTimeIntervals[idx] = mt.MuteTimeInterval
+
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := saveAlertmanagerConfiguration(ctx, svc.config, orgID, revision); err != nil {
+			return err
+		}
+		return svc.prov.SetProvenance(ctx, &mt, orgID, mt.Provenance)
+	})
+	if err != nil {
+		return definitions.MuteTimeInterval{}, err
+	}
+	return mt, nil
+}
+
+// DeleteMuteTiming removes the mute timing from the org. A mute timing that is used by a notification policy is not removed.
+func (svc *MuteTimingService) DeleteMuteTiming(ctx context.Context, name string, orgID int64, provenance models.Provenance) error {
+	revision, err := getLastConfiguration(ctx, orgID, svc.config)
+	if err != nil {
+		return err
+	}
+
+	idx := muteTimingIndex(revision.cfg.AlertmanagerConfig.MuteTimeIntervals, name)
+	if idx < 0 {
+		return fmt.Errorf("%w: mute timing %s", ErrNotFound, name)
+	}
+	target := &definitions.MuteTimeInterval{MuteTimeInterval: config.MuteTimeInterval{Name: name}}
+	storedProvenance, err := svc.prov.GetProvenance(ctx, target, orgID)
+	if err != nil {
+		return err
+	}
+	if storedProvenance != provenance && storedProvenance != models.ProvenanceNone {
+		return fmt.Errorf("cannot delete with provided provenance '%s', needs '%s'", provenance, storedProvenance)
+	}
+	if isMuteTimingInUse(name, revision.cfg.AlertmanagerConfig.Route) {
+		return fmt.Errorf("%w: mute timing %s is used by a notification policy", ErrInUse, name)
+	}
+	intervals := revision.cfg.AlertmanagerConfig.MuteTimeIntervals
+	revision.cfg.AlertmanagerConfig.MuteTimeIntervals = append(intervals[:idx], intervals[idx+1:]...)
+
+	return svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := saveAlertmanagerConfiguration(ctx, svc.config, orgID, revision); err != nil {
+			return err
+		}
+		return svc.prov.DeleteProvenance(ctx, target, orgID)
+	})
+}
+
+func muteTimingIndex(intervals []config.MuteTimeInterval, name string) int {
+	for i, interval := range intervals {
+		if interval.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func isMuteTimingInUse(name string, route *definitions.Route) bool {
+	if route == nil {
+		return false
+	}
+	for _, mt := range route.MuteTimeIntervals {
+		if mt == name {
+			return true
+		}
+	}
+	for _, child := range route.Routes {
+		if isMuteTimingInUse(name, child) {
+			return true
+		}
+	}
+	return false
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
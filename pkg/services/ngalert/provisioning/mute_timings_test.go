@@ -0,0 +1,169 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestMuteTimingService(t *testing.T) {
+	t.Run("service returns empty list when there are no mute timings", func(t *testing.T) {
+		sut := createMuteTimingSvcSut()
+
+		result, err := sut.GetMuteTimings(context.Background(), 1)
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+
+	t.Run("service creates mute timing and records its provenance", func(t *testing.T) {
+		sut := createMuteTimingSvcSut()
+
+		mt := createMuteTiming(t, "weekends", `{"weekdays": ["saturday", "sunday"], "times": [{"start_time": "00:00", "end_time": "12:00"}]}`)
+		mt.Provenance = models.ProvenanceAPI
+		_, err := sut.CreateMuteTiming(context.Background(), mt, 1)
+		require.NoError(t, err)
+
+		result, err := sut.GetMuteTimings(context.Background(), 1)
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, "weekends", result[0].Name)
+		require.Equal(t, models.ProvenanceAPI, result[0].Provenance)
+
+		single, err := sut.GetMuteTiming(context.Background(), "weekends", 1)
+		require.NoError(t, err)
+		require.Equal(t, result[0], single)
+	})
+
+	t.Run("service rejects mute timings with an existing name", func(t *testing.T) {
+		sut := createMuteTimingSvcSut()
+		mt := createMuteTiming(t, "weekends", `{"weekdays": ["monday:friday"]}`)
+		_, err := sut.CreateMuteTiming(context.Background(), mt, 1)
+		require.NoError(t, err)
+
+		_, err = sut.CreateMuteTiming(context.Background(), mt, 1)
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("service rejects invalid mute timings", func(t *testing.T) {
+		sut := createMuteTimingSvcSut()
+
+		_, err := sut.CreateMuteTiming(context.Background(), definitions.MuteTimeInterval{}, 1)
+		require.ErrorIs(t, err, ErrValidation)
+
+		invalidTimeRange := definitions.MuteTimeInterval{MuteTimeInterval: config.MuteTimeInterval{
+			Name:          "invalid",
+			TimeIntervals: []timeinterval.TimeInterval{{Times: []timeinterval.TimeRange{{StartMinute: 600, EndMinute: 60}}}},
+		}}
+		_, err = sut.CreateMuteTiming(context.Background(), invalidTimeRange, 1)
+		require.ErrorIs(t, err, ErrValidation)
+
+	})
+
+	t.Run("service updates existing mute timing", func(t *testing.T) {
+		sut := createMuteTimingSvcSut()
+		_, err := sut.CreateMuteTiming(context.Background(), createMuteTiming(t, "holidays", `{"months": ["december"]}`), 1)
+		require.NoError(t, err)
+
+		_, err = sut.UpdateMuteTiming(context.Background(), createMuteTiming(t, "holidays", `{"months": ["july:august"]}`), 1)
+		require.NoError(t, err)
+
+		updated, err := sut.GetMuteTiming(context.Background(), "holidays", 1)
+		require.NoError(t, err)
+		require.Len(t, updated.TimeIntervals[0].Months, 1)
+		require.Equal(t, 7, updated.TimeIntervals[0].Months[0].Begin)
+	})
+
+	t.Run("service returns not found for unknown mute timing", func(t *testing.T) {
+		sut := createMuteTimingSvcSut()
+
+		_, err := sut.GetMuteTiming(context.Background(), "unknown", 1)
+		require.ErrorIs(t, err, ErrNotFound)
+
+		_, err = sut.UpdateMuteTiming(context.Background(), createMuteTiming(t, "unknown", `{"months": ["july"]}`), 1)
+		require.ErrorIs(t, err, ErrNotFound)
+
+		err = sut.DeleteMuteTiming(context.Background(), "unknown", 1, models.ProvenanceAPI)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("service deletes mute timing and its provenance", func(t *testing.T) {
+		sut := createMuteTimingSvcSut()
+		mt := createMuteTiming(t, "holidays", `{"months": ["december"]}`)
+		mt.Provenance = models.ProvenanceAPI
+		_, err := sut.CreateMuteTiming(context.Background(), mt, 1)
+		require.NoError(t, err)
+
+		require.NoError(t, sut.DeleteMuteTiming(context.Background(), "holidays", 1, models.ProvenanceAPI))
+
+		result, err := sut.GetMuteTimings(context.Background(), 1)
+		require.NoError(t, err)
+		require.Empty(t, result)
+		provenance, err := sut.prov.GetProvenance(context.Background(), &mt, 1)
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceNone, provenance)
+	})
+
+	t.Run("service refuses to delete mute timing used by a notification policy", func(t *testing.T) {
+		sut := createMuteTimingSvcSut()
+		_, err := sut.CreateMuteTiming(context.Background(), createMuteTiming(t, "holidays", `{"months": ["december"]}`), 1)
+		require.NoError(t, err)
+
+		revision, err := getLastConfiguration(context.Background(), 1, sut.config)
+		require.NoError(t, err)
+		revision.cfg.AlertmanagerConfig.Route.Routes[0].MuteTimeIntervals = []string{"holidays"}
+		require.NoError(t, saveAlertmanagerConfiguration(context.Background(), sut.config, 1, revision))
+
+		err = sut.DeleteMuteTiming(context.Background(), "holidays", 1, models.ProvenanceAPI)
+		require.ErrorIs(t, err, ErrInUse)
+
+		_, err = sut.GetMuteTiming(context.Background(), "holidays", 1)
+		require.NoError(t, err)
+	})
+
+	t.Run("service rejects changing the provenance of a mute timing", func(t *testing.T) {
+		sut := createMuteTimingSvcSut()
+		mt := createMuteTiming(t, "holidays", `{"months": ["december"]}`)
+		mt.Provenance = models.ProvenanceFile
+		_, err := sut.CreateMuteTiming(context.Background(), mt, 1)
+		require.NoError(t, err)
+
+		update := createMuteTiming(t, "holidays", `{"months": ["july"]}`)
+		update.Provenance = models.ProvenanceAPI
+		_, err = sut.UpdateMuteTiming(context.Background(), update, 1)
+		require.EqualError(t, err, "cannot changed provenance from 'file' to 'api'")
+
+		err = sut.DeleteMuteTiming(context.Background(), "holidays", 1, models.ProvenanceAPI)
+		require.EqualError(t, err, "cannot delete with provided provenance 'api', needs 'file'")
+
+		stored, err := sut.GetMuteTiming(context.Background(), "holidays", 1)
+		require.NoError(t, err)
+		require.Equal(t, mt, stored)
+	})
+}
+
+func createMuteTimingSvcSut() *MuteTimingService {
+	return &MuteTimingService{
+		config: newFakeAMConfigStore(),
+		prov:   NewFakeProvisioningStore(),
+		xact:   newNopTransactionManager(),
+		log:    log.NewNopLogger(),
+	}
+}
+
+func createMuteTiming(t *testing.T, name string, interval string) definitions.MuteTimeInterval {
+	t.Helper()
+	var ti timeinterval.TimeInterval
+	require.NoError(t, json.Unmarshal([]byte(interval), &ti))
+	return definitions.MuteTimeInterval{MuteTimeInterval: config.MuteTimeInterval{
+		Name:          name,
+		TimeIntervals: []timeinterval.TimeInterval{ti},
+	}}
+}
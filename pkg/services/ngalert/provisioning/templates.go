@@ -0,0 +1,190 @@
+package provisioning
+
+// LOGZ.IO GRAFANA CHANGE :: Provisioning API for message templates
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	tmpltext "text/template"
+
+	"github.com/prometheus/alertmanager/template"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+)
+
+// defaultTemplateName is the name the Alertmanager stores the default template under, see notifier.Alertmanager.
+const defaultTemplateName = "__default__.tmpl"
+
+type TemplateService struct {
+	config AMConfigStore
+	prov   ProvisioningStore
+	xact   TransactionManager
+	log    log.Logger
+}
+
+func NewTemplateService(config AMConfigStore, prov ProvisioningStore, xact TransactionManager, log log.Logger) *TemplateService {
+	return &TemplateService{
+		config: config,
+		prov:   prov,
+		xact:   xact,
+		log:    log,
+	}
+}
+
+// GetTemplates returns all the message templates of the org, sorted by name.
+func (t *TemplateService) GetTemplates(ctx context.Context, orgID int64) ([]definitions.MessageTemplate, error) {
+	revision, err := getLastConfiguration(ctx, orgID, t.config)
+	if err != nil {
+		return nil, err
+	}
+
+	provenances, err := t.prov.GetProvenances(ctx, orgID, (&definitions.MessageTemplate{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]definitions.MessageTemplate, 0, len(revision.cfg.TemplateFiles))
+	for name, content := range revision.cfg.TemplateFiles {
+		result = append(result, definitions.MessageTemplate{Name: name, Template: content, Provenance: provenances[name]})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// GetTemplate returns the message template of the org with the given name.
+func (t *TemplateService) GetTemplate(ctx context.Context, name string, orgID int64) (definitions.MessageTemplate, error) {
+	revision, err := getLastConfiguration(ctx, orgID, t.config)
+	if err != nil {
+		return definitions.MessageTemplate{}, err
+	}
+
+	content, ok := revision.cfg.TemplateFiles[name]
+	if !ok {
+		return definitions.MessageTemplate{}, fmt.Errorf("%w: template %s", ErrNotFound, name)
+	}
+
+	result := definitions.MessageTemplate{Name: name, Template: content}
+	result.Provenance, err = t.prov.GetProvenance(ctx, &result, orgID)
+	if err != nil {
+		return definitions.MessageTemplate{}, err
+	}
+	return result, nil
+}
+
+// CreateTemplate adds the message template to the org and returns it.
+func (t *TemplateService) CreateTemplate(ctx context.Context, tmpl definitions.MessageTemplate, orgID int64) (definitions.MessageTemplate, error) {
+	if err := validateTemplate(tmpl); err != nil {
+		return definitions.MessageTemplate{}, err
+	}
+
+	revision, err := getLastConfiguration(ctx, orgID, t.config)
+	if err != nil {
+		return definitions.MessageTemplate{}, err
+	}
+
+	if _, exists := revision.cfg.TemplateFiles[tmpl.Name]; exists {
+		return definitions.MessageTemplate{}, fmt.Errorf("%w: a template with the name %s already exists", ErrValidation, tmpl.Name)
+	}
+	if revision.cfg.TemplateFiles == nil {
+		revision.cfg.TemplateFiles = map[string]string{}
+	}
+	revision.cfg.TemplateFiles[tmpl.Name] = tmpl.Template
+
+	if err := t.save(ctx, orgID, revision, &tmpl); err != nil {
+		return definitions.MessageTemplate{}, err
+	}
+	return tmpl, nil
+}
+
+// UpdateTemplate replaces the content of the message template of the org that has the same name and returns it.
+func (t *TemplateService) UpdateTemplate(ctx context.Context, tmpl definitions.MessageTemplate, orgID int64) (definitions.MessageTemplate, error) {
+	if err := validateTemplate(tmpl); err != nil {
+		return definitions.MessageTemplate{}, err
+	}
+
+	revision, err := getLastConfiguration(ctx, orgID, t.config)
+	if err != nil {
+		return definitions.MessageTemplate{}, err
+	}
+
+	if _, exists := revision.cfg.TemplateFiles[tmpl.Name]; !exists {
+		return definitions.MessageTemplate{}, fmt.Errorf("%w: template %s", ErrNotFound, tmpl.Name)
+	}
+	storedProvenance, err := t.prov.GetProvenance(ctx, &tmpl, orgID)
+	if err != nil {
+		return definitions.MessageTemplate{}, err
+	}
+	if storedProvenance != tmpl.Provenance && storedProvenance != models.ProvenanceNone {
+		return definitions.MessageTemplate{}, fmt.Errorf("cannot changed provenance from '%s' to '%s'", storedProvenance, tmpl.Provenance)
+	}
+	revision.cfg.TemplateFiles[tmpl.Name] = tmpl.Template
+
+	if err := t.save(ctx, orgID, revision, &tmpl); err != nil {
+		return definitions.MessageTemplate{}, err
+	}
+	return tmpl, nil
+}
+
+// DeleteTemplate removes the message template from the org.
+func (t *TemplateService) DeleteTemplate(ctx context.Context, name string, orgID int64, provenance models.Provenance) error {
+	revision, err := getLastConfiguration(ctx, orgID, t.config)
+	if err != nil {
+		return err
+	}
+
+	if _, exists := revision.cfg.TemplateFiles[name]; !exists {
+		return nil
+	}
+	storedProvenance, err := t.prov.GetProvenance(ctx, &definitions.MessageTemplate{Name: name}, orgID)
+	if err != nil {
+		return err
+	}
+	if storedProvenance != provenance && storedProvenance != models.ProvenanceNone {
+		return fmt.Errorf("cannot delete with provided provenance '%s', needs '%s'", provenance, storedProvenance)
+	}
+	delete(revision.cfg.TemplateFiles, name)
+
+	return t.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := saveAlertmanagerConfiguration(ctx, t.config, orgID, revision); err != nil {
+			return err
+		}
+		return t.prov.DeleteProvenance(ctx, &definitions.MessageTemplate{Name: name}, orgID)
+	})
+}
+
+func (t *TemplateService) save(ctx context.Context, orgID int64, revision *cfgRevision, tmpl *definitions.MessageTemplate) error {
+	return t.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := saveAlertmanagerConfiguration(ctx, t.config, orgID, revision); err != nil {
+			return err
+		}
+		return t.prov.SetProvenance(ctx, tmpl, orgID, tmpl.Provenance)
+	})
+}
+
+// validateTemplate checks the message template and parses it together with the default template, so that it can
+// use the functions of the Alertmanager and the templates defined by the default template.
+func validateTemplate(tmpl definitions.MessageTemplate) error {
+	if err := tmpl.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+	if tmpl.Name == defaultTemplateName {
+		return fmt.Errorf("%w: the template name %s is reserved", ErrValidation, defaultTemplateName)
+	}
+
+	parsed, err := tmpltext.New(defaultTemplateName).Option("missingkey=zero").Funcs(tmpltext.FuncMap(template.DefaultFuncs)).Parse(channels.DefaultTemplateString)
+	if err != nil {
+		return fmt.Errorf("failed to parse the default template: %w", err)
+	}
+	if _, err := parsed.New(tmpl.Name).Parse(tmpl.Template); err != nil {
+		return fmt.Errorf("%w: invalid template: %s", ErrValidation, err.Error())
+	}
+	return nil
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -0,0 +1,149 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestTemplateService(t *testing.T) {
+	t.Run("service returns empty list when there are no templates", func(t *testing.T) {
+		sut := createTemplateServiceSut()
+
+		result, err := sut.GetTemplates(context.Background(), 1)
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+
+	t.Run("service creates template and records its provenance", func(t *testing.T) {
+		sut := createTemplateServiceSut()
+		tmpl := createMessageTemplate("custom", `{{ define "custom.title" }}{{ template "default.title" . }} - custom{{ end }}`)
+		tmpl.Provenance = models.ProvenanceAPI
+
+		_, err := sut.CreateTemplate(context.Background(), tmpl, 1)
+		require.NoError(t, err)
+
+		result, err := sut.GetTemplates(context.Background(), 1)
+		require.NoError(t, err)
+		require.Equal(t, []definitions.MessageTemplate{tmpl}, result)
+
+		single, err := sut.GetTemplate(context.Background(), "custom", 1)
+		require.NoError(t, err)
+		require.Equal(t, tmpl, single)
+	})
+
+	t.Run("service rejects templates with an existing name", func(t *testing.T) {
+		sut := createTemplateServiceSut()
+		tmpl := createMessageTemplate("custom", `{{ define "custom.title" }}title{{ end }}`)
+		_, err := sut.CreateTemplate(context.Background(), tmpl, 1)
+		require.NoError(t, err)
+
+		_, err = sut.CreateTemplate(context.Background(), tmpl, 1)
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("service rejects invalid templates", func(t *testing.T) {
+		sut := createTemplateServiceSut()
+
+		for _, tmpl := range []definitions.MessageTemplate{
+			createMessageTemplate("", `{{ define "custom.title" }}title{{ end }}`),
+			createMessageTemplate("../custom", `{{ define "custom.title" }}title{{ end }}`),
+			createMessageTemplate(defaultTemplateName, `{{ define "custom.title" }}title{{ end }}`),
+			createMessageTemplate("custom", ""),
+			createMessageTemplate("custom", `{{ define "custom.title" }}title`),
+			createMessageTemplate("custom", `{{ define "custom.title" }}{{ unknownFunc . }}{{ end }}`),
+		} {
+			_, err := sut.CreateTemplate(context.Background(), tmpl, 1)
+			require.ErrorIs(t, err, ErrValidation, tmpl.Name)
+		}
+	})
+
+	t.Run("service updates existing template", func(t *testing.T) {
+		sut := createTemplateServiceSut()
+		_, err := sut.CreateTemplate(context.Background(), createMessageTemplate("custom", `{{ define "custom.title" }}old{{ end }}`), 1)
+		require.NoError(t, err)
+
+		_, err = sut.UpdateTemplate(context.Background(), createMessageTemplate("custom", `{{ define "custom.title" }}new{{ end }}`), 1)
+		require.NoError(t, err)
+
+		updated, err := sut.GetTemplate(context.Background(), "custom", 1)
+		require.NoError(t, err)
+		require.Equal(t, `{{ define "custom.title" }}new{{ end }}`, updated.Template)
+	})
+
+	t.Run("service returns not found for unknown template", func(t *testing.T) {
+		sut := createTemplateServiceSut()
+
+		_, err := sut.GetTemplate(context.Background(), "unknown", 1)
+		require.ErrorIs(t, err, ErrNotFound)
+
+		_, err = sut.UpdateTemplate(context.Background(), createMessageTemplate("unknown", `{{ define "custom.title" }}title{{ end }}`), 1)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("service deletes template and its provenance", func(t *testing.T) {
+		sut := createTemplateServiceSut()
+		tmpl := createMessageTemplate("custom", `{{ define "custom.title" }}title{{ end }}`)
+		tmpl.Provenance = models.ProvenanceAPI
+		_, err := sut.CreateTemplate(context.Background(), tmpl, 1)
+		require.NoError(t, err)
+
+		require.NoError(t, sut.DeleteTemplate(context.Background(), "custom", 1, models.ProvenanceAPI))
+
+		result, err := sut.GetTemplates(context.Background(), 1)
+		require.NoError(t, err)
+		require.Empty(t, result)
+		provenance, err := sut.prov.GetProvenance(context.Background(), &tmpl, 1)
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceNone, provenance)
+	})
+
+	t.Run("service rejects changing the provenance of a template", func(t *testing.T) {
+		sut := createTemplateServiceSut()
+		tmpl := createMessageTemplate("custom", `{{ define "custom.title" }}title{{ end }}`)
+		tmpl.Provenance = models.ProvenanceFile
+		_, err := sut.CreateTemplate(context.Background(), tmpl, 1)
+		require.NoError(t, err)
+
+		update := createMessageTemplate("custom", `{{ define "custom.title" }}new{{ end }}`)
+		update.Provenance = models.ProvenanceAPI
+		_, err = sut.UpdateTemplate(context.Background(), update, 1)
+		require.EqualError(t, err, "cannot changed provenance from 'file' to 'api'")
+
+		err = sut.DeleteTemplate(context.Background(), "custom", 1, models.ProvenanceAPI)
+		require.EqualError(t, err, "cannot delete with provided provenance 'api', needs 'file'")
+
+		stored, err := sut.GetTemplate(context.Background(), "custom", 1)
+		require.NoError(t, err)
+		require.Equal(t, tmpl, stored)
+	})
+
+	t.Run("service updates templates without a provenance", func(t *testing.T) {
+		sut := createTemplateServiceSut()
+		_, err := sut.CreateTemplate(context.Background(), createMessageTemplate("custom", `{{ define "custom.title" }}title{{ end }}`), 1)
+		require.NoError(t, err)
+
+		update := createMessageTemplate("custom", `{{ define "custom.title" }}new{{ end }}`)
+		update.Provenance = models.ProvenanceAPI
+		_, err = sut.UpdateTemplate(context.Background(), update, 1)
+		require.NoError(t, err)
+	})
+}
+
+func createTemplateServiceSut() *TemplateService {
+	return &TemplateService{
+		config: newFakeAMConfigStore(),
+		prov:   NewFakeProvisioningStore(),
+		xact:   newNopTransactionManager(),
+		log:    log.NewNopLogger(),
+	}
+}
+
+func createMessageTemplate(name string, content string) definitions.MessageTemplate {
+	return definitions.MessageTemplate{Name: name, Template: content}
+}
@@ -40,6 +40,17 @@ func stateToPostableAlert(alertState *state.State, appURL *url.URL) *models.Post
 		nA["__value_string__"] = alertState.LastEvaluationString
 	}
 
+	// LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+	if alertState.Image != nil {
+		if alertState.Image.URL != "" {
+			nA[ngModels.LogzioImageURLAnnotation] = alertState.Image.URL
+		}
+		if alertState.Image.Path != "" {
+			nA[ngModels.LogzioImagePathAnnotation] = alertState.Image.Path
+		}
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
 	var urlStr string
 	if uid := nL[ngModels.RuleUIDLabel]; len(uid) > 0 && appURL != nil {
 		u := *appURL
@@ -0,0 +1,97 @@
+package schedule
+
+// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// pausedRules are the rules whose routines are being stopped because they were paused.
+type pausedRules struct {
+	mu   sync.Mutex
+	keys map[models.AlertRuleKey]struct{}
+}
+
+func (p *pausedRules) add(key models.AlertRuleKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.keys == nil {
+		p.keys = make(map[models.AlertRuleKey]struct{})
+	}
+	p.keys[key] = struct{}{}
+}
+
+// take returns true if the rule was paused, and forgets it.
+func (p *pausedRules) take(key models.AlertRuleKey) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.keys[key]
+	delete(p.keys, key)
+	return ok
+}
+
+// pauseAlertRule stops the routine of a paused rule whose alerts are resolved. The routine is started again when the
+// rule is resumed. The routines of the paused rules whose alerts are kept keep running, see pausedRuleAlerts.
+func (sch *schedule) pauseAlertRule(key models.AlertRuleKey) {
+	ruleInfo, ok := sch.registry.del(key)
+	if !ok {
+		return
+	}
+	sch.log.Info("alert rule is paused, stopping its evaluation", "uid", key.UID, "org_id", key.OrgID)
+	sch.pausedRules.add(key)
+	ruleInfo.stop()
+}
+
+// stopPausedRule handles the alerts of a rule whose routine is stopping, and returns false if the rule was not paused.
+// The alerts are resolved, and deleted from the database so that they are not restored on restart.
+func (sch *schedule) stopPausedRule(key models.AlertRuleKey, clearState func()) bool {
+	if !sch.pausedRules.take(key) {
+		return false
+	}
+	clearState()
+	// the routine context is cancelled at this point
+	if err := sch.ruleStore.DeleteAlertInstancesByRuleUID(context.Background(), key.OrgID, key.UID); err != nil {
+		sch.log.Error("failed to delete the alert instances of the paused rule", "uid", key.UID, "org_id", key.OrgID, "err", err)
+	}
+	return true
+}
+
+// keepsStatesOnUpdate returns true if the states of the rule are kept by the update of the rule, which is the case when
+// it only pauses or resumes the rule and keepPausedRuleStates is set.
+func (sch *schedule) keepsStatesOnUpdate(oldRule, newRule *models.AlertRule) bool {
+	if !sch.keepPausedRuleStates || oldRule.IsPaused == newRule.IsPaused {
+		return false
+	}
+	return len(oldRule.Diff(newRule, "IsPaused", "Version", "Updated")) == 0
+}
+
+// pausedRuleAlerts returns the alerts of a paused rule to re-send instead of evaluating the rule when
+// keepPausedRuleStates is set, so that the Alertmanager does not resolve them while the rule is paused. The alerts end
+// as if the rule was evaluated at the given time, and are re-sent at most once per resend delay.
+func (sch *schedule) pausedRuleAlerts(r *models.AlertRule, now time.Time) definitions.PostableAlerts {
+	states := sch.stateManager.GetStatesForRuleUID(r.OrgID, r.UID)
+	alerts := definitions.PostableAlerts{PostableAlerts: make([]amv2.PostableAlert, 0, len(states))}
+	var sentStates []*state.State
+	for _, s := range states {
+		if s.State != eval.Alerting || now.Before(s.LastSentAt.Add(sch.stateManager.ResendDelay)) {
+			continue
+		}
+		s.ExtendEndsAt(r, now)
+		s.LastSentAt = now
+		alerts.PostableAlerts = append(alerts.PostableAlerts, *stateToPostableAlert(s, sch.appURL))
+		sentStates = append(sentStates, s)
+	}
+	sch.stateManager.Put(sentStates)
+	return alerts
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
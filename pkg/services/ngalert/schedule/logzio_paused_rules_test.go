@@ -0,0 +1,181 @@
+package schedule
+
+// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/sender"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func TestPausedRuleStates(t *testing.T) {
+	createSchedule := func(t *testing.T, keepPausedRuleStates bool) (*schedule, *store.FakeRuleStore, *models.AlertRule, *store.FakeExternalAlertmanager) {
+		fakeAM := store.NewFakeExternalAlertmanager(t)
+		t.Cleanup(fakeAM.Close)
+
+		orgID := rand.Int63()
+		s, err := sender.New(nil)
+		require.NoError(t, err)
+		require.NoError(t, s.ApplyConfig(&models.AdminConfiguration{OrgID: orgID, Alertmanagers: []string{fakeAM.Server.URL}}))
+		s.Run()
+		t.Cleanup(s.Stop)
+		require.Eventuallyf(t, func() bool {
+			return len(s.Alertmanagers()) == 1
+		}, 20*time.Second, 200*time.Millisecond, "external Alertmanager was not discovered.")
+
+		ruleStore := store.NewFakeRuleStore(t)
+		sch, _ := setupScheduler(t, ruleStore, &store.FakeInstanceStore{}, store.NewFakeAdminConfigStore(t), nil)
+		sch.senders[orgID] = s
+		sch.keepPausedRuleStates = keepPausedRuleStates
+
+		rule := CreateTestAlertRule(t, ruleStore, 10, orgID, eval.Alerting)
+		now := sch.clock.Now()
+		sch.stateManager.Put([]*state.State{{
+			AlertRuleUID:       rule.UID,
+			OrgID:              rule.OrgID,
+			CacheId:            "a",
+			State:              eval.Alerting,
+			Labels:             data.Labels{"instance": "a"},
+			StartsAt:           now,
+			EndsAt:             now.Add(time.Minute),
+			LastEvaluationTime: now,
+			LastSentAt:         now,
+		}})
+		return sch, ruleStore, rule, fakeAM
+	}
+
+	t.Run("the alerts of a paused rule are resolved", func(t *testing.T) {
+		sch, _, rule, fakeAM := createSchedule(t, false)
+
+		ruleInfo, _ := sch.registry.getOrCreateInfo(context.Background(), rule.GetKey())
+		stopped := make(chan error)
+		go func() {
+			stopped <- sch.ruleRoutine(ruleInfo.ctx, rule.GetKey(), ruleInfo.evalCh, ruleInfo.updateCh)
+		}()
+
+		sch.pauseAlertRule(rule.GetKey())
+		require.NoError(t, waitForErrChannel(t, stopped))
+		require.False(t, sch.registry.exists(rule.GetKey()))
+		require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+
+		require.Eventuallyf(t, func() bool {
+			return fakeAM.AlertsCount() == 1
+		}, 20*time.Second, 200*time.Millisecond, "Alertmanager was expected to receive the resolved alert")
+		require.Equal(t, sch.clock.Now().UTC(), time.Time(fakeAM.Alerts()[0].EndsAt).UTC())
+	})
+
+	t.Run("the alerts of a paused rule are kept and re-sent", func(t *testing.T) {
+		sch, ruleStore, rule, fakeAM := createSchedule(t, true)
+
+		evalChan := make(chan *evaluation)
+		evalAppliedChan := make(chan time.Time)
+		updateChan := make(chan struct{})
+		sch.evalAppliedFunc = func(key models.AlertRuleKey, t time.Time) {
+			evalAppliedChan <- t
+		}
+		go func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, updateChan)
+		}()
+
+		var wg sync.WaitGroup
+		ruleStore.Hook = func(cmd interface{}) error {
+			if _, ok := cmd.(models.GetAlertRuleByUIDQuery); ok {
+				wg.Done()
+			}
+			return nil
+		}
+		update := func(r models.AlertRule) {
+			ruleStore.PutRule(context.Background(), &r)
+			wg.Add(1)
+			updateChan <- struct{}{}
+			wg.Wait()
+		}
+
+		update(*rule)
+		pausedRule := *rule
+		pausedRule.Version++
+		pausedRule.IsPaused = true
+		update(pausedRule)
+
+		scheduledAt := sch.clock.Now().Add(time.Hour)
+		evalChan <- &evaluation{scheduledAt: scheduledAt, version: pausedRule.Version}
+		waitForTimeChannel(t, evalAppliedChan)
+
+		states := sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		require.Len(t, states, 1)
+		require.Equal(t, eval.Alerting, states[0].State)
+		require.Equal(t, scheduledAt, states[0].LastSentAt)
+		require.True(t, states[0].EndsAt.After(scheduledAt))
+
+		require.Eventuallyf(t, func() bool {
+			return fakeAM.AlertsCount() == 1
+		}, 20*time.Second, 200*time.Millisecond, "Alertmanager was expected to receive the kept alert")
+		require.Equal(t, states[0].EndsAt.UTC(), time.Time(fakeAM.Alerts()[0].EndsAt).UTC())
+
+		// the alerts are not re-sent before the resend delay
+		evalChan <- &evaluation{scheduledAt: scheduledAt.Add(time.Second), version: pausedRule.Version}
+		waitForTimeChannel(t, evalAppliedChan)
+		require.Equal(t, scheduledAt, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)[0].LastSentAt)
+
+		// and are kept when the rule is resumed
+		resumedRule := pausedRule
+		resumedRule.Version++
+		resumedRule.IsPaused = false
+		update(resumedRule)
+		require.Never(t, func() bool {
+			return len(sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)) == 0
+		}, time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("the alerts of a rule that is paused and changed by the same update are resolved", func(t *testing.T) {
+		sch, ruleStore, rule, _ := createSchedule(t, true)
+
+		updateChan := make(chan struct{})
+		go func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), make(chan *evaluation), updateChan)
+		}()
+
+		var wg sync.WaitGroup
+		ruleStore.Hook = func(cmd interface{}) error {
+			if _, ok := cmd.(models.GetAlertRuleByUIDQuery); ok {
+				wg.Done()
+			}
+			return nil
+		}
+		update := func(r models.AlertRule) {
+			ruleStore.PutRule(context.Background(), &r)
+			wg.Add(1)
+			updateChan <- struct{}{}
+			wg.Wait()
+		}
+
+		update(*rule)
+		require.Len(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID), 1)
+
+		changedRule := *rule
+		changedRule.Version++
+		changedRule.IsPaused = true
+		changedRule.Condition = "B"
+		update(changedRule)
+		require.Eventually(t, func() bool {
+			return len(sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)) == 0
+		}, time.Second, 100*time.Millisecond)
+	})
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -0,0 +1,43 @@
+package schedule
+
+// LOGZ.IO GRAFANA CHANGE :: Recording rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+var errNoRecordingWriter = errors.New("recording rules are disabled: no remote-write endpoint is configured")
+
+// evaluateRecordingRule executes the queries and expressions of a recording rule and writes the series of the
+// recorded one as its metric.
+func (sch *schedule) evaluateRecordingRule(ctx context.Context, r *models.AlertRule, now time.Time) error {
+	if sch.recordingWriter == nil {
+		return errNoRecordingWriter
+	}
+	// LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+	// the samples are written at the time of the data they are computed from, as with Prometheus rule groups
+	now = now.Add(-r.QueryOffset)
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	resp, err := sch.evaluator.QueriesAndExpressionsEval(r.OrgID, r.Data, now, sch.expressionService, http.Header{})
+	if err != nil {
+		return err
+	}
+	res, ok := resp.Responses[r.Record.From]
+	if !ok {
+		return fmt.Errorf("no result for the recorded query or expression %s", r.Record.From)
+	}
+	if res.Error != nil {
+		return fmt.Errorf("failed to execute the recorded query or expression %s: %w", r.Record.From, res.Error)
+	}
+
+	return sch.recordingWriter.Write(ctx, r.Record.Metric, now, res.Frames, r.Labels)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -0,0 +1,97 @@
+package schedule
+
+// LOGZ.IO GRAFANA CHANGE :: Rule evaluation sharding across HA scheduler instances
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// shardRingTokens is the number of points of each member on the hash ring. More points spread the rules more evenly
+// between the members, and move fewer rules when a member joins or leaves.
+const shardRingTokens = 128
+
+// ShardMembership is the membership of the live instances that share the evaluation of the alert rules.
+type ShardMembership interface {
+	// Self returns the name of this instance.
+	Self() string
+	// Members returns the names of the live instances, including this one.
+	Members() []string
+}
+
+// shardRing assigns every alert rule to a single member by consistent hashing of its key, so that a member joining or
+// leaving only moves the rules it owns or takes over.
+type shardRing struct {
+	self    string
+	tokens  []uint64
+	members map[uint64]string
+}
+
+func newShardRing(self string, members []string) *shardRing {
+	r := &shardRing{self: self, members: make(map[uint64]string, len(members)*shardRingTokens)}
+	for _, member := range members {
+		for i := 0; i < shardRingTokens; i++ {
+			token := shardHash(member + "/" + strconv.Itoa(i))
+			// on collision the member that sorts first keeps the token, so that every member builds the same ring
+			if owner, ok := r.members[token]; ok && owner < member {
+				continue
+			}
+			r.members[token] = member
+		}
+	}
+	r.tokens = make([]uint64, 0, len(r.members))
+	for token := range r.members {
+		r.tokens = append(r.tokens, token)
+	}
+	sort.Slice(r.tokens, func(i, j int) bool { return r.tokens[i] < r.tokens[j] })
+	return r
+}
+
+// owner returns the member that evaluates the rule, which is the member of the first token following the hash of the
+// rule key on the ring.
+func (r *shardRing) owner(key models.AlertRuleKey) string {
+	if len(r.tokens) == 0 {
+		return r.self
+	}
+	h := shardHash(strconv.FormatInt(key.OrgID, 10) + "/" + key.UID)
+	i := sort.Search(len(r.tokens), func(i int) bool { return r.tokens[i] >= h })
+	if i == len(r.tokens) {
+		i = 0
+	}
+	return r.members[r.tokens[i]]
+}
+
+// owns returns true if this instance evaluates the rule. A nil ring owns every rule.
+func (r *shardRing) owns(key models.AlertRuleKey) bool {
+	return r == nil || r.owner(key) == r.self
+}
+
+func shardHash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// shardRing returns the ring of the current members, or nil if the rules are not sharded.
+func (sch *schedule) shardRing() *shardRing {
+	if sch.shardMembership == nil {
+		return nil
+	}
+	self := sch.shardMembership.Self()
+	members := sch.shardMembership.Members()
+	found := false
+	for _, member := range members {
+		found = found || member == self
+	}
+	// this instance keeps evaluating its share of the rules while it is not a member yet
+	if !found {
+		members = append(members, self)
+	}
+	sch.metrics.ShardMembers.Set(float64(len(members)))
+	return newShardRing(self, members)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -0,0 +1,127 @@
+package schedule
+
+// LOGZ.IO GRAFANA CHANGE :: Rule evaluation sharding across HA scheduler instances
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func TestShardRing(t *testing.T) {
+	keys := make([]models.AlertRuleKey, 0, 3000)
+	for i := 0; i < cap(keys); i++ {
+		keys = append(keys, models.AlertRuleKey{OrgID: int64(i%3 + 1), UID: "rule-" + strconv.Itoa(i)})
+	}
+	members := []string{"a", "b", "c"}
+
+	t.Run("every rule is owned by exactly one member", func(t *testing.T) {
+		rings := make([]*shardRing, 0, len(members))
+		for _, m := range members {
+			rings = append(rings, newShardRing(m, members))
+		}
+		owned := make(map[string]int, len(members))
+		for _, key := range keys {
+			owners := 0
+			for _, r := range rings {
+				if r.owns(key) {
+					owners++
+				}
+			}
+			require.Equal(t, 1, owners)
+			owned[rings[0].owner(key)]++
+		}
+		// the rules are spread between the members, even if not evenly
+		for _, m := range members {
+			require.Greater(t, owned[m], len(keys)/len(members)/2)
+		}
+	})
+
+	t.Run("only the rules of a leaving member move", func(t *testing.T) {
+		before := newShardRing("a", members)
+		after := newShardRing("a", []string{"a", "b"})
+		for _, key := range keys {
+			if owner := before.owner(key); owner != "c" {
+				require.Equal(t, owner, after.owner(key))
+			}
+		}
+	})
+
+	t.Run("nil ring owns every rule", func(t *testing.T) {
+		var r *shardRing
+		require.True(t, r.owns(keys[0]))
+	})
+}
+
+type fakeShardMembership struct {
+	self    string
+	members []string
+}
+
+func (f fakeShardMembership) Self() string      { return f.self }
+func (f fakeShardMembership) Members() []string { return f.members }
+
+func TestSchedule_sharding(t *testing.T) {
+	ruleStore := store.NewFakeRuleStore(t)
+	instanceStore := &store.FakeInstanceStore{}
+	sch, mockedClock := setupScheduler(t, ruleStore, instanceStore, store.NewFakeAdminConfigStore(t), nil)
+	membership := fakeShardMembership{self: "a", members: []string{"a", "b"}}
+	sch.shardMembership = membership
+	evalAppliedCh := make(chan models.AlertRuleKey, 10)
+	sch.evalAppliedFunc = func(key models.AlertRuleKey, _ time.Time) {
+		evalAppliedCh <- key
+	}
+
+	ring := newShardRing(membership.self, membership.members)
+	owned := map[models.AlertRuleKey]struct{}{}
+	notOwned := 0
+	for len(owned) == 0 || notOwned == 0 {
+		rule := CreateTestAlertRule(t, ruleStore, 1, 1, eval.Alerting)
+		if ring.owns(rule.GetKey()) {
+			owned[rule.GetKey()] = struct{}{}
+		} else {
+			notOwned++
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		_ = sch.schedulePeriodic(ctx)
+	}()
+	mockedClock.Add(time.Second)
+
+	for range owned {
+		select {
+		case key := <-evalAppliedCh:
+			require.Contains(t, owned, key, "only the rules owned by this instance are evaluated")
+		case <-time.After(5 * time.Second):
+			t.Fatal("owned rules were not evaluated")
+		}
+	}
+
+	t.Run("the owned rules manage their annotations and instances", func(t *testing.T) {
+		saved := map[models.AlertRuleKey]struct{}{}
+		for _, op := range instanceStore.RecordedOps {
+			if cmd, ok := op.(models.SaveAlertInstanceCommand); ok {
+				saved[models.AlertRuleKey{OrgID: cmd.RuleOrgID, UID: cmd.RuleUID}] = struct{}{}
+			}
+		}
+		require.Equal(t, owned, saved)
+
+		annotationsRepo := annotations.GetRepository().(*store.FakeAnnotationsRepo)
+		require.Eventually(t, func() bool {
+			return annotationsRepo.Len() == len(owned)
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer" // LOGZ.IO GRAFANA CHANGE :: Recording rules
 
 	"github.com/benbjohnson/clock"
 	"golang.org/x/sync/errgroup"
@@ -100,6 +101,13 @@ type schedule struct {
 	adminConfigPollInterval time.Duration
 	disabledOrgs            map[int64]struct{}
 	minRuleInterval         time.Duration
+
+	recordingWriter writer.Writer   // LOGZ.IO GRAFANA CHANGE :: Recording rules
+	shardMembership ShardMembership // LOGZ.IO GRAFANA CHANGE :: Rule evaluation sharding across HA scheduler instances
+	// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+	pausedRules          pausedRules
+	keepPausedRuleStates bool
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -120,6 +128,17 @@ type SchedulerCfg struct {
 	AdminConfigPollInterval time.Duration
 	DisabledOrgs            map[int64]struct{}
 	MinRuleInterval         time.Duration
+	// RecordingWriter writes the series of the recording rules. Recording rules fail to evaluate when it is nil.
+	RecordingWriter writer.Writer // LOGZ.IO GRAFANA CHANGE :: Recording rules
+	// LOGZ.IO GRAFANA CHANGE :: Rule evaluation sharding across HA scheduler instances
+	// ShardMembership is the membership of the instances that share the evaluation of the rules. Every rule is
+	// evaluated by this instance when it is nil.
+	ShardMembership ShardMembership
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+	// KeepPausedRuleStates keeps the alerts of the paused rules firing, by re-sending them, instead of resolving them.
+	KeepPausedRuleStates bool
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 // NewScheduler returns a new schedule.
@@ -151,6 +170,9 @@ func NewScheduler(cfg SchedulerCfg, expressionService *expr.Service, appURL *url
 		adminConfigPollInterval: cfg.AdminConfigPollInterval,
 		disabledOrgs:            cfg.DisabledOrgs,
 		minRuleInterval:         cfg.MinRuleInterval,
+		recordingWriter:         cfg.RecordingWriter,      // LOGZ.IO GRAFANA CHANGE :: Recording rules
+		shardMembership:         cfg.ShardMembership,      // LOGZ.IO GRAFANA CHANGE :: Rule evaluation sharding across HA scheduler instances
+		keepPausedRuleStates:    cfg.KeepPausedRuleStates, // LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
 	}
 	return &sch
 }
@@ -392,6 +414,13 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 			// so, at the end, the remaining registered alert rules are the deleted ones
 			registeredDefinitions := sch.registry.keyMap()
 
+			// LOGZ.IO GRAFANA CHANGE :: Rule evaluation sharding across HA scheduler instances
+			// The ring is rebuilt on every tick, so that the rules are rebalanced as soon as an instance joins or leaves.
+			// The routines of the rules owned by other instances keep running, so that they are not treated as deleted.
+			ring := sch.shardRing()
+			ownedRules := 0
+			// LOGZ.IO GRAFANA CHANGE :: end
+
 			type readyToRunItem struct {
 				key      models.AlertRuleKey
 				ruleInfo *alertRuleInfo
@@ -402,6 +431,16 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 			for _, item := range alertRules {
 				key := item.GetKey()
 				itemVersion := item.Version
+
+				// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+				// paused rules are not deleted, but their routines are stopped until they are resumed unless their
+				// alerts are kept, in which case the routines re-send the alerts instead of evaluating the rules
+				if item.IsPaused && !sch.keepPausedRuleStates {
+					sch.pauseAlertRule(key)
+					delete(registeredDefinitions, key)
+					continue
+				}
+				// LOGZ.IO GRAFANA CHANGE :: end
 				ruleInfo, newRoutine := sch.registry.getOrCreateInfo(ctx, key)
 
 				// enforce minimum evaluation interval
@@ -425,8 +464,15 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 					continue
 				}
 
+				// LOGZ.IO GRAFANA CHANGE :: Rule evaluation sharding across HA scheduler instances
+				owned := ring.owns(key)
+				if owned {
+					ownedRules++
+				}
+				// LOGZ.IO GRAFANA CHANGE :: end
+
 				itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
-				if item.IntervalSeconds != 0 && tickNum%itemFrequency == 0 {
+				if owned && item.IntervalSeconds != 0 && tickNum%itemFrequency == 0 { // LOGZ.IO GRAFANA CHANGE :: Rule evaluation sharding across HA scheduler instances
 					readyToRun = append(readyToRun, readyToRunItem{key: key, ruleInfo: ruleInfo, version: itemVersion})
 				}
 
@@ -434,6 +480,8 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 				delete(registeredDefinitions, key)
 			}
 
+			sch.metrics.OwnedRules.Set(float64(ownedRules)) // LOGZ.IO GRAFANA CHANGE :: Rule evaluation sharding across HA scheduler instances
+
 			var step int64 = 0
 			if len(readyToRun) > 0 {
 				step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
@@ -478,6 +526,11 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 	logger := sch.log.New("uid", key.UID, "org", key.OrgID)
 	logger.Debug("alert rule routine started")
 
+	// LOGZ.IO GRAFANA CHANGE :: Rule evaluation sharding across HA scheduler instances
+	// only the instance that owns the rule evaluates it, and so it manages the annotations and instances of the rule
+	grafanaCtx = context.WithValue(grafanaCtx, state.ShouldManageAnnotationsAndInstancesContextKey, true)
+	// LOGZ.IO GRAFANA CHANGE :: end
+
 	orgID := fmt.Sprint(key.OrgID)
 	evalTotal := sch.metrics.EvalTotal.WithLabelValues(orgID)
 	evalDuration := sch.metrics.EvalDuration.WithLabelValues(orgID)
@@ -541,7 +594,7 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 			logger.Error("failed to fetch alert rule", "err", err)
 			return nil, err
 		}
-		if oldRule != nil && oldRule.Version < q.Result.Version {
+		if oldRule != nil && oldRule.Version < q.Result.Version && !sch.keepsStatesOnUpdate(oldRule, q.Result) { // LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
 			clearState()
 		}
 		return q.Result, nil
@@ -551,6 +604,29 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 		logger := logger.New("version", r.Version, "attempt", attempt, "now", e.scheduledAt)
 		start := sch.clock.Now()
 
+		// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+		if r.IsPaused {
+			notify(sch.pausedRuleAlerts(r, e.scheduledAt), logger)
+			return nil
+		}
+		// LOGZ.IO GRAFANA CHANGE :: end
+
+		// LOGZ.IO GRAFANA CHANGE :: Recording rules
+		if r.IsRecordingRule() {
+			err := sch.evaluateRecordingRule(ctx, r, start)
+			dur := sch.clock.Now().Sub(start)
+			evalTotal.Inc()
+			evalDuration.Observe(dur.Seconds())
+			if err != nil {
+				evalTotalFailures.Inc()
+				logger.Error("failed to evaluate recording rule", "duration", dur, "err", err)
+				return err
+			}
+			logger.Debug("recording rule evaluated", "metric", r.Record.Metric, "duration", dur)
+			return nil
+		}
+		// LOGZ.IO GRAFANA CHANGE :: end
+
 		condition := models.Condition{
 			Condition: r.Condition,
 			OrgID:     r.OrgID,
@@ -561,7 +637,7 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 			LogzioHeaders:     http.Header{},
 			DsOverrideByDsUid: map[string]models.EvaluationDatasourceOverride{},
 		}
-		results, err := sch.evaluator.ConditionEval(&condition, start, sch.expressionService, logzioEvalContext)
+		results, err := eval.ConditionEvalWithQueryOffset(sch.evaluator, &condition, start, r.QueryOffset, sch.expressionService, logzioEvalContext) // LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
 		// LOGZ.IO GRAFANA CHANGE :: end
 		dur := sch.clock.Now().Sub(start)
 		evalTotal.Inc()
@@ -648,6 +724,12 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 				}
 			}()
 		case <-grafanaCtx.Done():
+			// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+			if sch.stopPausedRule(key, clearState) {
+				logger.Debug("stopping alert rule routine of paused rule")
+				return nil
+			}
+			// LOGZ.IO GRAFANA CHANGE :: end
 			clearState()
 			logger.Debug("stopping alert rule routine")
 			return nil
@@ -104,7 +104,11 @@ func (c *cache) expandRuleLabelsAndAnnotations(ctx context.Context, alertRule *n
 
 		return expanded
 	}
-	return expand(alertRule.Labels), expand(alertRule.Annotations)
+	// LOGZ.IO GRAFANA CHANGE :: Dynamic labels and annotations from query results
+	expandedLabels := expand(alertRule.Labels)
+	dropEmptyLabels(expandedLabels)
+	return expandedLabels, expand(alertRule.Annotations)
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 func (c *cache) set(entry *State) {
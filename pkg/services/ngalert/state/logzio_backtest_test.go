@@ -0,0 +1,53 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestProcessEvalResultsAt(t *testing.T) {
+	st := NewIsolatedManager(log.New("test"), nil, nil)
+	ctx := WithoutAnnotationsAndInstances(context.Background())
+	rule := &ngModels.AlertRule{OrgID: 1, UID: "rule", IntervalSeconds: 10}
+	start := time.Unix(1000, 0)
+
+	evaluate := func(step int, series ...string) {
+		evalTime := start.Add(time.Duration(step) * 10 * time.Second)
+		var results eval.Results
+		for _, s := range series {
+			results = append(results, eval.Result{Instance: data.Labels{"series": s}, State: eval.Alerting, EvaluatedAt: evalTime})
+		}
+		st.ProcessEvalResultsAt(ctx, rule, results, evalTime)
+	}
+	seriesOf := func() map[string]time.Time {
+		series := make(map[string]time.Time)
+		for _, s := range st.GetStatesForRuleUID(1, "rule") {
+			series[s.Labels["series"]] = s.StartsAt
+		}
+		return series
+	}
+
+	evaluate(0, "a", "b")
+	evaluate(1, "a", "b")
+
+	// the evaluations of the past do not make the states stale relative to the current time
+	evaluate(2, "a")
+	evaluate(3, "a")
+	require.Equal(t, map[string]time.Time{"a": start, "b": start}, seriesOf())
+
+	// the series is stale after two intervals without results
+	evaluate(4, "a")
+	require.Equal(t, map[string]time.Time{"a": start}, seriesOf())
+
+	// and starts firing again once it is back
+	evaluate(5, "a", "b")
+	require.Equal(t, map[string]time.Time{"a": start, "b": start.Add(50 * time.Second)}, seriesOf())
+}
@@ -0,0 +1,50 @@
+package state
+
+// LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/image"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// WithImageService sets the service that takes the screenshots of the panels of the rules when their alerts fire.
+func WithImageService(images image.ImageService) ManagerOption {
+	return func(st *Manager) {
+		st.images = images
+	}
+}
+
+// maybeTakeImage takes the screenshot of the panel of the rule when the state starts alerting, or when it is alerting
+// without a screenshot because the previous attempt failed. The screenshot is kept until the state alerts again so that
+// the resolved notification shows the panel at the time the alert fired.
+func (st *Manager) maybeTakeImage(ctx context.Context, alertRule *ngModels.AlertRule, state *State, oldState eval.State) {
+	if st.images == nil || state.State != eval.Alerting {
+		return
+	}
+	if oldState == eval.Alerting && state.Image != nil {
+		return
+	}
+
+	img, err := st.images.NewImage(ctx, alertRule, state.LastEvaluationTime)
+	if err != nil {
+		if !errors.Is(err, image.ErrNoPanel) {
+			st.log.Warn("failed to take the screenshot of the panel of the rule", "orgID", alertRule.OrgID, "alertRuleUID", alertRule.UID, "err", err)
+		}
+		state.Image = nil
+		return
+	}
+	state.Image = img
+}
+
+// forgetImages drops the screenshots kept for the rule, once its states are removed.
+func (st *Manager) forgetImages(orgID int64, ruleUID string) {
+	if st.images != nil {
+		st.images.Forget(ngModels.AlertRuleKey{OrgID: orgID, UID: ruleUID})
+	}
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -0,0 +1,61 @@
+package state
+
+// LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// WithInstanceLimit sets the maximum number of alert instances of a rule, by default and for the orgs of byOrg. Zero
+// is unlimited.
+func WithInstanceLimit(limit int, byOrg map[int64]int) ManagerOption {
+	return func(st *Manager) {
+		st.instanceLimit = limit
+		st.instanceLimitByOrg = byOrg
+	}
+}
+
+// instanceLimitOf returns the maximum number of alert instances of the rule, which the rule can override with its
+// MaxInstances.
+func (st *Manager) instanceLimitOf(alertRule *ngModels.AlertRule) int {
+	if alertRule.MaxInstances != nil {
+		return int(*alertRule.MaxInstances)
+	}
+	if orgLimit, ok := st.instanceLimitByOrg[alertRule.OrgID]; ok {
+		return orgLimit
+	}
+	return st.instanceLimit
+}
+
+// limitInstances replaces the results of the rule with a single error result when they exceed the maximum number of
+// alert instances of the rule, so that a rule grouping by a high-cardinality term does not create every instance.
+// The rule is returned with an error state of ngModels.ErrorErrState so that it goes to the Error state.
+func (st *Manager) limitInstances(alertRule *ngModels.AlertRule, results eval.Results) (*ngModels.AlertRule, eval.Results) {
+	limit := st.instanceLimitOf(alertRule)
+	if limit <= 0 || len(results) <= limit {
+		return alertRule, results
+	}
+
+	st.log.Warn("rule exceeded the maximum number of alert instances", "orgID", alertRule.OrgID, "alertRuleUID", alertRule.UID,
+		"instances", len(results), "limit", limit)
+	if st.metrics != nil {
+		st.metrics.InstanceLimitExceeded.WithLabelValues(fmt.Sprint(alertRule.OrgID)).Inc()
+	}
+
+	limitedRule := *alertRule
+	limitedRule.ExecErrState = ngModels.ErrorErrState
+	return &limitedRule, eval.Results{{
+		Instance:           data.Labels{},
+		State:              eval.Error,
+		Error:              fmt.Errorf("the query returned %d series, more than the maximum of %d alert instances of the rule", len(results), limit),
+		EvaluatedAt:        results[0].EvaluatedAt,
+		EvaluationDuration: results[0].EvaluationDuration,
+	}}
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -0,0 +1,60 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestInstanceLimitOf(t *testing.T) {
+	st := &Manager{log: log.New("test")}
+	WithInstanceLimit(100, map[int64]int{2: 10})(st)
+
+	require.Equal(t, 100, st.instanceLimitOf(&ngModels.AlertRule{OrgID: 1}))
+	require.Equal(t, 10, st.instanceLimitOf(&ngModels.AlertRule{OrgID: 2}))
+	maxInstances := func(limit int64) *int64 { return &limit }
+	require.Equal(t, 5, st.instanceLimitOf(&ngModels.AlertRule{OrgID: 2, MaxInstances: maxInstances(5)}))
+	require.Equal(t, 0, st.instanceLimitOf(&ngModels.AlertRule{OrgID: 1, MaxInstances: maxInstances(0)}))
+}
+
+func TestLimitInstances(t *testing.T) {
+	m := metrics.NewNGAlert(prometheus.NewRegistry()).GetStateMetrics()
+	st := &Manager{log: log.New("test"), metrics: m}
+	WithInstanceLimit(2, nil)(st)
+
+	now := time.Unix(1000, 0)
+	results := func(n int) eval.Results {
+		var r eval.Results
+		for i := 0; i < n; i++ {
+			r = append(r, eval.Result{Instance: data.Labels{"term": string(rune('a' + i))}, State: eval.Alerting, EvaluatedAt: now})
+		}
+		return r
+	}
+	rule := &ngModels.AlertRule{OrgID: 1, UID: "rule", ExecErrState: ngModels.AlertingErrState}
+
+	t.Run("results within the limit are kept", func(t *testing.T) {
+		limitedRule, limited := st.limitInstances(rule, results(2))
+		require.Same(t, rule, limitedRule)
+		require.Len(t, limited, 2)
+	})
+
+	t.Run("results over the limit are replaced with an error", func(t *testing.T) {
+		limitedRule, limited := st.limitInstances(rule, results(3))
+		require.Equal(t, ngModels.ErrorErrState, limitedRule.ExecErrState)
+		require.Equal(t, ngModels.AlertingErrState, rule.ExecErrState)
+		require.Len(t, limited, 1)
+		require.Equal(t, eval.Error, limited[0].State)
+		require.Equal(t, now, limited[0].EvaluatedAt)
+		require.EqualError(t, limited[0].Error, "the query returned 3 series, more than the maximum of 2 alert instances of the rule")
+		require.Equal(t, 1.0, testutil.ToFloat64(m.InstanceLimitExceeded.WithLabelValues("1")))
+	})
+}
@@ -0,0 +1,104 @@
+package state
+
+// LOGZ.IO GRAFANA CHANGE :: Dry-run processing of evaluation results
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// Transition describes how the state of a single alert instance changed while processing evaluation results.
+type Transition struct {
+	State         *State
+	PreviousState eval.State
+}
+
+// Changed returns true if the processing moved the alert instance to a different state.
+func (t Transition) Changed() bool {
+	return t.PreviousState != t.State.State
+}
+
+// NewIsolatedManager creates a Manager whose cache holds copies of the given states. The isolated manager
+// does not run any background routine and has no instance store or database, so it must only be used with
+// contexts that disable the management of annotations and instances (see WithoutAnnotationsAndInstances).
+func NewIsolatedManager(logger log.Logger, externalURL *url.URL, states []*State) *Manager {
+	manager := &Manager{
+		cache:       newCache(logger, nil, externalURL),
+		quit:        make(chan struct{}),
+		ResendDelay: ResendDelay,
+		log:         logger,
+	}
+	for _, s := range states {
+		manager.set(s.copy())
+	}
+	return manager
+}
+
+// IsolatedCopyForRule creates an isolated manager seeded with copies of the current states of the given rule, read
+// from the shared state store when st has one. Processing evaluation results on the copy never modifies the states
+// of st, and creating the copy does not modify them either.
+func (st *Manager) IsolatedCopyForRule(ctx context.Context, orgID int64, alertRuleUID string) *Manager {
+	states, _, ok := st.readSharedRuleStates(ctx, orgID, alertRuleUID)
+	if !ok {
+		states = st.GetStatesForRuleUID(orgID, alertRuleUID)
+	}
+	isolated := NewIsolatedManager(st.log, st.cache.externalURL, states)
+	isolated.ResendDelay = st.ResendDelay
+	return isolated
+}
+
+// WithoutAnnotationsAndInstances returns a context that prevents the manager from writing annotations and alert instances.
+func WithoutAnnotationsAndInstances(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ShouldManageAnnotationsAndInstancesContextKey, false)
+}
+
+// ProcessEvalResultsWithTransitions processes the evaluation results like ProcessEvalResults and reports the state
+// each of the processed alert instances was in before the processing.
+func (st *Manager) ProcessEvalResultsWithTransitions(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results) []Transition {
+	saveSharedRuleStates := st.loadSharedRuleStates(ctx, alertRule.OrgID, alertRule.UID)
+	defer saveSharedRuleStates()
+
+	previousStates := make(map[string]eval.State)
+	for _, s := range st.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID) {
+		previousStates[s.CacheId] = s.State
+	}
+
+	processedStates := st.processEvalResults(ctx, alertRule, results)
+	transitions := make([]Transition, 0, len(processedStates))
+	for _, s := range processedStates {
+		// alert instances that are not yet known to the manager start in the Normal state
+		transitions = append(transitions, Transition{State: s, PreviousState: previousStates[s.CacheId]})
+	}
+	return transitions
+}
+
+// copy returns a deep copy of the state so that it can be modified independently of the original.
+func (a *State) copy() *State {
+	c := *a
+	c.Labels = a.Labels.Copy()
+	if a.Annotations != nil {
+		c.Annotations = make(map[string]string, len(a.Annotations))
+		for k, v := range a.Annotations {
+			c.Annotations[k] = v
+		}
+	}
+	if a.Results != nil {
+		c.Results = make([]Evaluation, len(a.Results))
+		for i, r := range a.Results {
+			c.Results[i] = r
+			if r.Values != nil {
+				c.Results[i].Values = make(map[string]*float64, len(r.Values))
+				for k, v := range r.Values {
+					c.Results[i].Values[k] = v
+				}
+			}
+		}
+	}
+	return &c
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -0,0 +1,32 @@
+package state
+
+// LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+
+import (
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// resultKeepFiring keeps an alerting state alerting when its condition cleared less than the keep firing for duration
+// of its rule ago, and returns false once the state should become normal.
+func (a *State) resultKeepFiring(alertRule *ngModels.AlertRule, result eval.Result) bool {
+	if a.State != eval.Alerting || alertRule.KeepFiringFor <= 0 {
+		return false
+	}
+	if a.KeptFiringSince.IsZero() {
+		a.KeptFiringSince = result.EvaluatedAt
+	}
+	if result.EvaluatedAt.Sub(a.KeptFiringSince) >= alertRule.KeepFiringFor {
+		return false
+	}
+	a.Error = nil
+	a.setEndsAt(alertRule, result)
+	return true
+}
+
+// IsKeptFiring returns true if the state is alerting only because it is in the keep firing window of its rule.
+func (a *State) IsKeptFiring() bool {
+	return a.State == eval.Alerting && !a.KeptFiringSince.IsZero()
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
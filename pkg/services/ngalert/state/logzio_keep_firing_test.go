@@ -0,0 +1,38 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestResultKeepFiring(t *testing.T) {
+	now := time.Unix(1000, 0)
+	rule := &ngModels.AlertRule{IntervalSeconds: 10, KeepFiringFor: 30 * time.Second}
+
+	t.Run("an alerting state keeps firing during the window", func(t *testing.T) {
+		s := &State{State: eval.Alerting, StartsAt: now.Add(-time.Minute)}
+		require.True(t, s.resultKeepFiring(rule, eval.Result{State: eval.Normal, EvaluatedAt: now}))
+		require.Equal(t, now, s.KeptFiringSince)
+		require.True(t, s.IsKeptFiring())
+		require.True(t, s.EndsAt.After(now))
+
+		require.True(t, s.resultKeepFiring(rule, eval.Result{State: eval.Normal, EvaluatedAt: now.Add(20 * time.Second)}))
+		require.Equal(t, now, s.KeptFiringSince)
+
+		require.False(t, s.resultKeepFiring(rule, eval.Result{State: eval.Normal, EvaluatedAt: now.Add(30 * time.Second)}))
+	})
+
+	t.Run("other states and rules without a window do not keep firing", func(t *testing.T) {
+		s := &State{State: eval.Pending}
+		require.False(t, s.resultKeepFiring(rule, eval.Result{State: eval.Normal, EvaluatedAt: now}))
+		require.False(t, s.IsKeptFiring())
+
+		s = &State{State: eval.Alerting}
+		require.False(t, s.resultKeepFiring(&ngModels.AlertRule{IntervalSeconds: 10}, eval.Result{State: eval.Normal, EvaluatedAt: now}))
+	})
+}
@@ -0,0 +1,41 @@
+package state
+
+// LOGZ.IO GRAFANA CHANGE :: Dynamic labels and annotations from query results
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ValidateRuleTemplates returns an error if a label or annotation of the rule is not a valid template. The templates
+// are parsed with the same functions and variables ($labels, $values and $value) they are expanded with.
+func ValidateRuleTemplates(rule ngModels.AlertRule) error {
+	validate := func(kind string, templates map[string]string) error {
+		for k, v := range templates {
+			expander := newTemplateExpander(context.Background(), rule.Title, v, nil, time.Time{}, nil)
+			if err := expander.ParseTest(); err != nil {
+				return fmt.Errorf("%w: invalid template in %s %q: %s", ngModels.ErrAlertRuleFailedValidation, kind, k, err.Error())
+			}
+		}
+		return nil
+	}
+	if err := validate("label", rule.Labels); err != nil {
+		return err
+	}
+	return validate("annotation", rule.Annotations)
+}
+
+// dropEmptyLabels removes the labels whose template expanded to an empty value, as Prometheus does, so that a label
+// can be set only for some of the instances of a rule.
+func dropEmptyLabels(labels map[string]string) {
+	for k, v := range labels {
+		if v == "" {
+			delete(labels, k)
+		}
+	}
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -0,0 +1,71 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestValidateRuleTemplates(t *testing.T) {
+	t.Run("valid templates pass", func(t *testing.T) {
+		rule := ngModels.AlertRule{
+			Title:       "test",
+			Labels:      map[string]string{"severity": `{{ if gt $values.B.Value 90.0 }}critical{{ else }}warning{{ end }}`},
+			Annotations: map[string]string{"summary": `{{ $labels.instance }} is at {{ $value }}`},
+		}
+		require.NoError(t, ValidateRuleTemplates(rule))
+	})
+
+	t.Run("an invalid label template fails", func(t *testing.T) {
+		rule := ngModels.AlertRule{Title: "test", Labels: map[string]string{"severity": `{{ if $value }}critical`}}
+		err := ValidateRuleTemplates(rule)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, ngModels.ErrAlertRuleFailedValidation))
+		require.Contains(t, err.Error(), `label "severity"`)
+	})
+
+	t.Run("an unknown variable in an annotation fails", func(t *testing.T) {
+		rule := ngModels.AlertRule{Title: "test", Annotations: map[string]string{"summary": `{{ $foo }}`}}
+		err := ValidateRuleTemplates(rule)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `annotation "summary"`)
+	})
+}
+
+func TestDynamicRuleLabels(t *testing.T) {
+	c := newCache(log.New("test"), nil, nil)
+	rule := &ngModels.AlertRule{
+		OrgID: 1,
+		UID:   "rule",
+		Title: "test",
+		Labels: map[string]string{
+			"severity": `{{ if gt $values.B.Value 90.0 }}critical{{ else }}warning{{ end }}`,
+			"page":     `{{ if gt $values.B.Value 90.0 }}true{{ end }}`,
+		},
+	}
+	result := func(value float64) eval.Result {
+		return eval.Result{
+			Instance:    data.Labels{"host": "a"},
+			State:       eval.Alerting,
+			EvaluatedAt: time.Unix(1000, 0),
+			Values:      map[string]eval.NumberValueCapture{"B": {Var: "B", Value: ptr.Float64(value)}},
+		}
+	}
+
+	s := c.getOrCreate(context.Background(), rule, result(95))
+	require.Equal(t, "critical", s.Labels["severity"])
+	require.Equal(t, "true", s.Labels["page"])
+
+	s = c.getOrCreate(context.Background(), rule, result(50))
+	require.Equal(t, "warning", s.Labels["severity"])
+	require.NotContains(t, s.Labels, "page")
+}
@@ -0,0 +1,382 @@
+package state
+
+// LOGZ.IO GRAFANA CHANGE :: Shared alert state cache across HA peers
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/image"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	// ErrRuleStatesNotFound is returned by a SharedStateStore that holds no states of the requested rule.
+	ErrRuleStatesNotFound = errors.New("rule states not found")
+	// ErrRuleStatesConflict is returned by a SharedStateStore when the states of the rule were written or deleted by
+	// another peer since they were read.
+	ErrRuleStatesConflict = errors.New("rule states were changed concurrently")
+	// ErrRuleStatesLocked is returned by a SharedStateStore when the lock of the states of the rule is held by another
+	// peer for longer than the store waits for it.
+	ErrRuleStatesLocked = errors.New("rule states are locked")
+)
+
+const (
+	// defaultRuleStatesLockTTL is how long the lock of the states of a rule is held at most, e.g. when the peer that
+	// holds it stops before releasing it.
+	defaultRuleStatesLockTTL = 30 * time.Second
+	// defaultRuleStatesLockTimeout is how long a peer waits for the lock of the states of a rule.
+	defaultRuleStatesLockTimeout = 10 * time.Second
+	// ruleStatesLockRetryInterval is how often a peer that waits for the lock of the states of a rule tries again.
+	ruleStatesLockRetryInterval = 50 * time.Millisecond
+)
+
+// SharedStateStore keeps the current alert states of the rules outside of the process, so that every peer of an
+// HA cluster processes evaluation results based on the same state, whichever peer processed the previous results.
+type SharedStateStore interface {
+	// LockRuleStates acquires the lock of the states of the rule, so that a single peer at a time reads, processes and
+	// writes them. It returns ErrRuleStatesLocked if the lock is not acquired in time. The returned function releases
+	// the lock.
+	LockRuleStates(ctx context.Context, orgID int64, alertRuleUID string) (func(), error)
+	// GetRuleStates returns the states of all alert instances of the rule and their version, or ErrRuleStatesNotFound.
+	GetRuleStates(ctx context.Context, orgID int64, alertRuleUID string) ([]*State, int64, error)
+	// SetRuleStates replaces the states of all alert instances of the rule if they are still at the given version, the
+	// version of states that are not found being zero, and returns ErrRuleStatesConflict otherwise.
+	SetRuleStates(ctx context.Context, orgID int64, alertRuleUID string, states []*State, version int64) error
+	// DeleteRuleStates removes the states of all alert instances of the rule.
+	DeleteRuleStates(ctx context.Context, orgID int64, alertRuleUID string) error
+}
+
+// RemoteCacheStateStore is a SharedStateStore that keeps the states of each rule and their version under a single key
+// of the remote cache, and the lock of the states under another key. The remote cache cannot compare and set a key, so
+// the store checks the lock and the version right after reading them and a write can still win a very close race; the
+// lock makes these races rare and the version keeps the states of a rule from going back to an older version.
+type RemoteCacheStateStore struct {
+	cache       remotecache.CacheStorage
+	ttl         time.Duration
+	lockTTL     time.Duration
+	lockTimeout time.Duration
+}
+
+func NewRemoteCacheStateStore(cache remotecache.CacheStorage, ttl time.Duration) *RemoteCacheStateStore {
+	return &RemoteCacheStateStore{
+		cache:       cache,
+		ttl:         ttl,
+		lockTTL:     defaultRuleStatesLockTTL,
+		lockTimeout: defaultRuleStatesLockTimeout,
+	}
+}
+
+// sharedRuleStates is the encoded form of the states of a rule.
+type sharedRuleStates struct {
+	// Version is incremented by every write of the states.
+	Version int64
+	States  []sharedState
+}
+
+// sharedState is the encoded form of State. The error of the state is kept as its message only.
+type sharedState struct {
+	AlertRuleUID         string
+	OrgID                int64
+	CacheId              string
+	State                eval.State
+	Resolved             bool
+	Results              []Evaluation
+	LastEvaluationString string
+	StartsAt             time.Time
+	EndsAt               time.Time
+	LastEvaluationTime   time.Time
+	EvaluationDuration   time.Duration
+	LastSentAt           time.Time
+	Annotations          map[string]string
+	Labels               data.Labels
+	Error                string
+	KeptFiringSince      time.Time     // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+	Image                *image.Image  // LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+	EvictionDelay        time.Duration // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+}
+
+func ruleStatesCacheKey(orgID int64, alertRuleUID string) string {
+	return fmt.Sprintf("ngalert-rule-states-%d-%s", orgID, alertRuleUID)
+}
+
+func ruleStatesLockCacheKey(orgID int64, alertRuleUID string) string {
+	return fmt.Sprintf("ngalert-rule-states-lock-%d-%s", orgID, alertRuleUID)
+}
+
+func (s *RemoteCacheStateStore) LockRuleStates(ctx context.Context, orgID int64, alertRuleUID string) (func(), error) {
+	key := ruleStatesLockCacheKey(orgID, alertRuleUID)
+	token := util.GenerateShortUID()
+	timeout := time.NewTimer(s.lockTimeout)
+	defer timeout.Stop()
+
+	for {
+		acquired, err := s.tryLock(ctx, key, token)
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			return func() { s.unlock(key, token) }, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, ErrRuleStatesLocked
+		case <-time.After(ruleStatesLockRetryInterval):
+		}
+	}
+}
+
+// tryLock sets the lock to the token if no peer holds it, and reports whether the lock holds the token afterwards.
+func (s *RemoteCacheStateStore) tryLock(ctx context.Context, key string, token string) (bool, error) {
+	holder, err := s.cache.Get(ctx, key)
+	if err == nil {
+		return holder == token, nil
+	}
+	if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		return false, err
+	}
+
+	if err := s.cache.Set(ctx, key, token, s.lockTTL); err != nil {
+		return false, err
+	}
+	// another peer that found the lock free at the same time may have set it after us
+	holder, err = s.cache.Get(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	return holder == token, nil
+}
+
+// unlock releases the lock if it still holds the token, i.e. it did not expire and another peer did not take it over.
+// It does not use the context of the lock, so that the lock is released even if that context is done.
+func (s *RemoteCacheStateStore) unlock(key string, token string) {
+	ctx := context.Background()
+	if holder, err := s.cache.Get(ctx, key); err != nil || holder != token {
+		return
+	}
+	_ = s.cache.Delete(ctx, key)
+}
+
+// getSharedRuleStates returns the encoded states of the rule or ErrRuleStatesNotFound.
+func (s *RemoteCacheStateStore) getSharedRuleStates(ctx context.Context, orgID int64, alertRuleUID string) (sharedRuleStates, error) {
+	cached, err := s.cache.Get(ctx, ruleStatesCacheKey(orgID, alertRuleUID))
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return sharedRuleStates{}, ErrRuleStatesNotFound
+		}
+		return sharedRuleStates{}, err
+	}
+	raw, ok := cached.([]byte)
+	if !ok {
+		return sharedRuleStates{}, fmt.Errorf("unexpected type %T of the states of rule %s", cached, alertRuleUID)
+	}
+
+	var shared sharedRuleStates
+	if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&shared); err != nil {
+		return sharedRuleStates{}, fmt.Errorf("failed to decode the states of rule %s: %w", alertRuleUID, err)
+	}
+	return shared, nil
+}
+
+func (s *RemoteCacheStateStore) GetRuleStates(ctx context.Context, orgID int64, alertRuleUID string) ([]*State, int64, error) {
+	shared, err := s.getSharedRuleStates(ctx, orgID, alertRuleUID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	states := make([]*State, 0, len(shared.States))
+	for _, encoded := range shared.States {
+		state := &State{
+			AlertRuleUID:         encoded.AlertRuleUID,
+			OrgID:                encoded.OrgID,
+			CacheId:              encoded.CacheId,
+			State:                encoded.State,
+			Resolved:             encoded.Resolved,
+			Results:              encoded.Results,
+			LastEvaluationString: encoded.LastEvaluationString,
+			StartsAt:             encoded.StartsAt,
+			EndsAt:               encoded.EndsAt,
+			LastEvaluationTime:   encoded.LastEvaluationTime,
+			EvaluationDuration:   encoded.EvaluationDuration,
+			LastSentAt:           encoded.LastSentAt,
+			Annotations:          encoded.Annotations,
+			Labels:               encoded.Labels,
+			KeptFiringSince:      encoded.KeptFiringSince, // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+			Image:                encoded.Image,           // LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+			EvictionDelay:        encoded.EvictionDelay,   // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+		}
+		if encoded.Error != "" {
+			state.Error = errors.New(encoded.Error)
+		}
+		states = append(states, state)
+	}
+	return states, shared.Version, nil
+}
+
+func (s *RemoteCacheStateStore) SetRuleStates(ctx context.Context, orgID int64, alertRuleUID string, states []*State, version int64) error {
+	current, err := s.getSharedRuleStates(ctx, orgID, alertRuleUID)
+	if err != nil && !errors.Is(err, ErrRuleStatesNotFound) {
+		return err
+	}
+	if current.Version != version {
+		return fmt.Errorf("%w: rule %s is at version %d, expected %d", ErrRuleStatesConflict, alertRuleUID, current.Version, version)
+	}
+
+	shared := sharedRuleStates{Version: version + 1, States: make([]sharedState, 0, len(states))}
+	for _, state := range states {
+		encoded := sharedState{
+			AlertRuleUID:         state.AlertRuleUID,
+			OrgID:                state.OrgID,
+			CacheId:              state.CacheId,
+			State:                state.State,
+			Resolved:             state.Resolved,
+			Results:              state.Results,
+			LastEvaluationString: state.LastEvaluationString,
+			StartsAt:             state.StartsAt,
+			EndsAt:               state.EndsAt,
+			LastEvaluationTime:   state.LastEvaluationTime,
+			EvaluationDuration:   state.EvaluationDuration,
+			LastSentAt:           state.LastSentAt,
+			Annotations:          state.Annotations,
+			Labels:               state.Labels,
+			KeptFiringSince:      state.KeptFiringSince, // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+			Image:                state.Image,           // LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+			EvictionDelay:        state.EvictionDelay,   // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+		}
+		if state.Error != nil {
+			encoded.Error = state.Error.Error()
+		}
+		shared.States = append(shared.States, encoded)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(shared); err != nil {
+		return fmt.Errorf("failed to encode the states of rule %s: %w", alertRuleUID, err)
+	}
+	return s.cache.Set(ctx, ruleStatesCacheKey(orgID, alertRuleUID), buf.Bytes(), s.ttl)
+}
+
+func (s *RemoteCacheStateStore) DeleteRuleStates(ctx context.Context, orgID int64, alertRuleUID string) error {
+	err := s.cache.Delete(ctx, ruleStatesCacheKey(orgID, alertRuleUID))
+	if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		return nil
+	}
+	return err
+}
+
+// SetSharedStateStore makes the manager lock and read the states of a rule from the store before processing its
+// evaluation results, and write them back and release the lock afterwards.
+func (st *Manager) SetSharedStateStore(store SharedStateStore) {
+	st.sharedStore = store
+}
+
+// loadSharedRuleStates locks the states of the rule in the shared store and replaces the cached states of the rule
+// with them. The cached states are kept if the shared store does not hold the rule, e.g. before its first evaluation
+// after the startup. The returned function writes the cached states of the rule back and releases the lock. If the
+// lock is not acquired, the cached states are processed but not written back, so that they do not overwrite the states
+// of the peer that holds the lock.
+func (st *Manager) loadSharedRuleStates(ctx context.Context, orgID int64, alertRuleUID string) func() {
+	if st.sharedStore == nil {
+		return func() {}
+	}
+
+	unlock, err := st.sharedStore.LockRuleStates(ctx, orgID, alertRuleUID)
+	if err != nil {
+		st.log.Error("unable to lock alert states in the shared state cache, using the local states", "orgID", orgID, "alertRuleUID", alertRuleUID, "err", err)
+		return func() {}
+	}
+
+	states, version, ok := st.readSharedRuleStates(ctx, orgID, alertRuleUID)
+	if ok {
+		st.cache.replaceRuleStates(orgID, alertRuleUID, states)
+	}
+	return func() {
+		defer unlock()
+		st.writeSharedRuleStates(ctx, orgID, alertRuleUID, version)
+	}
+}
+
+// readSharedRuleStates returns the states of the rule from the shared store and their version, and false if the
+// manager has no shared store or the shared store does not hold the rule.
+func (st *Manager) readSharedRuleStates(ctx context.Context, orgID int64, alertRuleUID string) ([]*State, int64, bool) {
+	if st.sharedStore == nil {
+		return nil, 0, false
+	}
+
+	states, version, err := st.sharedStore.GetRuleStates(ctx, orgID, alertRuleUID)
+	if err != nil {
+		if !errors.Is(err, ErrRuleStatesNotFound) {
+			st.log.Error("unable to read alert states from the shared state cache, using the local states", "orgID", orgID, "alertRuleUID", alertRuleUID, "err", err)
+		}
+		return nil, 0, false
+	}
+	return states, version, true
+}
+
+// saveSharedRuleStates writes the cached states of the rule to the shared store, replacing whatever states it holds.
+func (st *Manager) saveSharedRuleStates(ctx context.Context, orgID int64, alertRuleUID string) {
+	if st.sharedStore == nil {
+		return
+	}
+
+	unlock, err := st.sharedStore.LockRuleStates(ctx, orgID, alertRuleUID)
+	if err != nil {
+		st.log.Error("unable to lock alert states in the shared state cache", "orgID", orgID, "alertRuleUID", alertRuleUID, "err", err)
+		return
+	}
+	defer unlock()
+
+	_, version, _ := st.readSharedRuleStates(ctx, orgID, alertRuleUID)
+	st.writeSharedRuleStates(ctx, orgID, alertRuleUID, version)
+}
+
+// writeSharedRuleStates writes the cached states of the rule to the shared store if the shared states are still at the
+// given version. Otherwise another peer changed them concurrently, and the cached states are not written so that they
+// do not overwrite the states of the other peer.
+func (st *Manager) writeSharedRuleStates(ctx context.Context, orgID int64, alertRuleUID string, version int64) {
+	err := st.sharedStore.SetRuleStates(ctx, orgID, alertRuleUID, st.GetStatesForRuleUID(orgID, alertRuleUID), version)
+	if errors.Is(err, ErrRuleStatesConflict) {
+		st.log.Warn("alert states were changed by another peer while they were processed, discarding the local states", "orgID", orgID, "alertRuleUID", alertRuleUID, "err", err)
+		return
+	}
+	if err != nil {
+		st.log.Error("unable to write alert states to the shared state cache", "orgID", orgID, "alertRuleUID", alertRuleUID, "err", err)
+	}
+}
+
+// deleteSharedRuleStates removes the states of the rule from the shared store.
+func (st *Manager) deleteSharedRuleStates(ctx context.Context, orgID int64, alertRuleUID string) {
+	if st.sharedStore == nil {
+		return
+	}
+
+	if err := st.sharedStore.DeleteRuleStates(ctx, orgID, alertRuleUID); err != nil {
+		st.log.Error("unable to delete alert states from the shared state cache", "orgID", orgID, "alertRuleUID", alertRuleUID, "err", err)
+	}
+}
+
+func (c *cache) replaceRuleStates(orgID int64, alertRuleUID string, states []*State) {
+	c.mtxStates.Lock()
+	defer c.mtxStates.Unlock()
+	if _, ok := c.states[orgID]; !ok {
+		c.states[orgID] = make(map[string]map[string]*State)
+	}
+	ruleStates := make(map[string]*State, len(states))
+	for _, s := range states {
+		ruleStates[s.CacheId] = s
+	}
+	c.states[orgID][alertRuleUID] = ruleStates
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
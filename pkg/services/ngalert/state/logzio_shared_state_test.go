@@ -0,0 +1,143 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestSharedStateStore(t *testing.T) {
+	sharedStore := NewRemoteCacheStateStore(remotecache.NewFakeStore(t), time.Hour)
+	ctx := WithoutAnnotationsAndInstances(context.Background())
+
+	rule := &ngModels.AlertRule{
+		OrgID:           1,
+		UID:             "rule",
+		Title:           "rule",
+		Condition:       "A",
+		IntervalSeconds: 10,
+		For:             20 * time.Second,
+	}
+	evaluatedAt := time.Now()
+	result := func(state eval.State, at time.Time) eval.Results {
+		nan := math.NaN()
+		return eval.Results{{
+			Instance:    data.Labels{"instance": "a"},
+			State:       state,
+			EvaluatedAt: at,
+			Values:      map[string]eval.NumberValueCapture{"A": {Var: "A", Value: &nan}},
+		}}
+	}
+
+	newPeer := func() *Manager {
+		peer := NewIsolatedManager(log.New("test"), nil, nil)
+		peer.SetSharedStateStore(sharedStore)
+		return peer
+	}
+	first, second := newPeer(), newPeer()
+
+	t.Run("peer continues from the state the other peer processed", func(t *testing.T) {
+		states := first.ProcessEvalResults(ctx, rule, result(eval.Alerting, evaluatedAt))
+		require.Len(t, states, 1)
+		require.Equal(t, eval.Pending, states[0].State)
+
+		states = second.ProcessEvalResults(ctx, rule, result(eval.Alerting, evaluatedAt.Add(30*time.Second)))
+		require.Len(t, states, 1)
+		require.Equal(t, eval.Alerting, states[0].State)
+		require.Len(t, states[0].Results, 2)
+	})
+
+	t.Run("isolated copy reads the shared states without replacing the local states", func(t *testing.T) {
+		require.Equal(t, eval.Pending, first.GetStatesForRuleUID(rule.OrgID, rule.UID)[0].State)
+
+		isolated := first.IsolatedCopyForRule(ctx, rule.OrgID, rule.UID)
+		require.Equal(t, eval.Alerting, isolated.GetStatesForRuleUID(rule.OrgID, rule.UID)[0].State)
+		require.Equal(t, eval.Pending, first.GetStatesForRuleUID(rule.OrgID, rule.UID)[0].State)
+	})
+
+	t.Run("errors of states are shared as messages", func(t *testing.T) {
+		states := first.ProcessEvalResults(ctx, rule, eval.Results{{
+			Instance:    data.Labels{"instance": "a"},
+			State:       eval.Error,
+			Error:       errors.New("query failed"),
+			EvaluatedAt: evaluatedAt.Add(time.Minute),
+		}})
+		require.Len(t, states, 1)
+
+		shared, _, err := sharedStore.GetRuleStates(context.Background(), rule.OrgID, rule.UID)
+		require.NoError(t, err)
+		require.Len(t, shared, 1)
+		require.Error(t, shared[0].Error)
+		require.Equal(t, states[0].Error.Error(), shared[0].Error.Error())
+		require.Equal(t, states[0].State, shared[0].State)
+		require.Equal(t, states[0].CacheId, shared[0].CacheId)
+	})
+
+	t.Run("evicting the local states keeps the shared states", func(t *testing.T) {
+		evictedRule := *rule
+		evictedRule.UID = "evicted"
+		second.ProcessEvalResults(ctx, &evictedRule, result(eval.Normal, evaluatedAt.Add(-time.Hour)))
+
+		WithStateEviction(time.Minute, time.Hour)(second)
+		second.clearDeletedAlertEntriesForOrg(rule.OrgID)
+		require.Empty(t, second.GetStatesForRuleUID(rule.OrgID, evictedRule.UID))
+
+		shared, _, err := sharedStore.GetRuleStates(context.Background(), rule.OrgID, evictedRule.UID)
+		require.NoError(t, err)
+		require.Len(t, shared, 1)
+	})
+
+	t.Run("writing states of an outdated version is rejected", func(t *testing.T) {
+		outdated, version, err := sharedStore.GetRuleStates(context.Background(), rule.OrgID, rule.UID)
+		require.NoError(t, err)
+
+		first.ProcessEvalResults(ctx, rule, result(eval.Alerting, evaluatedAt.Add(2*time.Minute)))
+
+		err = sharedStore.SetRuleStates(context.Background(), rule.OrgID, rule.UID, outdated, version)
+		require.ErrorIs(t, err, ErrRuleStatesConflict)
+		shared, current, err := sharedStore.GetRuleStates(context.Background(), rule.OrgID, rule.UID)
+		require.NoError(t, err)
+		require.Equal(t, version+1, current)
+		require.True(t, evaluatedAt.Add(2*time.Minute).Equal(shared[0].LastEvaluationTime))
+	})
+
+	t.Run("peer does not write the states while another peer holds their lock", func(t *testing.T) {
+		sharedStore.lockTimeout = 100 * time.Millisecond
+		defer func() { sharedStore.lockTimeout = defaultRuleStatesLockTimeout }()
+		_, version, err := sharedStore.GetRuleStates(context.Background(), rule.OrgID, rule.UID)
+		require.NoError(t, err)
+
+		unlock, err := sharedStore.LockRuleStates(context.Background(), rule.OrgID, rule.UID)
+		require.NoError(t, err)
+		_, err = sharedStore.LockRuleStates(context.Background(), rule.OrgID, rule.UID)
+		require.ErrorIs(t, err, ErrRuleStatesLocked)
+
+		second.ProcessEvalResults(ctx, rule, result(eval.Alerting, evaluatedAt.Add(3*time.Minute)))
+		_, current, err := sharedStore.GetRuleStates(context.Background(), rule.OrgID, rule.UID)
+		require.NoError(t, err)
+		require.Equal(t, version, current)
+
+		unlock()
+		second.ProcessEvalResults(ctx, rule, result(eval.Alerting, evaluatedAt.Add(4*time.Minute)))
+		_, current, err = sharedStore.GetRuleStates(context.Background(), rule.OrgID, rule.UID)
+		require.NoError(t, err)
+		require.Equal(t, version+1, current)
+	})
+
+	t.Run("removing the rule removes its shared states", func(t *testing.T) {
+		second.RemoveByRuleUID(rule.OrgID, rule.UID)
+
+		_, _, err := sharedStore.GetRuleStates(context.Background(), rule.OrgID, rule.UID)
+		require.ErrorIs(t, err, ErrRuleStatesNotFound)
+	})
+}
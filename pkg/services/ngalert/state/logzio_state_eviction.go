@@ -0,0 +1,124 @@
+package state
+
+// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+
+import (
+	"sort"
+	"time"
+)
+
+// ManagerOption configures optional behaviour of the Manager.
+type ManagerOption func(*Manager)
+
+// WithStateEviction sets how long after the last evaluation of a rule its states are evicted and how often the
+// states to evict are looked for.
+func WithStateEviction(delay, frequency time.Duration) ManagerOption {
+	return func(st *Manager) {
+		if delay > 0 {
+			st.evictionDelay = delay
+		}
+		if frequency > 0 {
+			st.evictionFrequency = frequency
+		}
+	}
+}
+
+// ScheduledEviction is a state that is evicted at the next eviction.
+type ScheduledEviction struct {
+	State         *State
+	EvictionDelay time.Duration
+	// EvictableAt is when the state became or becomes eligible for eviction.
+	EvictableAt time.Time
+}
+
+type ruleEviction struct {
+	ruleUID     string
+	states      []*State
+	lastState   *State
+	delay       time.Duration
+	evictableAt time.Time
+}
+
+// ruleEvictions returns the rules whose states are eligible for eviction before the given time. The states of a rule
+// are eligible once the eviction delay passed since the last evaluation of the rule and its last state ended.
+func (st *Manager) ruleEvictions(orgID int64, until time.Time) []ruleEviction {
+	statesByRuleUID := make(map[string][]*State)
+	lastStateByRuleUID := make(map[string]*State)
+	for _, s := range st.GetAll(orgID) {
+		statesByRuleUID[s.AlertRuleUID] = append(statesByRuleUID[s.AlertRuleUID], s)
+		if lastState, found := lastStateByRuleUID[s.AlertRuleUID]; !found || s.LastEvaluationTime.After(lastState.LastEvaluationTime) {
+			lastStateByRuleUID[s.AlertRuleUID] = s
+		}
+	}
+
+	var evictions []ruleEviction
+	for ruleUID, lastState := range lastStateByRuleUID {
+		delay := st.evictionDelayOf(lastState)
+		evictableAt := lastState.LastEvaluationTime.Add(delay)
+		if lastState.EndsAt.After(evictableAt) {
+			evictableAt = lastState.EndsAt
+		}
+		if !evictableAt.Before(until) {
+			continue
+		}
+		evictions = append(evictions, ruleEviction{
+			ruleUID:     ruleUID,
+			states:      statesByRuleUID[ruleUID],
+			lastState:   lastState,
+			delay:       delay,
+			evictableAt: evictableAt,
+		})
+	}
+	return evictions
+}
+
+// evictionDelayOf returns the eviction delay of the rule of the state, which the rule can override with its
+// EvictionDelay.
+func (st *Manager) evictionDelayOf(s *State) time.Duration {
+	if s.EvictionDelay > 0 {
+		return s.EvictionDelay
+	}
+	return st.evictionDelay
+}
+
+func (st *Manager) setNextEvictionAt(next time.Time) {
+	st.mtxEviction.Lock()
+	defer st.mtxEviction.Unlock()
+	st.nextEvictionAt = next
+}
+
+// StatesScheduledForEviction returns when the states are evicted next and the states of the org that are evicted then.
+func (st *Manager) StatesScheduledForEviction(orgID int64) (time.Time, []ScheduledEviction) {
+	st.mtxEviction.RLock()
+	nextEvictionAt := st.nextEvictionAt
+	st.mtxEviction.RUnlock()
+
+	var scheduled []ScheduledEviction
+	for _, eviction := range st.ruleEvictions(orgID, nextEvictionAt) {
+		for _, s := range eviction.states {
+			scheduled = append(scheduled, ScheduledEviction{State: s, EvictionDelay: eviction.delay, EvictableAt: eviction.evictableAt})
+		}
+	}
+	sort.Slice(scheduled, func(i, j int) bool {
+		if !scheduled[i].EvictableAt.Equal(scheduled[j].EvictableAt) {
+			return scheduled[i].EvictableAt.Before(scheduled[j].EvictableAt)
+		}
+		if scheduled[i].State.AlertRuleUID != scheduled[j].State.AlertRuleUID {
+			return scheduled[i].State.AlertRuleUID < scheduled[j].State.AlertRuleUID
+		}
+		return scheduled[i].State.CacheId < scheduled[j].State.CacheId
+	})
+	return nextEvictionAt, scheduled
+}
+
+func (c *cache) getOrgIDs() []int64 {
+	c.mtxStates.RLock()
+	defer c.mtxStates.RUnlock()
+	orgIDs := make([]int64, 0, len(c.states))
+	for orgID := range c.states {
+		orgIDs = append(orgIDs, orgID)
+	}
+	return orgIDs
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
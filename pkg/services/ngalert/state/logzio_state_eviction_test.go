@@ -0,0 +1,84 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestStateEviction(t *testing.T) {
+	now := time.Now()
+	newManager := func() *Manager {
+		st := &Manager{
+			log:     log.New("test"),
+			metrics: metrics.NewNGAlert(prometheus.NewRegistry()).GetStateMetrics(),
+			cache:   newCache(log.New("test"), nil, nil),
+		}
+		WithStateEviction(time.Hour, 10*time.Minute)(st)
+		st.setNextEvictionAt(now.Add(10 * time.Minute))
+
+		st.Put([]*State{
+			// evaluated recently
+			{OrgID: 1, AlertRuleUID: "recent", CacheId: "a", State: eval.Normal, LastEvaluationTime: now.Add(-time.Minute)},
+			// not evaluated for longer than the eviction delay
+			{OrgID: 1, AlertRuleUID: "old", CacheId: "a", State: eval.Normal, LastEvaluationTime: now.Add(-2 * time.Hour)},
+			{OrgID: 1, AlertRuleUID: "old", CacheId: "b", State: eval.Normal, LastEvaluationTime: now.Add(-3 * time.Hour)},
+			// not evaluated for longer than the eviction delay, but still firing
+			{OrgID: 1, AlertRuleUID: "firing", CacheId: "a", State: eval.Alerting, LastEvaluationTime: now.Add(-2 * time.Hour), EndsAt: now.Add(time.Hour)},
+			// the rule keeps its states for longer
+			{OrgID: 1, AlertRuleUID: "override", CacheId: "a", State: eval.Normal, LastEvaluationTime: now.Add(-2 * time.Hour), EvictionDelay: 3 * time.Hour},
+			// becomes eligible before the next eviction
+			{OrgID: 1, AlertRuleUID: "next", CacheId: "a", State: eval.Normal, LastEvaluationTime: now.Add(-55 * time.Minute)},
+		})
+		return st
+	}
+
+	t.Run("evicts the states of rules not evaluated within the eviction delay", func(t *testing.T) {
+		st := newManager()
+		st.clearDeletedAlertEntriesForOrg(1)
+
+		require.Empty(t, st.GetStatesForRuleUID(1, "old"))
+		require.Len(t, st.GetStatesForRuleUID(1, "recent"), 1)
+		require.Len(t, st.GetStatesForRuleUID(1, "firing"), 1)
+		require.Len(t, st.GetStatesForRuleUID(1, "override"), 1)
+		require.Len(t, st.GetStatesForRuleUID(1, "next"), 1)
+		require.Equal(t, float64(2), testutil.ToFloat64(st.metrics.EvictedStates.WithLabelValues("1")))
+	})
+
+	t.Run("lists the states evicted at the next eviction", func(t *testing.T) {
+		st := newManager()
+		nextEvictionAt, scheduled := st.StatesScheduledForEviction(1)
+
+		require.Equal(t, now.Add(10*time.Minute), nextEvictionAt)
+		require.Len(t, scheduled, 3)
+		require.Equal(t, "old", scheduled[0].State.AlertRuleUID)
+		require.Equal(t, "old", scheduled[1].State.AlertRuleUID)
+		require.Equal(t, now.Add(-time.Hour), scheduled[0].EvictableAt)
+		require.Equal(t, "next", scheduled[2].State.AlertRuleUID)
+		require.Equal(t, now.Add(5*time.Minute), scheduled[2].EvictableAt)
+		require.Equal(t, time.Hour, scheduled[2].EvictionDelay)
+	})
+
+	t.Run("states take the eviction delay of their rule", func(t *testing.T) {
+		st := newManager()
+		ctx := WithoutAnnotationsAndInstances(context.Background())
+		rule := &ngModels.AlertRule{OrgID: 1, UID: "rule", IntervalSeconds: 10, EvictionDelay: 3 * time.Hour}
+		states := st.ProcessEvalResults(ctx, rule, eval.Results{{Instance: data.Labels{"instance": "a"}, State: eval.Normal, EvaluatedAt: now}})
+		require.Len(t, states, 1)
+		require.Equal(t, 3*time.Hour, st.evictionDelayOf(states[0]))
+
+		rule.EvictionDelay = 0
+		states = st.ProcessEvalResults(ctx, rule, eval.Results{{Instance: data.Labels{"instance": "a"}, State: eval.Normal, EvaluatedAt: now}})
+		require.Equal(t, time.Hour, st.evictionDelayOf(states[0]))
+	})
+}
@@ -0,0 +1,93 @@
+package state
+
+// LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// StateHistoryStore records the state transitions of the alerts.
+type StateHistoryStore interface {
+	SaveStateHistory(ctx context.Context, entries ...*ngModels.StateHistoryEntry) error
+}
+
+// WithStateHistory records the state transitions of the alerts in the given store, in addition to the annotations.
+func WithStateHistory(store StateHistoryStore) ManagerOption {
+	return func(st *Manager) {
+		st.stateHistory = store
+	}
+}
+
+// recordStateHistory records the transition of the state from the previous state, with the values of its last
+// evaluation. The entry is built before returning, as the state keeps changing, and saved in the background.
+func (st *Manager) recordStateHistory(s *State, evaluatedAt time.Time, previousState eval.State) {
+	if st.stateHistory == nil {
+		return
+	}
+	var values map[string]*float64
+	if len(s.Results) > 0 {
+		values = historyValues(s.Results[len(s.Results)-1].Values)
+	}
+	st.saveStateHistory(st.stateHistoryEntry(s, evaluatedAt, previousState, s.State, values))
+}
+
+// recordStaleStateHistory records the resolution of a firing state that is no longer returned by the evaluations of
+// its rule.
+func (st *Manager) recordStaleStateHistory(s *State, resolvedAt time.Time) {
+	if st.stateHistory == nil {
+		return
+	}
+	st.saveStateHistory(st.stateHistoryEntry(s, resolvedAt, s.State, eval.Normal, nil))
+}
+
+func (st *Manager) stateHistoryEntry(s *State, evaluatedAt time.Time, previousState, state eval.State, values map[string]*float64) *ngModels.StateHistoryEntry {
+	labels := make(ngModels.InstanceLabels, len(s.Labels))
+	for name, value := range s.Labels {
+		labels[name] = value
+	}
+	_, labelsHash, err := labels.StringAndHash()
+	if err != nil {
+		st.log.Error("unable to get labelsHash of state history entry", "error", err.Error(), "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID)
+	}
+	return &ngModels.StateHistoryEntry{
+		OrgID:         s.OrgID,
+		RuleUID:       s.AlertRuleUID,
+		LabelsHash:    labelsHash,
+		Labels:        labels,
+		PreviousState: previousState.String(),
+		State:         state.String(),
+		Values:        values,
+		EvaluatedAt:   evaluatedAt.UnixMilli(),
+	}
+}
+
+func (st *Manager) saveStateHistory(entry *ngModels.StateHistoryEntry) {
+	go func() {
+		// The transition is recorded even if the evaluation that caused it is cancelled meanwhile.
+		if err := st.stateHistory.SaveStateHistory(context.Background(), entry); err != nil {
+			st.log.Error("error saving alert state history", "alertRuleUID", entry.RuleUID, "error", err.Error())
+		}
+	}()
+}
+
+// historyValues copies the values of an evaluation, replacing the values that cannot be serialized, such as NaN,
+// with nil.
+func historyValues(values map[string]*float64) map[string]*float64 {
+	result := make(map[string]*float64, len(values))
+	for refID, value := range values {
+		if value == nil || math.IsNaN(*value) || math.IsInf(*value, 0) {
+			result[refID] = nil
+			continue
+		}
+		v := *value
+		result[refID] = &v
+	}
+	return result
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
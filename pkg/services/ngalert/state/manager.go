@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -14,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/image"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -23,6 +25,7 @@ import (
 var ResendDelay = 30 * time.Second
 
 // LOGZ.IO GRAFANA CHANGE :: Evict old entries from state manager
+// EvaluationDelayToClearAlertState and ClearOldAlertFrequency are the defaults of the eviction settings, see WithStateEviction.
 const EvaluationDelayToClearAlertState = time.Duration(1) * time.Hour
 const ClearOldAlertFrequency = time.Duration(40) * time.Minute
 
@@ -48,10 +51,28 @@ type Manager struct {
 	ruleStore     store.RuleStore
 	instanceStore store.InstanceStore
 	sqlStore      sqlstore.Store
+
+	sharedStore SharedStateStore // LOGZ.IO GRAFANA CHANGE :: Shared alert state cache across HA peers
+
+	// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+	evictionDelay     time.Duration
+	evictionFrequency time.Duration
+	nextEvictionAt    time.Time
+	mtxEviction       sync.RWMutex
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	stateHistory StateHistoryStore // LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+
+	// LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+	instanceLimit      int
+	instanceLimitByOrg map[int64]int
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	images image.ImageService // LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
 }
 
 func NewManager(logger log.Logger, metrics *metrics.State, externalURL *url.URL, ruleStore store.RuleStore,
-	instanceStore store.InstanceStore, sqlStore sqlstore.Store) *Manager {
+	instanceStore store.InstanceStore, sqlStore sqlstore.Store, opts ...ManagerOption) *Manager { // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
 	manager := &Manager{
 		cache:         newCache(logger, metrics, externalURL),
 		quit:          make(chan struct{}),
@@ -61,7 +82,16 @@ func NewManager(logger log.Logger, metrics *metrics.State, externalURL *url.URL,
 		ruleStore:     ruleStore,
 		instanceStore: instanceStore,
 		sqlStore:      sqlStore,
+		// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+		evictionDelay:     EvaluationDelayToClearAlertState,
+		evictionFrequency: ClearOldAlertFrequency,
+		// LOGZ.IO GRAFANA CHANGE :: end
+	}
+	// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+	for _, opt := range opts {
+		opt(manager)
 	}
+	// LOGZ.IO GRAFANA CHANGE :: end
 	go manager.recordMetrics()
 	go manager.clearOldEntries()
 	return manager
@@ -126,6 +156,7 @@ func (st *Manager) Warm(ctx context.Context) {
 				EndsAt:               entry.CurrentStateEnd,
 				LastEvaluationTime:   entry.LastEvalTime,
 				Annotations:          ruleForEntry.Annotations,
+				EvictionDelay:        ruleForEntry.EvictionDelay, // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
 			}
 			states = append(states, stateForEntry)
 		}
@@ -154,12 +185,43 @@ func (st *Manager) ResetCache() {
 }
 
 // RemoveByRuleUID deletes all entries in the state manager that match the given rule UID.
+// LOGZ.IO GRAFANA CHANGE :: Shared alert state cache across HA peers
+// The states are also deleted from the shared state store, so it must only be called when the states of the rule are
+// reset, e.g. when the rule is deleted or updated.
+// LOGZ.IO GRAFANA CHANGE :: end
 func (st *Manager) RemoveByRuleUID(orgID int64, ruleUID string) {
 	st.cache.removeByRuleUID(orgID, ruleUID)
+	st.deleteSharedRuleStates(context.Background(), orgID, ruleUID) // LOGZ.IO GRAFANA CHANGE :: Shared alert state cache across HA peers
+	st.forgetImages(orgID, ruleUID)                                 // LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
 }
 
+// LOGZ.IO GRAFANA CHANGE :: Shared alert state cache across HA peers
 func (st *Manager) ProcessEvalResults(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results) []*State {
+	saveSharedRuleStates := st.loadSharedRuleStates(ctx, alertRule.OrgID, alertRule.UID)
+	defer saveSharedRuleStates()
+	return st.processEvalResults(ctx, alertRule, results)
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Alert rule backtesting endpoint
+// ProcessEvalResultsAt processes the results of an evaluation of the past like ProcessEvalResults, with the states that
+// were not part of the results considered stale relative to evalTime rather than to the current time.
+func (st *Manager) ProcessEvalResultsAt(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results, evalTime time.Time) []*State {
+	saveSharedRuleStates := st.loadSharedRuleStates(ctx, alertRule.OrgID, alertRule.UID)
+	defer saveSharedRuleStates()
+	return st.processEvalResultsAt(ctx, alertRule, results, evalTime)
+}
+
+func (st *Manager) processEvalResults(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results) []*State {
+	return st.processEvalResultsAt(ctx, alertRule, results, time.Now())
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+func (st *Manager) processEvalResultsAt(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results, evalTime time.Time) []*State { // LOGZ.IO GRAFANA CHANGE :: Alert rule backtesting endpoint
 	st.log.Debug("state manager processing evaluation results", "uid", alertRule.UID, "resultCount", len(results))
+	alertRule, results = st.limitInstances(alertRule, results) // LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
 	var states []*State
 	processedResults := make(map[string]*State, len(results))
 	for _, result := range results {
@@ -167,7 +229,7 @@ func (st *Manager) ProcessEvalResults(ctx context.Context, alertRule *ngModels.A
 		states = append(states, s)
 		processedResults[s.CacheId] = s
 	}
-	st.staleResultsHandler(ctx, alertRule, processedResults)
+	st.staleResultsHandler(ctx, alertRule, processedResults, evalTime) // LOGZ.IO GRAFANA CHANGE :: Alert rule backtesting endpoint
 	return states
 }
 
@@ -184,12 +246,18 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		Condition:       alertRule.Condition,
 	})
 	currentState.LastEvaluationString = result.EvaluationString
+	currentState.EvictionDelay = alertRule.EvictionDelay // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
 	currentState.TrimResults(alertRule)
 	oldState := currentState.State
 
 	st.log.Debug("setting alert state", "uid", alertRule.UID)
 	switch result.State {
 	case eval.Normal:
+		// LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+		if currentState.resultKeepFiring(alertRule, result) {
+			break
+		}
+		// LOGZ.IO GRAFANA CHANGE :: end
 		currentState.resultNormal(alertRule, result)
 	case eval.Alerting:
 		currentState.resultAlerting(alertRule, result)
@@ -199,17 +267,26 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		currentState.resultNoData(alertRule, result)
 	case eval.Pending: // we do not emit results with this state
 	}
+	// LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+	// the keep firing window ends when the state is no longer alerting or its condition is met again
+	if currentState.State != eval.Alerting || result.State != eval.Normal {
+		currentState.KeptFiringSince = time.Time{}
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
 
 	// Set Resolved property so the scheduler knows to send a postable alert
 	// to Alertmanager.
 	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
 
+	st.maybeTakeImage(ctx, alertRule, currentState, oldState) // LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+
 	st.set(currentState)
 	if oldState != currentState.State {
 		// LOGZ.IO GRAFANA CHANGE :: Manage annotations and instances only on one peer of HA cluster
 		shouldManageAnnotations := ctx.Value(ShouldManageAnnotationsAndInstancesContextKey).(bool)
 		if shouldManageAnnotations {
 			go st.annotateState(ctx, alertRule, currentState.Labels, result.EvaluatedAt, currentState.State, oldState)
+			st.recordStateHistory(currentState, result.EvaluatedAt, oldState) // LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
 		}
 		// LOGZ.IO GRAFANA CHANGE :: end
 	}
@@ -244,13 +321,15 @@ func (st *Manager) recordMetrics() {
 
 // LOGZ.IO GRAFANA CHANGE :: Clear old entries from state cache
 func (st *Manager) clearOldEntries() {
-	ticker := time.NewTicker(ClearOldAlertFrequency)
+	ticker := time.NewTicker(st.evictionFrequency)
+	st.setNextEvictionAt(time.Now().Add(st.evictionFrequency))
 
 	for {
 		select {
 		case <-ticker.C:
 			st.log.Debug("clearing old alert states", "now", time.Now())
-			for orgId := range st.cache.states {
+			st.setNextEvictionAt(time.Now().Add(st.evictionFrequency))
+			for _, orgId := range st.cache.getOrgIDs() {
 				st.clearDeletedAlertEntriesForOrg(orgId)
 			}
 		case <-st.quit:
@@ -262,32 +341,20 @@ func (st *Manager) clearOldEntries() {
 }
 
 func (st *Manager) clearDeletedAlertEntriesForOrg(orgId int64) {
-	lastStatePerRuleUuid := make(map[string]*State)
-	shouldRemove := func(state *State, now time.Time) bool {
-		if state != nil {
-			return state.LastEvaluationTime.Add(EvaluationDelayToClearAlertState).Before(now) &&
-				state.EndsAt.Before(now)
-		} else {
-			return false
-		}
-	}
-
-	for _, state := range st.GetAll(orgId) {
-		if lastState, found := lastStatePerRuleUuid[state.AlertRuleUID]; found {
-			if state.LastEvaluationTime.After(lastState.LastEvaluationTime) {
-				lastStatePerRuleUuid[state.AlertRuleUID] = state
-			}
-		} else {
-			lastStatePerRuleUuid[state.AlertRuleUID] = state
-		}
-	}
-
-	now := time.Now()
 	totalCleared := 0
-	for ruleUID, lastState := range lastStatePerRuleUuid {
-		if shouldRemove(lastState, now) {
-			st.RemoveByRuleUID(orgId, ruleUID)
-			totalCleared++
+	for _, eviction := range st.ruleEvictions(orgId, time.Now()) {
+		// LOGZ.IO GRAFANA CHANGE :: Shared alert state cache across HA peers
+		// only the local states are evicted: the eviction is based on the last evaluation of the rule by this peer, while
+		// the other peers may still be evaluating the rule with its shared states, which expire with their TTL instead
+		st.cache.removeByRuleUID(orgId, eviction.ruleUID)
+		// LOGZ.IO GRAFANA CHANGE :: end
+		st.forgetImages(orgId, eviction.ruleUID) // LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+		totalCleared++
+
+		st.log.Info("evicted alert states of rule", "orgID", orgId, "alertRuleUID", eviction.ruleUID, "states", len(eviction.states),
+			"lastEvaluation", eviction.lastState.LastEvaluationTime, "evictionDelay", eviction.delay)
+		if st.metrics != nil {
+			st.metrics.EvictedStates.WithLabelValues(fmt.Sprint(orgId)).Add(float64(len(eviction.states)))
 		}
 	}
 
@@ -304,6 +371,19 @@ func (st *Manager) Put(states []*State) {
 	for _, s := range states {
 		st.set(s)
 	}
+	// LOGZ.IO GRAFANA CHANGE :: Shared alert state cache across HA peers
+	if st.sharedStore != nil {
+		saved := make(map[ngModels.AlertRuleKey]struct{})
+		for _, s := range states {
+			key := ngModels.AlertRuleKey{OrgID: s.OrgID, UID: s.AlertRuleUID}
+			if _, ok := saved[key]; ok {
+				continue
+			}
+			saved[key] = struct{}{}
+			st.saveSharedRuleStates(context.Background(), key.OrgID, key.UID)
+		}
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 func translateInstanceState(state ngModels.InstanceStateType) eval.State {
@@ -365,7 +445,7 @@ func (st *Manager) annotateState(ctx context.Context, alertRule *ngModels.AlertR
 	}
 }
 
-func (st *Manager) staleResultsHandler(ctx context.Context, alertRule *ngModels.AlertRule, states map[string]*State) {
+func (st *Manager) staleResultsHandler(ctx context.Context, alertRule *ngModels.AlertRule, states map[string]*State, evalTime time.Time) { // LOGZ.IO GRAFANA CHANGE :: Alert rule backtesting endpoint
 	allStates := st.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID)
 	toDelete := make([]ngModels.AlertInstanceKey, 0)
 
@@ -374,7 +454,7 @@ func (st *Manager) staleResultsHandler(ctx context.Context, alertRule *ngModels.
 
 	for _, s := range allStates {
 		_, ok := states[s.CacheId]
-		if !ok && isItStale(s.LastEvaluationTime, alertRule.IntervalSeconds) {
+		if !ok && isItStale(s.LastEvaluationTime, alertRule.IntervalSeconds, evalTime) { // LOGZ.IO GRAFANA CHANGE :: Alert rule backtesting endpoint
 			st.log.Debug("removing stale state entry", "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID, "cacheID", s.CacheId)
 			st.cache.deleteEntry(s.OrgID, s.AlertRuleUID, s.CacheId)
 			ilbs := ngModels.InstanceLabels(s.Labels)
@@ -388,7 +468,8 @@ func (st *Manager) staleResultsHandler(ctx context.Context, alertRule *ngModels.
 			if s.State == eval.Alerting {
 				// LOGZ.IO GRAFANA CHANGE :: Manage annotations and instances only on one peer of HA cluster
 				if shouldManageAnnotationsAndInstances {
-					st.annotateState(ctx, alertRule, s.Labels, time.Now(), eval.Normal, s.State)
+					st.annotateState(ctx, alertRule, s.Labels, evalTime, eval.Normal, s.State)
+					st.recordStaleStateHistory(s, evalTime) // LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
 				}
 				// LOGZ.IO GRAFANA CHANGE :: end
 			}
@@ -405,10 +486,13 @@ func (st *Manager) staleResultsHandler(ctx context.Context, alertRule *ngModels.
 	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
-func isItStale(lastEval time.Time, intervalSeconds int64) bool {
-	return lastEval.Add(2 * time.Duration(intervalSeconds) * time.Second).Before(time.Now())
+// LOGZ.IO GRAFANA CHANGE :: Alert rule backtesting endpoint
+func isItStale(lastEval time.Time, intervalSeconds int64, evalTime time.Time) bool {
+	return lastEval.Add(2 * time.Duration(intervalSeconds) * time.Second).Before(evalTime)
 }
 
+// LOGZ.IO GRAFANA CHANGE :: end
+
 func removePrivateLabels(labels data.Labels) data.Labels {
 	result := make(data.Labels)
 	for k, v := range labels {
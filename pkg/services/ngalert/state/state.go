@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/image" // LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
 	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
@@ -30,6 +31,18 @@ type State struct {
 	Annotations          map[string]string
 	Labels               data.Labels
 	Error                error
+	// LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+	// KeptFiringSince is when the condition of an alerting state cleared while it keeps firing. It is zero otherwise.
+	KeptFiringSince time.Time
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+	// Image is the screenshot of the panel of the rule taken when the state started alerting.
+	Image *image.Image
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+	// EvictionDelay is the eviction delay of the rule of the state. It is zero when the rule uses the default one.
+	EvictionDelay time.Duration
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 type Evaluation struct {
@@ -215,6 +228,15 @@ func (a *State) setEndsAt(alertRule *ngModels.AlertRule, result eval.Result) {
 	a.EndsAt = result.EvaluatedAt.Add(ends * 7) //LOGZ.IO GRAFANA CHANGE :: Increase interval to keep last state of alert
 }
 
+// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+// ExtendEndsAt sets the end of the state as if the rule was evaluated at the given time, for the states that are kept
+// firing without evaluating the rule.
+func (a *State) ExtendEndsAt(alertRule *ngModels.AlertRule, at time.Time) {
+	a.setEndsAt(alertRule, eval.Result{EvaluatedAt: at})
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 func (a *State) GetLabels(opts ...ngModels.LabelOption) map[string]string {
 	labels := a.Labels.Copy()
 
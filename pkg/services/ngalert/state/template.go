@@ -32,8 +32,6 @@ func (v templateCaptureValue) String() string {
 }
 
 func expandTemplate(ctx context.Context, name, text string, labels map[string]string, alertInstance eval.Result, externalURL *url.URL) (result string, resultErr error) {
-	name = "__alert_" + name
-	text = "{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}" + text
 	data := struct {
 		Labels map[string]string
 		Values map[string]templateCaptureValue
@@ -44,12 +42,25 @@ func expandTemplate(ctx context.Context, name, text string, labels map[string]st
 		Value:  alertInstance.EvaluationString,
 	}
 
+	// LOGZ.IO GRAFANA CHANGE :: Dynamic labels and annotations from query results
+	expander := newTemplateExpander(ctx, name, text, data, alertInstance.EvaluatedAt, externalURL)
+	// LOGZ.IO GRAFANA CHANGE :: end
+	return expander.Expand()
+}
+
+// LOGZ.IO GRAFANA CHANGE :: Dynamic labels and annotations from query results
+// newTemplateExpander returns the expander of a label or annotation template, so that templates are validated with the
+// same functions and variables they are expanded with.
+func newTemplateExpander(ctx context.Context, name, text string, data interface{}, t time.Time, externalURL *url.URL) *template.Expander {
+	name = "__alert_" + name
+	text = "{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}" + text
+
 	expander := template.NewTemplateExpander(
 		ctx, // This context is only used with the `query()` function - which we don't support yet.
 		text,
 		name,
 		data,
-		model.Time(timestamp.FromTime(alertInstance.EvaluatedAt)),
+		model.Time(timestamp.FromTime(t)),
 		func(context.Context, string, time.Time) (promql.Vector, error) {
 			return nil, nil
 		},
@@ -67,9 +78,11 @@ func expandTemplate(ctx context.Context, name, text string, labels map[string]st
 		},
 	})
 
-	return expander.Expand()
+	return expander
 }
 
+// LOGZ.IO GRAFANA CHANGE :: end
+
 func newTemplateCaptureValues(values map[string]eval.NumberValueCapture) map[string]templateCaptureValue {
 	m := make(map[string]templateCaptureValue)
 	for k, v := range values {
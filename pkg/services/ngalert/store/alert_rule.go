@@ -157,6 +157,12 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				For:              r.For,
 				Annotations:      r.Annotations,
 				Labels:           r.Labels,
+				Record:           r.Record,        // LOGZ.IO GRAFANA CHANGE :: Recording rules
+				QueryOffset:      r.QueryOffset,   // LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+				IsPaused:         r.IsPaused,      // LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+				KeepFiringFor:    r.KeepFiringFor, // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+				MaxInstances:     r.MaxInstances,  // LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+				EvictionDelay:    r.EvictionDelay, // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
 			})
 		}
 		if len(newRules) > 0 {
@@ -222,6 +228,12 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []UpdateRule) erro
 				For:              r.New.For,
 				Annotations:      r.New.Annotations,
 				Labels:           r.New.Labels,
+				Record:           r.New.Record,        // LOGZ.IO GRAFANA CHANGE :: Recording rules
+				QueryOffset:      r.New.QueryOffset,   // LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+				IsPaused:         r.New.IsPaused,      // LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+				KeepFiringFor:    r.New.KeepFiringFor, // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+				MaxInstances:     r.New.MaxInstances,  // LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+				EvictionDelay:    r.New.EvictionDelay, // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
 			})
 		}
 		if len(newRules) > 0 {
@@ -380,7 +392,7 @@ func (st DBstore) GetNamespaceByTitle(ctx context.Context, namespace string, org
 func (st DBstore) GetAlertRulesForScheduling(ctx context.Context, query *ngmodels.ListAlertRulesQuery) error {
 	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
 		alerts := make([]*ngmodels.AlertRule, 0)
-		q := "SELECT uid, org_id, interval_seconds, version FROM alert_rule"
+		q := "SELECT uid, org_id, interval_seconds, version, is_paused FROM alert_rule" // LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
 		if len(query.ExcludeOrgs) > 0 {
 			q = fmt.Sprintf("%s WHERE org_id NOT IN (%s)", q, strings.Join(strings.Split(strings.Trim(fmt.Sprint(query.ExcludeOrgs), "[]"), " "), ","))
 		}
@@ -454,5 +466,45 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 	}
 	//LOGZ.IO GRAFANA CHANGE :: end
 
+	// LOGZ.IO GRAFANA CHANGE :: Recording rules
+	if alertRule.IsRecordingRule() {
+		if err := alertRule.Record.Validate(alertRule.Data); err != nil {
+			return err
+		}
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+	if alertRule.QueryOffset < 0 {
+		return fmt.Errorf("%w: query offset (%v) should not be negative", ngmodels.ErrAlertRuleFailedValidation, alertRule.QueryOffset)
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+	if alertRule.KeepFiringFor < 0 {
+		return fmt.Errorf("%w: keep firing for (%v) should not be negative", ngmodels.ErrAlertRuleFailedValidation, alertRule.KeepFiringFor)
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+	if alertRule.MaxInstances != nil && *alertRule.MaxInstances < 0 {
+		return fmt.Errorf("%w: maximum number of alert instances (%d) should not be negative", ngmodels.ErrAlertRuleFailedValidation, *alertRule.MaxInstances)
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+	if alertRule.EvictionDelay < 0 {
+		return fmt.Errorf("%w: eviction delay (%v) should not be negative", ngmodels.ErrAlertRuleFailedValidation, alertRule.EvictionDelay)
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Dynamic labels and annotations from query results
+	if st.TemplateValidator != nil {
+		if err := st.TemplateValidator(alertRule); err != nil {
+			return err
+		}
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
 	return nil
 }
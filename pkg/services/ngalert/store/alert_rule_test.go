@@ -0,0 +1,104 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNewDBstoreValidatesTemplates(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, testAlertingIntervalSeconds)
+	ctx := context.Background()
+
+	alertRule := tests.CreateTestAlertRule(t, ctx, dbstore, 60, 1)
+	st := store.NewDBstore(setting.UnifiedAlertingSettings{BaseInterval: testAlertingIntervalSeconds * time.Second}, dbstore.SQLStore, log.New("test"), state.ValidateRuleTemplates)
+
+	rule := *alertRule
+	rule.Annotations = map[string]string{"summary": "{{ $labels.instance"}
+	err := st.UpdateAlertRules(ctx, []store.UpdateRule{{Existing: alertRule, New: rule}})
+	require.True(t, errors.Is(err, models.ErrAlertRuleFailedValidation))
+
+	rule.Annotations = map[string]string{"summary": "{{ $labels.instance }}"}
+	require.NoError(t, st.UpdateAlertRules(ctx, []store.UpdateRule{{Existing: alertRule, New: rule}}))
+}
+
+func TestOptionalRuleFields(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, testAlertingIntervalSeconds)
+	ctx := context.Background()
+
+	alertRule := tests.CreateTestAlertRule(t, ctx, dbstore, 60, 1)
+	require.Zero(t, alertRule.QueryOffset)
+	require.Zero(t, alertRule.EvictionDelay)
+	require.Nil(t, alertRule.MaxInstances)
+
+	maxInstances := func(v int64) *int64 {
+		return &v
+	}
+
+	testCases := []struct {
+		name    string
+		rule    func(r *models.AlertRule)
+		invalid bool
+	}{
+		{
+			name:    "rejects a negative query offset",
+			rule:    func(r *models.AlertRule) { r.QueryOffset = -time.Minute },
+			invalid: true,
+		},
+		{
+			name: "stores the query offset of the rule",
+			rule: func(r *models.AlertRule) { r.QueryOffset = 5 * time.Minute },
+		},
+		{
+			name:    "rejects a negative eviction delay",
+			rule:    func(r *models.AlertRule) { r.EvictionDelay = -time.Hour },
+			invalid: true,
+		},
+		{
+			name: "stores the eviction delay of the rule",
+			rule: func(r *models.AlertRule) { r.EvictionDelay = 3 * time.Hour },
+		},
+		{
+			name:    "rejects a negative maximum number of alert instances",
+			rule:    func(r *models.AlertRule) { r.MaxInstances = maxInstances(-1) },
+			invalid: true,
+		},
+		{
+			name: "stores the maximum number of alert instances of the rule",
+			rule: func(r *models.AlertRule) { r.MaxInstances = maxInstances(500) },
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			q := models.GetAlertRuleByUIDQuery{OrgID: alertRule.OrgID, UID: alertRule.UID}
+			require.NoError(t, dbstore.GetAlertRuleByUID(ctx, &q))
+			existing := q.Result
+			rule := *existing
+			testCase.rule(&rule)
+
+			err := dbstore.UpdateAlertRules(ctx, []store.UpdateRule{{Existing: existing, New: rule}})
+			if testCase.invalid {
+				require.True(t, errors.Is(err, models.ErrAlertRuleFailedValidation))
+				return
+			}
+			require.NoError(t, err)
+
+			require.NoError(t, dbstore.GetAlertRuleByUID(ctx, &q))
+			stored := *q.Result
+			require.Equal(t, rule.QueryOffset, stored.QueryOffset)
+			require.Equal(t, rule.EvictionDelay, stored.EvictionDelay)
+			require.Equal(t, rule.MaxInstances, stored.MaxInstances)
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 // TimeNow makes it possible to test usage of time
@@ -36,4 +37,23 @@ type DBstore struct {
 	Logger          log.Logger
 	FolderService   dashboards.FolderService
 	AccessControl   accesscontrol.AccessControl
+	// LOGZ.IO GRAFANA CHANGE :: Dynamic labels and annotations from query results
+	// validates the label and annotation templates of the rules before they are saved, if set
+	TemplateValidator func(models.AlertRule) error
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
+
+// LOGZ.IO GRAFANA CHANGE :: Dynamic labels and annotations from query results
+// NewDBstore creates a DBstore with the intervals of the unified alerting settings that validates the label and
+// annotation templates of the rules with templateValidator, i.e. state.ValidateRuleTemplates, before saving them.
+func NewDBstore(cfg setting.UnifiedAlertingSettings, sqlStore *sqlstore.SQLStore, logger log.Logger, templateValidator func(models.AlertRule) error) *DBstore {
+	return &DBstore{
+		BaseInterval:      cfg.BaseInterval,
+		DefaultInterval:   cfg.DefaultRuleEvaluationInterval,
+		SQLStore:          sqlStore,
+		Logger:            logger,
+		TemplateValidator: templateValidator,
+	}
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
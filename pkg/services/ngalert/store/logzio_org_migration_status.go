@@ -0,0 +1,67 @@
+package store
+
+// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+
+import (
+	"context"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+const orgMigrationStatusTable = "alert_org_migration_status"
+
+type LogzioOrgMigrationStatusStore struct {
+	SQLStore *sqlstore.SQLStore
+}
+
+// GetOrgMigrationStatus returns the status of the last alert migration of the organization,
+// or ErrOrgMigrationStatusNotFound if its alerts were never migrated.
+func (st LogzioOrgMigrationStatusStore) GetOrgMigrationStatus(ctx context.Context, orgID int64) (*ngmodels.OrgMigrationStatus, error) {
+	status := &ngmodels.OrgMigrationStatus{}
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		ok, err := sess.Table(orgMigrationStatusTable).Where("org_id = ?", orgID).Get(status)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ngmodels.ErrOrgMigrationStatusNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// SaveOrgMigrationStatus creates or replaces the migration status of the organization.
+func (st LogzioOrgMigrationStatusStore) SaveOrgMigrationStatus(ctx context.Context, status *ngmodels.OrgMigrationStatus) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		existing := ngmodels.OrgMigrationStatus{}
+		has, err := sess.Table(orgMigrationStatusTable).Where("org_id = ?", status.OrgID).Get(&existing)
+		if err != nil {
+			return err
+		}
+
+		if !has {
+			status.ID = 0
+			_, err = sess.Table(orgMigrationStatusTable).Insert(status)
+			return err
+		}
+
+		status.ID = existing.ID
+		_, err = sess.Table(orgMigrationStatusTable).ID(existing.ID).AllCols().Update(status)
+		return err
+	})
+}
+
+// DeleteOrgMigrationStatus removes the migration status of the organization.
+func (st LogzioOrgMigrationStatusStore) DeleteOrgMigrationStatus(ctx context.Context, orgID int64) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM "+orgMigrationStatusTable+" WHERE org_id = ?", orgID)
+		return err
+	})
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
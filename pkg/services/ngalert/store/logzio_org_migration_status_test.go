@@ -0,0 +1,69 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogzioOrgMigrationStatusStore(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, testAlertingIntervalSeconds)
+	statusStore := store.LogzioOrgMigrationStatusStore{SQLStore: dbstore.SQLStore}
+	ctx := context.Background()
+
+	t.Run("returns not found for an organization that was never migrated", func(t *testing.T) {
+		_, err := statusStore.GetOrgMigrationStatus(ctx, 1)
+		require.ErrorIs(t, err, models.ErrOrgMigrationStatusNotFound)
+	})
+
+	t.Run("replaces the status of the organization", func(t *testing.T) {
+		startedAt := time.Unix(1000, 0).UTC()
+		err := statusStore.SaveOrgMigrationStatus(ctx, &models.OrgMigrationStatus{
+			OrgID:     2,
+			Status:    models.OrgMigrationStarted,
+			StartedAt: startedAt,
+		})
+		require.NoError(t, err)
+
+		finishedAt := startedAt.Add(time.Minute)
+		err = statusStore.SaveOrgMigrationStatus(ctx, &models.OrgMigrationStatus{
+			OrgID:       2,
+			Status:      models.OrgMigrationFailed,
+			StartedAt:   startedAt,
+			FinishedAt:  &finishedAt,
+			Alerts:      3,
+			Unsupported: 1,
+			Error:       "failed to migrate alert 1",
+		})
+		require.NoError(t, err)
+
+		status, err := statusStore.GetOrgMigrationStatus(ctx, 2)
+		require.NoError(t, err)
+		require.Equal(t, models.OrgMigrationFailed, status.Status)
+		require.Equal(t, 3, status.Alerts)
+		require.Equal(t, 1, status.Unsupported)
+		require.Equal(t, "failed to migrate alert 1", status.Error)
+		require.NotNil(t, status.FinishedAt)
+		require.True(t, finishedAt.Equal(*status.FinishedAt))
+
+		_, err = statusStore.GetOrgMigrationStatus(ctx, 3)
+		require.ErrorIs(t, err, models.ErrOrgMigrationStatusNotFound)
+	})
+
+	t.Run("deletes the status of the organization", func(t *testing.T) {
+		require.NoError(t, statusStore.SaveOrgMigrationStatus(ctx, &models.OrgMigrationStatus{
+			OrgID:     4,
+			Status:    models.OrgMigrationFinished,
+			StartedAt: time.Now(),
+		}))
+		require.NoError(t, statusStore.DeleteOrgMigrationStatus(ctx, 4))
+
+		_, err := statusStore.GetOrgMigrationStatus(ctx, 4)
+		require.ErrorIs(t, err, models.ErrOrgMigrationStatusNotFound)
+	})
+}
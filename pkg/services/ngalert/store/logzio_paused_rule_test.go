@@ -0,0 +1,42 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestPausedRules(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, testAlertingIntervalSeconds)
+	ctx := context.Background()
+
+	alertRule := tests.CreateTestAlertRule(t, ctx, dbstore, 60, 1)
+	require.False(t, alertRule.IsPaused)
+
+	getRule := func() *models.AlertRule {
+		q := models.GetAlertRuleByUIDQuery{OrgID: alertRule.OrgID, UID: alertRule.UID}
+		require.NoError(t, dbstore.GetAlertRuleByUID(ctx, &q))
+		return q.Result
+	}
+
+	paused := *alertRule
+	paused.IsPaused = true
+	require.NoError(t, dbstore.UpdateAlertRules(ctx, []store.UpdateRule{{Existing: alertRule, New: paused}}))
+	stored := getRule()
+	require.True(t, stored.IsPaused)
+
+	q := models.ListAlertRulesQuery{OrgID: alertRule.OrgID}
+	require.NoError(t, dbstore.GetAlertRulesForScheduling(ctx, &q))
+	require.Len(t, q.Result, 1)
+	require.True(t, q.Result[0].IsPaused, "paused rules are still listed for scheduling so that their routines are stopped")
+
+	resumed := *stored
+	resumed.IsPaused = false
+	require.NoError(t, dbstore.UpdateAlertRules(ctx, []store.UpdateRule{{Existing: stored, New: resumed}}))
+	require.False(t, getRule().IsPaused)
+}
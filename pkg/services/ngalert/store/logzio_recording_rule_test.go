@@ -0,0 +1,65 @@
+package store_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestRecordingRules(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, testAlertingIntervalSeconds)
+	ctx := context.Background()
+
+	alertRule := tests.CreateTestAlertRule(t, ctx, dbstore, 60, 1)
+	require.False(t, alertRule.IsRecordingRule())
+
+	rule := models.AlertRule{
+		OrgID:     1,
+		Title:     "recording rule",
+		Condition: "A",
+		Data: []models.AlertQuery{{
+			RefID:         "A",
+			DatasourceUID: "-100",
+			Model:         json.RawMessage(`{"type":"math","expression":"2 + 2"}`),
+		}},
+		IntervalSeconds: 60,
+		NamespaceUID:    "namespace",
+		RuleGroup:       "recording",
+		NoDataState:     models.NoData,
+		ExecErrState:    models.AlertingErrState,
+		Record:          &models.Record{Metric: "four:sum", From: "A"},
+	}
+
+	t.Run("rejects an invalid metric name or an unknown query", func(t *testing.T) {
+		invalid := rule
+		invalid.Record = &models.Record{Metric: "four sum", From: "A"}
+		_, err := dbstore.InsertAlertRules(ctx, []models.AlertRule{invalid})
+		require.True(t, errors.Is(err, models.ErrAlertRuleFailedValidation))
+
+		invalid.Record = &models.Record{Metric: "four:sum", From: "B"}
+		_, err = dbstore.InsertAlertRules(ctx, []models.AlertRule{invalid})
+		require.True(t, errors.Is(err, models.ErrAlertRuleFailedValidation))
+	})
+
+	t.Run("stores the record of the rule", func(t *testing.T) {
+		ids, err := dbstore.InsertAlertRules(ctx, []models.AlertRule{rule})
+		require.NoError(t, err)
+		require.Len(t, ids, 1)
+
+		q := models.ListAlertRulesQuery{OrgID: 1, NamespaceUIDs: []string{"namespace"}, RuleGroup: "recording"}
+		require.NoError(t, dbstore.ListAlertRules(ctx, &q))
+		require.Len(t, q.Result, 1)
+		require.True(t, q.Result[0].IsRecordingRule())
+		require.Equal(t, models.Record{Metric: "four:sum", From: "A"}, *q.Result[0].Record)
+
+		getQuery := models.GetAlertRuleByUIDQuery{OrgID: 1, UID: alertRule.UID}
+		require.NoError(t, dbstore.GetAlertRuleByUID(ctx, &getQuery))
+		require.Nil(t, getQuery.Result.Record)
+	})
+}
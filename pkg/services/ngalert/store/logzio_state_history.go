@@ -0,0 +1,125 @@
+package store
+
+// LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+const (
+	stateHistoryTable = "alert_state_history"
+	// stateHistoryDefaultLimit is the number of transitions returned by a query without a limit.
+	stateHistoryDefaultLimit = 1000
+	// stateHistoryDefaultMatcherBatchSize is the number of transitions read at once by a query with label matchers.
+	stateHistoryDefaultMatcherBatchSize = 1000
+)
+
+type LogzioStateHistoryStore struct {
+	SQLStore *sqlstore.SQLStore
+	// MatcherBatchSize is the number of transitions read at once by a query with label matchers, the default is
+	// stateHistoryDefaultMatcherBatchSize.
+	MatcherBatchSize int
+}
+
+// SaveStateHistory records state transitions.
+func (st LogzioStateHistoryStore) SaveStateHistory(ctx context.Context, entries ...*ngmodels.StateHistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Table(stateHistoryTable).Insert(entries)
+		return err
+	})
+}
+
+// GetStateHistory returns the state transitions that match the query, latest first. Label matchers are applied to the
+// transitions of the time range, before the limit: the transitions are then read in batches until the limit of
+// transitions matching the labels is reached.
+func (st LogzioStateHistoryStore) GetStateHistory(ctx context.Context, query *ngmodels.GetStateHistoryQuery) error {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = stateHistoryDefaultLimit
+	}
+	batchSize := limit
+	if len(query.Matchers) > 0 {
+		batchSize = st.MatcherBatchSize
+		if batchSize <= 0 {
+			batchSize = stateHistoryDefaultMatcherBatchSize
+		}
+	}
+
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		result := make([]*ngmodels.StateHistoryEntry, 0)
+		var last *ngmodels.StateHistoryEntry
+		for {
+			q := sess.Table(stateHistoryTable).Where("org_id = ?", query.OrgID)
+			if query.RuleUID != "" {
+				q = q.And("rule_uid = ?", query.RuleUID)
+			}
+			if query.State != "" {
+				q = q.And("state = ?", query.State)
+			}
+			if !query.From.IsZero() {
+				q = q.And("evaluated_at >= ?", query.From.UnixMilli())
+			}
+			if !query.To.IsZero() {
+				q = q.And("evaluated_at <= ?", query.To.UnixMilli())
+			}
+			if last != nil {
+				q = q.And("(evaluated_at < ? OR (evaluated_at = ? AND id < ?))", last.EvaluatedAt, last.EvaluatedAt, last.ID)
+			}
+			q = q.Desc("evaluated_at", "id").Limit(batchSize)
+
+			var entries []*ngmodels.StateHistoryEntry
+			if err := q.Find(&entries); err != nil {
+				return err
+			}
+			for _, entry := range entries {
+				if len(query.Matchers) > 0 && !query.Matchers.Matches(labelSet(entry.Labels)) {
+					continue
+				}
+				result = append(result, entry)
+				if len(result) == limit {
+					query.Result = result
+					return nil
+				}
+			}
+			if len(entries) < batchSize {
+				query.Result = result
+				return nil
+			}
+			last = entries[len(entries)-1]
+		}
+	})
+}
+
+// DeleteStateHistoryBefore removes the state transitions evaluated before the given time and returns how many were
+// removed.
+func (st LogzioStateHistoryStore) DeleteStateHistoryBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM "+stateHistoryTable+" WHERE evaluated_at < ?", before.UnixMilli())
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
+
+func labelSet(labels map[string]string) model.LabelSet {
+	set := make(model.LabelSet, len(labels))
+	for name, value := range labels {
+		set[model.LabelName(name)] = model.LabelValue(value)
+	}
+	return set
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
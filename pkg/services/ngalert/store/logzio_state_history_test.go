@@ -0,0 +1,104 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestLogzioStateHistoryStore(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, testAlertingIntervalSeconds)
+	historyStore := store.LogzioStateHistoryStore{SQLStore: dbstore.SQLStore}
+	ctx := context.Background()
+
+	value := 3.5
+	evaluatedAt := time.Unix(10000, 0).UTC()
+	entry := func(ruleUID, severity, state string, minutes int) *models.StateHistoryEntry {
+		return &models.StateHistoryEntry{
+			OrgID:         1,
+			RuleUID:       ruleUID,
+			LabelsHash:    ruleUID + severity,
+			Labels:        map[string]string{"alertname": ruleUID, "severity": severity},
+			PreviousState: "Normal",
+			State:         state,
+			Values:        map[string]*float64{"B": &value, "C": nil},
+			EvaluatedAt:   evaluatedAt.Add(time.Duration(minutes) * time.Minute).UnixMilli(),
+		}
+	}
+	require.NoError(t, historyStore.SaveStateHistory(ctx,
+		entry("rule-1", "critical", "Alerting", 0),
+		entry("rule-1", "warning", "Alerting", 1),
+		entry("rule-1", "critical", "Normal", 2),
+		entry("rule-2", "critical", "Alerting", 3),
+	))
+	other := entry("rule-1", "critical", "Alerting", 0)
+	other.OrgID = 2
+	require.NoError(t, historyStore.SaveStateHistory(ctx, other))
+
+	t.Run("returns the transitions of the org latest first", func(t *testing.T) {
+		q := models.GetStateHistoryQuery{OrgID: 1}
+		require.NoError(t, historyStore.GetStateHistory(ctx, &q))
+		require.Len(t, q.Result, 4)
+		require.Equal(t, "rule-2", q.Result[0].RuleUID)
+		require.Equal(t, map[string]string{"alertname": "rule-2", "severity": "critical"}, q.Result[0].Labels)
+		require.Equal(t, 3.5, *q.Result[0].Values["B"])
+		require.Nil(t, q.Result[0].Values["C"])
+	})
+
+	t.Run("filters by rule, state, time range and labels", func(t *testing.T) {
+		q := models.GetStateHistoryQuery{OrgID: 1, RuleUID: "rule-1", State: "Alerting"}
+		require.NoError(t, historyStore.GetStateHistory(ctx, &q))
+		require.Len(t, q.Result, 2)
+
+		q = models.GetStateHistoryQuery{OrgID: 1, From: evaluatedAt.Add(time.Minute), To: evaluatedAt.Add(2 * time.Minute)}
+		require.NoError(t, historyStore.GetStateHistory(ctx, &q))
+		require.Len(t, q.Result, 2)
+
+		matcher, err := labels.NewMatcher(labels.MatchEqual, "severity", "critical")
+		require.NoError(t, err)
+		q = models.GetStateHistoryQuery{OrgID: 1, Matchers: labels.Matchers{matcher}, Limit: 2}
+		require.NoError(t, historyStore.GetStateHistory(ctx, &q))
+		require.Len(t, q.Result, 2)
+		require.Equal(t, "rule-2", q.Result[0].RuleUID)
+		require.Equal(t, "Normal", q.Result[1].State)
+	})
+
+	t.Run("reads the transitions in batches until the limit of matching transitions", func(t *testing.T) {
+		batchedStore := store.LogzioStateHistoryStore{SQLStore: dbstore.SQLStore, MatcherBatchSize: 1}
+		matcher, err := labels.NewMatcher(labels.MatchEqual, "severity", "critical")
+		require.NoError(t, err)
+
+		q := models.GetStateHistoryQuery{OrgID: 1, Matchers: labels.Matchers{matcher}, Limit: 3}
+		require.NoError(t, batchedStore.GetStateHistory(ctx, &q))
+		require.Len(t, q.Result, 3)
+		require.Equal(t, []int64{
+			evaluatedAt.Add(3 * time.Minute).UnixMilli(),
+			evaluatedAt.Add(2 * time.Minute).UnixMilli(),
+			evaluatedAt.UnixMilli(),
+		}, []int64{q.Result[0].EvaluatedAt, q.Result[1].EvaluatedAt, q.Result[2].EvaluatedAt})
+
+		matcher, err = labels.NewMatcher(labels.MatchEqual, "severity", "warning")
+		require.NoError(t, err)
+		q = models.GetStateHistoryQuery{OrgID: 1, Matchers: labels.Matchers{matcher}}
+		require.NoError(t, batchedStore.GetStateHistory(ctx, &q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, "rule-1", q.Result[0].RuleUID)
+	})
+
+	t.Run("deletes the transitions before the given time", func(t *testing.T) {
+		deleted, err := historyStore.DeleteStateHistoryBefore(ctx, evaluatedAt.Add(2*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(3), deleted)
+
+		q := models.GetStateHistoryQuery{OrgID: 1}
+		require.NoError(t, historyStore.GetStateHistory(ctx, &q))
+		require.Len(t, q.Result, 2)
+	})
+}
@@ -0,0 +1,149 @@
+package writer
+
+// LOGZ.IO GRAFANA CHANGE :: Recording rules
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+)
+
+// Writer writes the series of recording rules.
+type Writer interface {
+	// Write writes the numeric values of the frames as samples of the metric at the given time. The labels of each
+	// series are the labels of its field along with the extra labels.
+	Write(ctx context.Context, metric string, t time.Time, frames data.Frames, extraLabels map[string]string) error
+}
+
+// RemoteWriter writes the series to a Prometheus remote-write endpoint.
+type RemoteWriter struct {
+	url      string
+	user     string
+	password string
+	client   *http.Client
+	log      log.Logger
+}
+
+// NewRemoteWriter returns a writer to the remote-write endpoint at the given URL. Requests are authenticated with
+// basic authentication when the user is not empty.
+func NewRemoteWriter(url, user, password string, timeout time.Duration, logger log.Logger) *RemoteWriter {
+	return &RemoteWriter{
+		url:      url,
+		user:     user,
+		password: password,
+		client:   &http.Client{Timeout: timeout},
+		log:      logger,
+	}
+}
+
+func (w *RemoteWriter) Write(ctx context.Context, metric string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	series := TimeSeries(metric, t, frames, extraLabels)
+	if len(series) == 0 {
+		w.log.Debug("no series to write", "metric", metric)
+		return nil
+	}
+
+	body, err := remotewrite.TimeSeriesToBytes(series)
+	if err != nil {
+		return fmt.Errorf("error converting time series to bytes: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error constructing remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.user != "" {
+		req.SetBasicAuth(w.user, w.password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending remote write request: %w", err)
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response code %d from remote write endpoint", resp.StatusCode)
+	}
+	w.log.Debug("series written", "metric", metric, "series", len(series))
+	return nil
+}
+
+// TimeSeries converts the numeric values of the frames to samples of the metric at the given time. Time series
+// frames are reduced to their last value. Null values and labels that are not valid Prometheus label names are
+// dropped.
+func TimeSeries(metric string, t time.Time, frames data.Frames, extraLabels map[string]string) []prompb.TimeSeries {
+	var series []prompb.TimeSeries
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			value, ok := lastValue(field)
+			if !ok {
+				continue
+			}
+			series = append(series, prompb.TimeSeries{
+				Labels:  seriesLabels(metric, field.Labels, extraLabels),
+				Samples: []prompb.Sample{{Value: value, Timestamp: t.UnixMilli()}},
+			})
+		}
+	}
+	return series
+}
+
+func lastValue(field *data.Field) (float64, bool) {
+	for i := field.Len() - 1; i >= 0; i-- {
+		if _, ok := field.ConcreteAt(i); !ok {
+			continue
+		}
+		v, err := field.FloatAt(i)
+		if err != nil {
+			return 0, false
+		}
+		return v, true
+	}
+	return 0, false
+}
+
+func seriesLabels(metric string, fieldLabels data.Labels, extraLabels map[string]string) []prompb.Label {
+	set := make(map[string]string, len(fieldLabels)+len(extraLabels)+1)
+	for name, value := range fieldLabels {
+		set[name] = value
+	}
+	for name, value := range extraLabels {
+		set[name] = value
+	}
+	set[model.MetricNameLabel] = metric
+
+	labels := make([]prompb.Label, 0, len(set))
+	for name, value := range set {
+		if !model.LabelName(name).IsValid() {
+			continue
+		}
+		labels = append(labels, prompb.Label{Name: name, Value: value})
+	}
+	// remote-write requires the labels of a series to be sorted by name
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].Name < labels[j].Name
+	})
+	return labels
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -0,0 +1,83 @@
+package writer
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestTimeSeries(t *testing.T) {
+	now := time.Unix(1000, 0)
+	value := func(v float64) *float64 { return &v }
+
+	frames := data.Frames{
+		data.NewFrame("",
+			data.NewField("B", data.Labels{"host": "a", "invalid-name": "x"}, []*float64{value(1.5)})),
+		data.NewFrame("",
+			data.NewField("B", data.Labels{"host": "b"}, []*float64{nil})),
+		data.NewFrame("",
+			data.NewField("time", nil, []time.Time{now.Add(-time.Minute), now}),
+			data.NewField("B", data.Labels{"host": "c"}, []*float64{value(2), nil})),
+	}
+
+	series := TimeSeries("host:load", now, frames, map[string]string{"team": "core"})
+	require.Equal(t, []prompb.TimeSeries{
+		{
+			Labels: []prompb.Label{
+				{Name: "__name__", Value: "host:load"},
+				{Name: "host", Value: "a"},
+				{Name: "team", Value: "core"},
+			},
+			Samples: []prompb.Sample{{Value: 1.5, Timestamp: 1000000}},
+		},
+		{
+			Labels: []prompb.Label{
+				{Name: "__name__", Value: "host:load"},
+				{Name: "host", Value: "c"},
+				{Name: "team", Value: "core"},
+			},
+			Samples: []prompb.Sample{{Value: 2, Timestamp: 1000000}},
+		},
+	}, series)
+}
+
+func TestRemoteWriter(t *testing.T) {
+	var received prompb.WriteRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "user" || password != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		compressed, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		body, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		require.NoError(t, proto.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	v := 3.0
+	frames := data.Frames{data.NewFrame("", data.NewField("A", data.Labels{"host": "a"}, []*float64{&v}))}
+
+	w := NewRemoteWriter(server.URL, "user", "password", time.Second, log.New("test"))
+	require.NoError(t, w.Write(context.Background(), "host:up", time.Unix(1, 0), frames, nil))
+	require.Len(t, received.Timeseries, 1)
+	require.Equal(t, 3.0, received.Timeseries[0].Samples[0].Value)
+
+	w = NewRemoteWriter(server.URL, "user", "wrong", time.Second, log.New("test"))
+	require.Error(t, w.Write(context.Background(), "host:up", time.Unix(1, 0), frames, nil))
+}
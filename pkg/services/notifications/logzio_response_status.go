@@ -0,0 +1,22 @@
+package notifications
+
+// LOGZ.IO GRAFANA CHANGE :: Notification delivery log
+
+import "context"
+
+type responseStatusRecorderKey struct{}
+
+// WithResponseStatusRecorder returns a context in which the status code of the response to a request sent with it is
+// stored in statusCode, so that the caller of a notifier knows the status code the notification was answered with.
+func WithResponseStatusRecorder(ctx context.Context, statusCode *int) context.Context {
+	return context.WithValue(ctx, responseStatusRecorderKey{}, statusCode)
+}
+
+// RecordResponseStatus stores the status code of a response in the recorder of the context, if any.
+func RecordResponseStatus(ctx context.Context, statusCode int) {
+	if recorder, ok := ctx.Value(responseStatusRecorderKey{}).(*int); ok {
+		*recorder = statusCode
+	}
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
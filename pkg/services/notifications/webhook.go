@@ -74,6 +74,7 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 	if err != nil {
 		return err
 	}
+	RecordResponseStatus(ctx, resp.StatusCode) // LOGZ.IO GRAFANA CHANGE :: Notification delivery log
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
 			ns.log.Warn("Failed to close response body", "err", err)
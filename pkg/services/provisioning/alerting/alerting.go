@@ -0,0 +1,256 @@
+package alerting
+
+// LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+type AlertRuleService interface {
+	GetAlertRule(ctx context.Context, orgID int64, ruleUID string) (ngmodels.AlertRule, ngmodels.Provenance, error)
+	CreateAlertRule(ctx context.Context, rule ngmodels.AlertRule, provenance ngmodels.Provenance) (ngmodels.AlertRule, error)
+	UpdateAlertRule(ctx context.Context, rule ngmodels.AlertRule, provenance ngmodels.Provenance) (ngmodels.AlertRule, error)
+	DeleteAlertRule(ctx context.Context, orgID int64, ruleUID string, provenance ngmodels.Provenance) error
+	UpdateRuleGroup(ctx context.Context, orgID int64, folderUID, rulegroup string, interval int64) error
+}
+
+type ContactPointService interface {
+	GetContactPoints(ctx context.Context, orgID int64) ([]definitions.EmbeddedContactPoint, error)
+	CreateContactPoint(ctx context.Context, orgID int64, contactPoint definitions.EmbeddedContactPoint, p ngmodels.Provenance) (definitions.EmbeddedContactPoint, error)
+	UpdateContactPoint(ctx context.Context, orgID int64, contactPoint definitions.EmbeddedContactPoint, p ngmodels.Provenance) error
+	DeleteContactPoint(ctx context.Context, orgID int64, uid string) error
+}
+
+type NotificationPolicyService interface {
+	GetPolicyTree(ctx context.Context, orgID int64) (definitions.Route, error)
+	UpdatePolicyTree(ctx context.Context, orgID int64, tree definitions.Route, p ngmodels.Provenance) error
+	ResetPolicyTree(ctx context.Context, orgID int64) (definitions.Route, error)
+}
+
+type ProvenanceStore interface {
+	GetProvenances(ctx context.Context, orgID int64, resourceType string) (map[string]ngmodels.Provenance, error)
+}
+
+// ProvisionerConfig holds the services the alerting provisioner provisions through.
+type ProvisionerConfig struct {
+	RuleService         AlertRuleService
+	ContactPointService ContactPointService
+	PolicyService       NotificationPolicyService
+	ProvenanceStore     ProvenanceStore
+	// RuleOrgStore lists the orgs whose alerting is reconciled with the files.
+	RuleOrgStore store.OrgStore
+	OrgStore     utils.OrgStore
+}
+
+// Provision alert rules, contact points and notification policies
+func Provision(ctx context.Context, configDirectory string, cfg ProvisionerConfig) error {
+	ap := newAlertingProvisioner(cfg, log.New("provisioning.alerting"))
+	return ap.applyChanges(ctx, configDirectory)
+}
+
+// AlertingProvisioner is responsible for provisioning unified alerting. Everything it provisions is marked with
+// the file provenance, and everything with the file provenance that is no longer in the files is removed.
+type AlertingProvisioner struct {
+	log         log.Logger
+	cfgProvider *configReader
+	cfg         ProvisionerConfig
+}
+
+func newAlertingProvisioner(cfg ProvisionerConfig, log log.Logger) AlertingProvisioner {
+	return AlertingProvisioner{
+		log:         log,
+		cfgProvider: &configReader{orgStore: cfg.OrgStore, log: log},
+		cfg:         cfg,
+	}
+}
+
+func (ap *AlertingProvisioner) applyChanges(ctx context.Context, configPath string) error {
+	configs, err := ap.cfgProvider.readConfig(ctx, configPath)
+	if err != nil {
+		return err
+	}
+
+	orgIDs, err := ap.cfg.RuleOrgStore.GetOrgs(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		groups        []*alertRuleGroup
+		contactPoints []*contactPoint
+		policies      []*notificationPolicy
+	)
+	for _, cfg := range configs {
+		groups = append(groups, cfg.Groups...)
+		contactPoints = append(contactPoints, cfg.ContactPoints...)
+		policies = append(policies, cfg.Policies...)
+	}
+
+	// Contact points go first and are removed last, as the notification policies refer to them.
+	if err := ap.provisionContactPoints(ctx, contactPoints); err != nil {
+		return err
+	}
+	if err := ap.provisionPolicies(ctx, orgIDs, policies); err != nil {
+		return err
+	}
+	if err := ap.removeContactPoints(ctx, orgIDs, contactPoints); err != nil {
+		return err
+	}
+	return ap.provisionRules(ctx, orgIDs, groups)
+}
+
+func (ap *AlertingProvisioner) provisionContactPoints(ctx context.Context, contactPoints []*contactPoint) error {
+	existing := map[int64]map[string]struct{}{}
+	for _, cp := range contactPoints {
+		if _, ok := existing[cp.OrgID]; !ok {
+			stored, err := ap.cfg.ContactPointService.GetContactPoints(ctx, cp.OrgID)
+			if err != nil {
+				return err
+			}
+			existing[cp.OrgID] = map[string]struct{}{}
+			for _, s := range stored {
+				existing[cp.OrgID][s.UID] = struct{}{}
+			}
+		}
+
+		if _, ok := existing[cp.OrgID][cp.ContactPoint.UID]; ok {
+			ap.log.Debug("Updating contact point", "org", cp.OrgID, "name", cp.Name, "uid", cp.ContactPoint.UID)
+			if err := ap.cfg.ContactPointService.UpdateContactPoint(ctx, cp.OrgID, cp.ContactPoint, ngmodels.ProvenanceFile); err != nil {
+				return fmt.Errorf("failed to update contact point %q: %w", cp.ContactPoint.UID, err)
+			}
+			continue
+		}
+		ap.log.Debug("Creating contact point", "org", cp.OrgID, "name", cp.Name, "uid", cp.ContactPoint.UID)
+		if _, err := ap.cfg.ContactPointService.CreateContactPoint(ctx, cp.OrgID, cp.ContactPoint, ngmodels.ProvenanceFile); err != nil {
+			return fmt.Errorf("failed to create contact point %q: %w", cp.ContactPoint.UID, err)
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) removeContactPoints(ctx context.Context, orgIDs []int64, contactPoints []*contactPoint) error {
+	provisioned := map[int64]map[string]struct{}{}
+	for _, cp := range contactPoints {
+		if _, ok := provisioned[cp.OrgID]; !ok {
+			provisioned[cp.OrgID] = map[string]struct{}{}
+		}
+		provisioned[cp.OrgID][cp.ContactPoint.UID] = struct{}{}
+	}
+
+	for _, orgID := range orgIDs {
+		stored, err := ap.cfg.ContactPointService.GetContactPoints(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		for _, s := range stored {
+			if s.Provenance != string(ngmodels.ProvenanceFile) {
+				continue
+			}
+			if _, ok := provisioned[orgID][s.UID]; ok {
+				continue
+			}
+			ap.log.Info("Deleting contact point that is no longer provisioned", "org", orgID, "name", s.Name, "uid", s.UID)
+			if err := ap.cfg.ContactPointService.DeleteContactPoint(ctx, orgID, s.UID); err != nil {
+				return fmt.Errorf("failed to delete contact point %q: %w", s.UID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) provisionPolicies(ctx context.Context, orgIDs []int64, policies []*notificationPolicy) error {
+	provisioned := map[int64]struct{}{}
+	for _, policy := range policies {
+		ap.log.Debug("Updating notification policy", "org", policy.OrgID)
+		if err := ap.cfg.PolicyService.UpdatePolicyTree(ctx, policy.OrgID, policy.Policy, ngmodels.ProvenanceFile); err != nil {
+			return fmt.Errorf("failed to update notification policy of org %d: %w", policy.OrgID, err)
+		}
+		provisioned[policy.OrgID] = struct{}{}
+	}
+
+	for _, orgID := range orgIDs {
+		if _, ok := provisioned[orgID]; ok {
+			continue
+		}
+		tree, err := ap.cfg.PolicyService.GetPolicyTree(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		if tree.Provenance != ngmodels.ProvenanceFile {
+			continue
+		}
+		ap.log.Info("Resetting notification policy that is no longer provisioned", "org", orgID)
+		if _, err := ap.cfg.PolicyService.ResetPolicyTree(ctx, orgID); err != nil {
+			return fmt.Errorf("failed to reset notification policy of org %d: %w", orgID, err)
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) provisionRules(ctx context.Context, orgIDs []int64, groups []*alertRuleGroup) error {
+	provisioned := map[int64]map[string]struct{}{}
+	for _, group := range groups {
+		if _, ok := provisioned[group.OrgID]; !ok {
+			provisioned[group.OrgID] = map[string]struct{}{}
+		}
+		for _, rule := range group.Rules {
+			if err := ap.provisionRule(ctx, rule); err != nil {
+				return err
+			}
+			provisioned[group.OrgID][rule.UID] = struct{}{}
+		}
+		if err := ap.cfg.RuleService.UpdateRuleGroup(ctx, group.OrgID, group.FolderUID, group.Name, int64(group.Interval.Seconds())); err != nil {
+			return fmt.Errorf("failed to update rule group %q: %w", group.Name, err)
+		}
+	}
+
+	for _, orgID := range orgIDs {
+		provenances, err := ap.cfg.ProvenanceStore.GetProvenances(ctx, orgID, (&ngmodels.AlertRule{}).ResourceType())
+		if err != nil {
+			return err
+		}
+		for uid, provenance := range provenances {
+			if provenance != ngmodels.ProvenanceFile {
+				continue
+			}
+			if _, ok := provisioned[orgID][uid]; ok {
+				continue
+			}
+			ap.log.Info("Deleting rule that is no longer provisioned", "org", orgID, "uid", uid)
+			if err := ap.cfg.RuleService.DeleteAlertRule(ctx, orgID, uid, ngmodels.ProvenanceFile); err != nil {
+				return fmt.Errorf("failed to delete rule %q: %w", uid, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) provisionRule(ctx context.Context, rule ngmodels.AlertRule) error {
+	_, _, err := ap.cfg.RuleService.GetAlertRule(ctx, rule.OrgID, rule.UID)
+	if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		ap.log.Debug("Creating rule", "org", rule.OrgID, "uid", rule.UID, "group", rule.RuleGroup)
+		if _, err := ap.cfg.RuleService.CreateAlertRule(ctx, rule, ngmodels.ProvenanceFile); err != nil {
+			return fmt.Errorf("failed to create rule %q: %w", rule.UID, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ap.log.Debug("Updating rule", "org", rule.OrgID, "uid", rule.UID, "group", rule.RuleGroup)
+	if _, err := ap.cfg.RuleService.UpdateAlertRule(ctx, rule, ngmodels.ProvenanceFile); err != nil {
+		return fmt.Errorf("failed to update rule %q: %w", rule.UID, err)
+	}
+	return nil
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -0,0 +1,231 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestAlertingProvisioner(t *testing.T) {
+	t.Run("provisions rules, contact points and policies with file provenance", func(t *testing.T) {
+		rules := newFakeRuleService()
+		contactPoints := newFakeContactPointService()
+		policies := newFakePolicyService()
+		ap := newTestProvisioner(rules, contactPoints, policies)
+
+		require.NoError(t, ap.applyChanges(context.Background(), correctProperties))
+
+		rule, provenance, err := rules.GetAlertRule(context.Background(), 1, "high-cpu")
+		require.NoError(t, err)
+		require.Equal(t, ngmodels.ProvenanceFile, provenance)
+		require.Equal(t, "High CPU usage", rule.Title)
+		require.Equal(t, int64(120), rules.groupIntervals["cpu"])
+
+		require.Len(t, contactPoints.contactPoints[2], 1)
+		require.Equal(t, string(ngmodels.ProvenanceFile), contactPoints.contactPoints[2][0].Provenance)
+
+		require.Equal(t, "ops-email", policies.trees[2].Receiver)
+		require.Equal(t, ngmodels.ProvenanceFile, policies.trees[2].Provenance)
+	})
+
+	t.Run("updates what already exists", func(t *testing.T) {
+		rules := newFakeRuleService()
+		rules.rules[ruleKey(1, "high-cpu")] = ngmodels.AlertRule{OrgID: 1, UID: "high-cpu", Title: "old title"}
+		contactPoints := newFakeContactPointService()
+		contactPoints.contactPoints[2] = []definitions.EmbeddedContactPoint{{UID: "ops-email-1", Name: "old-name"}}
+		ap := newTestProvisioner(rules, contactPoints, newFakePolicyService())
+
+		require.NoError(t, ap.applyChanges(context.Background(), correctProperties))
+
+		rule, provenance, err := rules.GetAlertRule(context.Background(), 1, "high-cpu")
+		require.NoError(t, err)
+		require.Equal(t, ngmodels.ProvenanceFile, provenance)
+		require.Equal(t, "High CPU usage", rule.Title)
+		require.Len(t, contactPoints.contactPoints[2], 1)
+		require.Equal(t, "ops-email", contactPoints.contactPoints[2][0].Name)
+	})
+
+	t.Run("removes what is no longer in the files", func(t *testing.T) {
+		rules := newFakeRuleService()
+		rules.rules[ruleKey(1, "removed")] = ngmodels.AlertRule{OrgID: 1, UID: "removed"}
+		rules.provenances[ruleKey(1, "removed")] = ngmodels.ProvenanceFile
+		rules.rules[ruleKey(1, "created-in-ui")] = ngmodels.AlertRule{OrgID: 1, UID: "created-in-ui"}
+		contactPoints := newFakeContactPointService()
+		contactPoints.contactPoints[1] = []definitions.EmbeddedContactPoint{
+			{UID: "removed", Provenance: string(ngmodels.ProvenanceFile)},
+			{UID: "created-in-ui"},
+		}
+		policies := newFakePolicyService()
+		policies.trees[1] = definitions.Route{Receiver: "removed", Provenance: ngmodels.ProvenanceFile}
+		ap := newTestProvisioner(rules, contactPoints, policies)
+
+		require.NoError(t, ap.applyChanges(context.Background(), correctProperties))
+
+		_, _, err := rules.GetAlertRule(context.Background(), 1, "removed")
+		require.ErrorIs(t, err, ngmodels.ErrAlertRuleNotFound)
+		_, _, err = rules.GetAlertRule(context.Background(), 1, "created-in-ui")
+		require.NoError(t, err)
+		require.Equal(t, []definitions.EmbeddedContactPoint{{UID: "created-in-ui"}}, contactPoints.contactPoints[1])
+		require.Equal(t, "default", policies.trees[1].Receiver)
+	})
+}
+
+func newTestProvisioner(rules *fakeRuleService, contactPoints *fakeContactPointService, policies *fakePolicyService) AlertingProvisioner {
+	orgs := newFakeOrgStore(1, 2)
+	return newAlertingProvisioner(ProvisionerConfig{
+		RuleService:         rules,
+		ContactPointService: contactPoints,
+		PolicyService:       policies,
+		ProvenanceStore:     rules,
+		RuleOrgStore:        orgs,
+		OrgStore:            orgs,
+	}, log.New("test logger"))
+}
+
+type fakeOrgStore struct {
+	orgs []int64
+}
+
+func newFakeOrgStore(orgs ...int64) *fakeOrgStore {
+	return &fakeOrgStore{orgs: orgs}
+}
+
+func (f *fakeOrgStore) GetOrgById(_ context.Context, query *models.GetOrgByIdQuery) error {
+	for _, org := range f.orgs {
+		if org == query.Id {
+			query.Result = &models.Org{Id: org}
+			return nil
+		}
+	}
+	return models.ErrOrgNotFound
+}
+
+func (f *fakeOrgStore) GetOrgs(_ context.Context) ([]int64, error) {
+	return f.orgs, nil
+}
+
+type fakeRuleService struct {
+	rules          map[string]ngmodels.AlertRule
+	provenances    map[string]ngmodels.Provenance
+	groupIntervals map[string]int64
+}
+
+func newFakeRuleService() *fakeRuleService {
+	return &fakeRuleService{
+		rules:          map[string]ngmodels.AlertRule{},
+		provenances:    map[string]ngmodels.Provenance{},
+		groupIntervals: map[string]int64{},
+	}
+}
+
+func ruleKey(orgID int64, uid string) string {
+	return fmt.Sprintf("%d/%s", orgID, uid)
+}
+
+func (f *fakeRuleService) GetAlertRule(_ context.Context, orgID int64, ruleUID string) (ngmodels.AlertRule, ngmodels.Provenance, error) {
+	rule, ok := f.rules[ruleKey(orgID, ruleUID)]
+	if !ok {
+		return ngmodels.AlertRule{}, ngmodels.ProvenanceNone, ngmodels.ErrAlertRuleNotFound
+	}
+	return rule, f.provenances[ruleKey(orgID, ruleUID)], nil
+}
+
+func (f *fakeRuleService) CreateAlertRule(_ context.Context, rule ngmodels.AlertRule, provenance ngmodels.Provenance) (ngmodels.AlertRule, error) {
+	f.rules[ruleKey(rule.OrgID, rule.UID)] = rule
+	f.provenances[ruleKey(rule.OrgID, rule.UID)] = provenance
+	return rule, nil
+}
+
+func (f *fakeRuleService) UpdateAlertRule(ctx context.Context, rule ngmodels.AlertRule, provenance ngmodels.Provenance) (ngmodels.AlertRule, error) {
+	return f.CreateAlertRule(ctx, rule, provenance)
+}
+
+func (f *fakeRuleService) DeleteAlertRule(_ context.Context, orgID int64, ruleUID string, _ ngmodels.Provenance) error {
+	delete(f.rules, ruleKey(orgID, ruleUID))
+	delete(f.provenances, ruleKey(orgID, ruleUID))
+	return nil
+}
+
+func (f *fakeRuleService) UpdateRuleGroup(_ context.Context, _ int64, _, rulegroup string, interval int64) error {
+	f.groupIntervals[rulegroup] = interval
+	return nil
+}
+
+func (f *fakeRuleService) GetProvenances(_ context.Context, orgID int64, _ string) (map[string]ngmodels.Provenance, error) {
+	result := map[string]ngmodels.Provenance{}
+	for _, rule := range f.rules {
+		if rule.OrgID == orgID {
+			result[rule.UID] = f.provenances[ruleKey(orgID, rule.UID)]
+		}
+	}
+	return result, nil
+}
+
+type fakeContactPointService struct {
+	contactPoints map[int64][]definitions.EmbeddedContactPoint
+}
+
+func newFakeContactPointService() *fakeContactPointService {
+	return &fakeContactPointService{contactPoints: map[int64][]definitions.EmbeddedContactPoint{}}
+}
+
+func (f *fakeContactPointService) GetContactPoints(_ context.Context, orgID int64) ([]definitions.EmbeddedContactPoint, error) {
+	return f.contactPoints[orgID], nil
+}
+
+func (f *fakeContactPointService) CreateContactPoint(_ context.Context, orgID int64, contactPoint definitions.EmbeddedContactPoint, p ngmodels.Provenance) (definitions.EmbeddedContactPoint, error) {
+	contactPoint.Provenance = string(p)
+	f.contactPoints[orgID] = append(f.contactPoints[orgID], contactPoint)
+	return contactPoint, nil
+}
+
+func (f *fakeContactPointService) UpdateContactPoint(_ context.Context, orgID int64, contactPoint definitions.EmbeddedContactPoint, p ngmodels.Provenance) error {
+	contactPoint.Provenance = string(p)
+	for i, cp := range f.contactPoints[orgID] {
+		if cp.UID == contactPoint.UID {
+			f.contactPoints[orgID][i] = contactPoint
+		}
+	}
+	return nil
+}
+
+func (f *fakeContactPointService) DeleteContactPoint(_ context.Context, orgID int64, uid string) error {
+	var remaining []definitions.EmbeddedContactPoint
+	for _, cp := range f.contactPoints[orgID] {
+		if cp.UID != uid {
+			remaining = append(remaining, cp)
+		}
+	}
+	f.contactPoints[orgID] = remaining
+	return nil
+}
+
+type fakePolicyService struct {
+	trees map[int64]definitions.Route
+}
+
+func newFakePolicyService() *fakePolicyService {
+	return &fakePolicyService{trees: map[int64]definitions.Route{}}
+}
+
+func (f *fakePolicyService) GetPolicyTree(_ context.Context, orgID int64) (definitions.Route, error) {
+	return f.trees[orgID], nil
+}
+
+func (f *fakePolicyService) UpdatePolicyTree(_ context.Context, orgID int64, tree definitions.Route, p ngmodels.Provenance) error {
+	tree.Provenance = p
+	f.trees[orgID] = tree
+	return nil
+}
+
+func (f *fakePolicyService) ResetPolicyTree(_ context.Context, orgID int64) (definitions.Route, error) {
+	f.trees[orgID] = definitions.Route{Receiver: "default"}
+	return f.trees[orgID], nil
+}
@@ -0,0 +1,202 @@
+package alerting
+
+// LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+type configReader struct {
+	orgStore utils.OrgStore
+	log      log.Logger
+}
+
+func (cr *configReader) readConfig(ctx context.Context, path string) ([]*alertingAsConfig, error) {
+	var configs []*alertingAsConfig
+	cr.log.Debug("Looking for alerting provisioning files", "path", path)
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Can't read alerting provisioning files from directory", "path", path, "error", err)
+		return configs, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cr.log.Debug("Parsing alerting provisioning file", "path", path, "file.Name", file.Name())
+			cfg, err := cr.parseConfig(path, file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse alerting provisioning file %q: %w", file.Name(), err)
+			}
+
+			if cfg != nil {
+				configs = append(configs, cfg)
+			}
+		}
+	}
+
+	cr.log.Debug("Validating alerting provisioning files")
+	if err := cr.validateRequiredFields(configs); err != nil {
+		return nil, err
+	}
+
+	if err := cr.checkOrgIDs(ctx, configs); err != nil {
+		return nil, err
+	}
+
+	if err := validateUniqueness(configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (cr *configReader) parseConfig(path string, file os.FileInfo) (*alertingAsConfig, error) {
+	filename, _ := filepath.Abs(filepath.Join(path, file.Name()))
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *alertingAsConfigV1
+	if err := yaml.Unmarshal(yamlFile, &cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg.mapToAlertingFromConfig()
+}
+
+// checkOrgIDs defaults the org of everything without one to the main org and checks that the other orgs exist.
+func (cr *configReader) checkOrgIDs(ctx context.Context, configs []*alertingAsConfig) error {
+	checkOrg := func(orgID *int64, kind, name string) error {
+		if *orgID < 1 {
+			*orgID = 1
+			return nil
+		}
+		if err := utils.CheckOrgExists(ctx, cr.orgStore, *orgID); err != nil {
+			return fmt.Errorf("failed to provision %s %q: %w", kind, name, err)
+		}
+		return nil
+	}
+
+	for _, cfg := range configs {
+		for _, group := range cfg.Groups {
+			if err := checkOrg(&group.OrgID, "rule group", group.Name); err != nil {
+				return err
+			}
+			for i := range group.Rules {
+				group.Rules[i].OrgID = group.OrgID
+			}
+		}
+		for _, cp := range cfg.ContactPoints {
+			if err := checkOrg(&cp.OrgID, "contact point", cp.Name); err != nil {
+				return err
+			}
+		}
+		for _, policy := range cfg.Policies {
+			if err := checkOrg(&policy.OrgID, "notification policy", policy.Policy.Receiver); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (cr *configReader) validateRequiredFields(configs []*alertingAsConfig) error {
+	var errStrings []string
+	for _, cfg := range configs {
+		for index, group := range cfg.Groups {
+			if group.Name == "" {
+				errStrings = append(errStrings, fmt.Sprintf("Rule group %d in configuration doesn't contain required field name", index+1))
+			}
+			if group.FolderUID == "" {
+				errStrings = append(errStrings, fmt.Sprintf("Rule group %d in configuration doesn't contain required field folderUid", index+1))
+			}
+			if group.Interval <= 0 {
+				errStrings = append(errStrings, fmt.Sprintf("Rule group %d in configuration doesn't contain required field interval", index+1))
+			}
+			for ruleIndex, rule := range group.Rules {
+				for _, field := range missingFields("uid", rule.UID, "title", rule.Title, "condition", rule.Condition) {
+					errStrings = append(errStrings, fmt.Sprintf("Rule %d of rule group %q in configuration doesn't contain required field %s", ruleIndex+1, group.Name, field))
+				}
+				if len(rule.Data) == 0 {
+					errStrings = append(errStrings, fmt.Sprintf("Rule %d of rule group %q in configuration doesn't contain required field data", ruleIndex+1, group.Name))
+				}
+			}
+		}
+
+		for index, cp := range cfg.ContactPoints {
+			for _, field := range missingFields("name", cp.Name, "uid", cp.ContactPoint.UID, "type", cp.ContactPoint.Type) {
+				errStrings = append(errStrings, fmt.Sprintf("Contact point receiver %d in configuration doesn't contain required field %s", index+1, field))
+			}
+		}
+
+		for index, policy := range cfg.Policies {
+			if policy.Policy.Receiver == "" {
+				errStrings = append(errStrings, fmt.Sprintf("Notification policy %d in configuration doesn't contain required field receiver", index+1))
+			}
+		}
+	}
+
+	if len(errStrings) != 0 {
+		return fmt.Errorf(strings.Join(errStrings, "\n"))
+	}
+	return nil
+}
+
+// missingFields returns the names of the empty fields, given as pairs of field name and value.
+func missingFields(fieldsAndValues ...string) []string {
+	var missing []string
+	for i := 0; i+1 < len(fieldsAndValues); i += 2 {
+		if fieldsAndValues[i+1] == "" {
+			missing = append(missing, fieldsAndValues[i])
+		}
+	}
+	return missing
+}
+
+// validateUniqueness checks that no rule or contact point is declared twice and that every org has a single
+// notification policy tree.
+func validateUniqueness(configs []*alertingAsConfig) error {
+	ruleUIDs := map[string]struct{}{}
+	contactPointUIDs := map[string]struct{}{}
+	policyOrgs := map[int64]struct{}{}
+	for _, cfg := range configs {
+		for _, group := range cfg.Groups {
+			for _, rule := range group.Rules {
+				if _, exists := ruleUIDs[rule.UID]; exists {
+					return fmt.Errorf("rule with uid %q is provisioned more than once", rule.UID)
+				}
+				ruleUIDs[rule.UID] = struct{}{}
+			}
+		}
+		for _, cp := range cfg.ContactPoints {
+			if _, exists := contactPointUIDs[cp.ContactPoint.UID]; exists {
+				return fmt.Errorf("contact point with uid %q is provisioned more than once", cp.ContactPoint.UID)
+			}
+			contactPointUIDs[cp.ContactPoint.UID] = struct{}{}
+		}
+		for _, policy := range cfg.Policies {
+			if _, exists := policyOrgs[policy.OrgID]; exists {
+				return fmt.Errorf("notification policy of org %d is provisioned more than once", policy.OrgID)
+			}
+			policyOrgs[policy.OrgID] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
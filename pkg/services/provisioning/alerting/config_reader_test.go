@@ -0,0 +1,109 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+var (
+	correctProperties = "./testdata/test-configs/correct-properties"
+	noRequiredFields  = "./testdata/test-configs/no-required-fields"
+	duplicateRules    = "./testdata/test-configs/duplicate-rules"
+	emptyFolder       = "./testdata/test-configs/empty_folder"
+)
+
+func TestAlertingAsConfig(t *testing.T) {
+	newConfigReader := func(orgs ...int64) *configReader {
+		return &configReader{orgStore: newFakeOrgStore(orgs...), log: log.New("test logger")}
+	}
+
+	t.Run("Can read correct properties", func(t *testing.T) {
+		_ = os.Setenv("TEST_VAR", "ops")
+		cfg, err := newConfigReader(1, 2).readConfig(context.Background(), correctProperties)
+		_ = os.Unsetenv("TEST_VAR")
+		require.NoError(t, err)
+		require.Len(t, cfg, 1)
+
+		require.Len(t, cfg[0].ContactPoints, 1)
+		cp := cfg[0].ContactPoints[0]
+		require.Equal(t, int64(2), cp.OrgID)
+		require.Equal(t, "ops-email", cp.ContactPoint.Name)
+		require.Equal(t, "ops-email-1", cp.ContactPoint.UID)
+		require.Equal(t, "email", cp.ContactPoint.Type)
+		require.Equal(t, "ops@example.com", cp.ContactPoint.Settings.Get("addresses").MustString())
+
+		require.Len(t, cfg[0].Policies, 1)
+		policy := cfg[0].Policies[0]
+		require.Equal(t, int64(2), policy.OrgID)
+		require.Equal(t, "ops-email", policy.Policy.Receiver)
+		require.Equal(t, []string{"alertname"}, policy.Policy.GroupByStr)
+		require.Len(t, policy.Policy.Routes, 1)
+		require.Len(t, policy.Policy.Routes[0].ObjectMatchers, 1)
+
+		require.Len(t, cfg[0].Groups, 1)
+		group := cfg[0].Groups[0]
+		require.Equal(t, int64(1), group.OrgID)
+		require.Equal(t, "cpu", group.Name)
+		require.Equal(t, "folder-uid", group.FolderUID)
+		require.Equal(t, 2*time.Minute, group.Interval)
+
+		require.Len(t, group.Rules, 1)
+		rule := group.Rules[0]
+		require.Equal(t, int64(1), rule.OrgID)
+		require.Equal(t, "high-cpu", rule.UID)
+		require.Equal(t, "High CPU usage", rule.Title)
+		require.Equal(t, "B", rule.Condition)
+		require.Equal(t, "folder-uid", rule.NamespaceUID)
+		require.Equal(t, "cpu", rule.RuleGroup)
+		require.Equal(t, int64(120), rule.IntervalSeconds)
+		require.Equal(t, 5*time.Minute, rule.For)
+		require.Equal(t, ngmodels.OK, rule.NoDataState)
+		require.Equal(t, ngmodels.AlertingErrState, rule.ExecErrState)
+		require.Equal(t, "dashboard-uid", *rule.DashboardUID)
+		require.Equal(t, int64(3), *rule.PanelID)
+		require.Equal(t, map[string]string{"team": "ops"}, rule.Labels)
+		require.Equal(t, map[string]string{"summary": "CPU usage is high"}, rule.Annotations)
+
+		require.Len(t, rule.Data, 2)
+		require.Equal(t, "A", rule.Data[0].RefID)
+		require.Equal(t, "prometheus-uid", rule.Data[0].DatasourceUID)
+		require.Equal(t, ngmodels.Duration(10*time.Minute), rule.Data[0].RelativeTimeRange.From)
+		var expression map[string]interface{}
+		require.NoError(t, json.Unmarshal(rule.Data[1].Model, &expression))
+		require.Equal(t, "$A > 0.9", expression["expression"])
+	})
+
+	t.Run("Should fail if orgs do not exist", func(t *testing.T) {
+		_, err := newConfigReader(1).readConfig(context.Background(), correctProperties)
+		require.ErrorIs(t, err, models.ErrOrgNotFound)
+	})
+
+	t.Run("Should fail if required fields are missing", func(t *testing.T) {
+		_, err := newConfigReader(1).readConfig(context.Background(), noRequiredFields)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Rule group 1 in configuration doesn't contain required field folderUid")
+		require.Contains(t, err.Error(), `Rule 1 of rule group "cpu" in configuration doesn't contain required field title`)
+		require.Contains(t, err.Error(), `Rule 1 of rule group "cpu" in configuration doesn't contain required field data`)
+		require.Contains(t, err.Error(), "Contact point receiver 1 in configuration doesn't contain required field uid")
+	})
+
+	t.Run("Should fail if a rule is provisioned more than once", func(t *testing.T) {
+		_, err := newConfigReader(1).readConfig(context.Background(), duplicateRules)
+		require.EqualError(t, err, `rule with uid "high-cpu" is provisioned more than once`)
+	})
+
+	t.Run("Empty folder should return empty config", func(t *testing.T) {
+		cfg, err := newConfigReader(1).readConfig(context.Background(), emptyFolder)
+		require.NoError(t, err)
+		require.Empty(t, cfg)
+	})
+}
@@ -0,0 +1,285 @@
+package alerting
+
+// LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+// alertingAsConfig is normalized data object for unified alerting config data. Any config version should be mappable
+// to this type.
+type alertingAsConfig struct {
+	Groups        []*alertRuleGroup
+	ContactPoints []*contactPoint
+	Policies      []*notificationPolicy
+}
+
+type alertRuleGroup struct {
+	OrgID     int64
+	Name      string
+	FolderUID string
+	Interval  time.Duration
+	Rules     []ngmodels.AlertRule
+}
+
+type contactPoint struct {
+	OrgID        int64
+	Name         string
+	ContactPoint definitions.EmbeddedContactPoint
+}
+
+type notificationPolicy struct {
+	OrgID  int64
+	Policy definitions.Route
+}
+
+// alertingAsConfigV1 is mapping for version 1 configs. This is mapped to its normalised version.
+type alertingAsConfigV1 struct {
+	APIVersion    values.Int64Value       `json:"apiVersion" yaml:"apiVersion"`
+	Groups        []*alertRuleGroupV1     `json:"groups" yaml:"groups"`
+	ContactPoints []*contactPointV1       `json:"contactPoints" yaml:"contactPoints"`
+	Policies      []*notificationPolicyV1 `json:"policies" yaml:"policies"`
+}
+
+type alertRuleGroupV1 struct {
+	OrgID     values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name      values.StringValue `json:"name" yaml:"name"`
+	FolderUID values.StringValue `json:"folderUid" yaml:"folderUid"`
+	Interval  values.StringValue `json:"interval" yaml:"interval"`
+	Rules     []*alertRuleV1     `json:"rules" yaml:"rules"`
+}
+
+type alertRuleV1 struct {
+	UID           values.StringValue    `json:"uid" yaml:"uid"`
+	Title         values.StringValue    `json:"title" yaml:"title"`
+	Condition     values.StringValue    `json:"condition" yaml:"condition"`
+	Data          []*alertQueryV1       `json:"data" yaml:"data"`
+	DashboardUID  values.StringValue    `json:"dashboardUid" yaml:"dashboardUid"`
+	PanelID       values.Int64Value     `json:"panelId" yaml:"panelId"`
+	NoDataState   values.StringValue    `json:"noDataState" yaml:"noDataState"`
+	ExecErrState  values.StringValue    `json:"execErrState" yaml:"execErrState"`
+	For           values.StringValue    `json:"for" yaml:"for"`
+	Annotations   values.StringMapValue `json:"annotations" yaml:"annotations"`
+	Labels        values.StringMapValue `json:"labels" yaml:"labels"`
+	IsPaused      values.BoolValue      `json:"isPaused" yaml:"isPaused"`           // LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+	KeepFiringFor values.StringValue    `json:"keepFiringFor" yaml:"keepFiringFor"` // LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+	MaxInstances  values.StringValue    `json:"maxInstances" yaml:"maxInstances"`   // LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+	EvictionDelay values.StringValue    `json:"evictionDelay" yaml:"evictionDelay"` // LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+}
+
+type alertQueryV1 struct {
+	RefID             values.StringValue  `json:"refId" yaml:"refId"`
+	QueryType         values.StringValue  `json:"queryType" yaml:"queryType"`
+	RelativeTimeRange relativeTimeRangeV1 `json:"relativeTimeRange" yaml:"relativeTimeRange"`
+	DatasourceUID     values.StringValue  `json:"datasourceUid" yaml:"datasourceUid"`
+	Model             values.JSONValue    `json:"model" yaml:"model"`
+}
+
+// relativeTimeRangeV1 is the relative time range of a query, in seconds.
+type relativeTimeRangeV1 struct {
+	From values.Int64Value `json:"from" yaml:"from"`
+	To   values.Int64Value `json:"to" yaml:"to"`
+}
+
+type contactPointV1 struct {
+	OrgID     values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name      values.StringValue `json:"name" yaml:"name"`
+	Receivers []*receiverV1      `json:"receivers" yaml:"receivers"`
+}
+
+type receiverV1 struct {
+	UID                   values.StringValue `json:"uid" yaml:"uid"`
+	Type                  values.StringValue `json:"type" yaml:"type"`
+	Settings              values.JSONValue   `json:"settings" yaml:"settings"`
+	DisableResolveMessage values.BoolValue   `json:"disableResolveMessage" yaml:"disableResolveMessage"`
+}
+
+type notificationPolicyV1 struct {
+	OrgID  values.Int64Value
+	Policy definitions.Route
+}
+
+// UnmarshalYAML reads the org of the policy and the policy tree, which is declared next to the org.
+func (p *notificationPolicyV1) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var org struct {
+		OrgID values.Int64Value `yaml:"orgId"`
+	}
+	if err := unmarshal(&org); err != nil {
+		return err
+	}
+	p.OrgID = org.OrgID
+	return unmarshal(&p.Policy)
+}
+
+// mapToAlertingFromConfig maps config syntax to normalized alertingAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *alertingAsConfigV1) mapToAlertingFromConfig() (*alertingAsConfig, error) {
+	r := &alertingAsConfig{}
+	if cfg == nil {
+		return r, nil
+	}
+
+	for _, group := range cfg.Groups {
+		g, err := group.mapToModel()
+		if err != nil {
+			return nil, err
+		}
+		r.Groups = append(r.Groups, g)
+	}
+
+	for _, cp := range cfg.ContactPoints {
+		for _, receiver := range cp.Receivers {
+			r.ContactPoints = append(r.ContactPoints, &contactPoint{
+				OrgID: cp.OrgID.Value(),
+				Name:  cp.Name.Value(),
+				ContactPoint: definitions.EmbeddedContactPoint{
+					UID:                   receiver.UID.Value(),
+					Name:                  cp.Name.Value(),
+					Type:                  receiver.Type.Value(),
+					Settings:              simplejson.NewFromAny(receiver.Settings.Value()),
+					DisableResolveMessage: receiver.DisableResolveMessage.Value(),
+				},
+			})
+		}
+	}
+
+	for _, policy := range cfg.Policies {
+		r.Policies = append(r.Policies, &notificationPolicy{
+			OrgID:  policy.OrgID.Value(),
+			Policy: policy.Policy,
+		})
+	}
+
+	return r, nil
+}
+
+func (group *alertRuleGroupV1) mapToModel() (*alertRuleGroup, error) {
+	g := &alertRuleGroup{
+		OrgID:     group.OrgID.Value(),
+		Name:      group.Name.Value(),
+		FolderUID: group.FolderUID.Value(),
+	}
+
+	if intervalValue := group.Interval.Value(); intervalValue != "" {
+		interval, err := model.ParseDuration(intervalValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval of rule group %q: %w", g.Name, err)
+		}
+		g.Interval = time.Duration(interval)
+	}
+
+	for _, rule := range group.Rules {
+		r, err := rule.mapToModel()
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %q of rule group %q: %w", rule.UID.Value(), g.Name, err)
+		}
+		r.OrgID = g.OrgID
+		r.NamespaceUID = g.FolderUID
+		r.RuleGroup = g.Name
+		r.IntervalSeconds = int64(g.Interval.Seconds())
+		g.Rules = append(g.Rules, r)
+	}
+	return g, nil
+}
+
+func (rule *alertRuleV1) mapToModel() (ngmodels.AlertRule, error) {
+	r := ngmodels.AlertRule{
+		UID:         rule.UID.Value(),
+		Title:       rule.Title.Value(),
+		Condition:   rule.Condition.Value(),
+		Annotations: rule.Annotations.Value(),
+		Labels:      rule.Labels.Value(),
+		IsPaused:    rule.IsPaused.Value(), // LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+	}
+
+	if dashboardUID := rule.DashboardUID.Value(); dashboardUID != "" {
+		panelID := rule.PanelID.Value()
+		r.DashboardUID = &dashboardUID
+		r.PanelID = &panelID
+	}
+
+	noDataState := rule.NoDataState.Value()
+	if noDataState == "" {
+		noDataState = string(ngmodels.NoData)
+	}
+	var err error
+	if r.NoDataState, err = ngmodels.NoDataStateFromString(noDataState); err != nil {
+		return ngmodels.AlertRule{}, err
+	}
+
+	execErrState := rule.ExecErrState.Value()
+	if execErrState == "" {
+		execErrState = string(ngmodels.AlertingErrState)
+	}
+	if r.ExecErrState, err = ngmodels.ErrStateFromString(execErrState); err != nil {
+		return ngmodels.AlertRule{}, err
+	}
+
+	if forValue := rule.For.Value(); forValue != "" {
+		duration, err := model.ParseDuration(forValue)
+		if err != nil {
+			return ngmodels.AlertRule{}, fmt.Errorf("invalid for: %w", err)
+		}
+		r.For = time.Duration(duration)
+	}
+
+	// LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+	if keepFiringFor := rule.KeepFiringFor.Value(); keepFiringFor != "" {
+		duration, err := model.ParseDuration(keepFiringFor)
+		if err != nil {
+			return ngmodels.AlertRule{}, fmt.Errorf("invalid keepFiringFor: %w", err)
+		}
+		r.KeepFiringFor = time.Duration(duration)
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+	if maxInstances := rule.MaxInstances.Value(); maxInstances != "" {
+		limit, err := strconv.ParseInt(maxInstances, 10, 64)
+		if err != nil {
+			return ngmodels.AlertRule{}, fmt.Errorf("invalid maxInstances: %w", err)
+		}
+		r.MaxInstances = &limit
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+	if evictionDelay := rule.EvictionDelay.Value(); evictionDelay != "" {
+		duration, err := model.ParseDuration(evictionDelay)
+		if err != nil {
+			return ngmodels.AlertRule{}, fmt.Errorf("invalid evictionDelay: %w", err)
+		}
+		r.EvictionDelay = time.Duration(duration)
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	for _, query := range rule.Data {
+		queryModel, err := json.Marshal(query.Model.Value())
+		if err != nil {
+			return ngmodels.AlertRule{}, err
+		}
+		r.Data = append(r.Data, ngmodels.AlertQuery{
+			RefID:         query.RefID.Value(),
+			QueryType:     query.QueryType.Value(),
+			DatasourceUID: query.DatasourceUID.Value(),
+			RelativeTimeRange: ngmodels.RelativeTimeRange{
+				From: ngmodels.Duration(time.Duration(query.RelativeTimeRange.From.Value()) * time.Second),
+				To:   ngmodels.Duration(time.Duration(query.RelativeTimeRange.To.Value()) * time.Second),
+			},
+			Model: queryModel,
+		})
+	}
+	return r, nil
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
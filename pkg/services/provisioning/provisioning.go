@@ -12,13 +12,18 @@ import (
 	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards"
 	datasourceservice "github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/encryption"
+	ngprovisioning "github.com/grafana/grafana/pkg/services/ngalert/provisioning" // LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
+	ngstate "github.com/grafana/grafana/pkg/services/ngalert/state"               // LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"               // LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/pluginsettings"
+	provisionerAlerting "github.com/grafana/grafana/pkg/services/provisioning/alerting" // LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
 	"github.com/grafana/grafana/pkg/services/provisioning/notifiers"
 	"github.com/grafana/grafana/pkg/services/provisioning/plugins"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/services/secrets" // LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/errutil"
@@ -29,6 +34,7 @@ func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, pluginStore p
 	dashboardService dashboardservice.DashboardProvisioningService,
 	datasourceService datasourceservice.DataSourceService,
 	alertingService *alerting.AlertNotificationService, pluginSettings pluginsettings.Service,
+	secretsService secrets.Service, // LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
 ) (*ProvisioningServiceImpl, error) {
 	s := &ProvisioningServiceImpl{
 		Cfg:                     cfg,
@@ -45,6 +51,8 @@ func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, pluginStore p
 		datasourceService:       datasourceService,
 		alertingService:         alertingService,
 		pluginsSettings:         pluginSettings,
+		provisionAlerting:       provisionerAlerting.Provision, // LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
+		secretsService:          secretsService,                // LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
 	}
 	return s, nil
 }
@@ -56,6 +64,7 @@ type ProvisioningService interface {
 	ProvisionPlugins(ctx context.Context) error
 	ProvisionNotifications(ctx context.Context) error
 	ProvisionDashboards(ctx context.Context) error
+	ProvisionAlerting(ctx context.Context) error // LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 }
@@ -68,6 +77,7 @@ func NewProvisioningServiceImpl() *ProvisioningServiceImpl {
 		provisionNotifiers:      notifiers.Provision,
 		provisionDatasources:    datasources.Provision,
 		provisionPlugins:        plugins.Provision,
+		provisionAlerting:       provisionerAlerting.Provision, // LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
 	}
 }
 
@@ -105,6 +115,10 @@ type ProvisioningServiceImpl struct {
 	datasourceService       datasourceservice.DataSourceService
 	alertingService         *alerting.AlertNotificationService
 	pluginsSettings         pluginsettings.Service
+	// LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
+	provisionAlerting func(context.Context, string, provisionerAlerting.ProvisionerConfig) error
+	secretsService    secrets.Service
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) error {
@@ -123,6 +137,13 @@ func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) erro
 		return err
 	}
 
+	// LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
+	err = ps.ProvisionAlerting(ctx)
+	if err != nil {
+		return err
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
 	return nil
 }
 
@@ -185,6 +206,34 @@ func (ps *ProvisioningServiceImpl) ProvisionNotifications(ctx context.Context) e
 	return nil
 }
 
+// LOGZ.IO GRAFANA CHANGE :: File-based provisioning of unified alerting
+func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error {
+	if !ps.Cfg.UnifiedAlerting.IsEnabled() {
+		return nil
+	}
+
+	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
+	st := ngstore.NewDBstore(ps.Cfg.UnifiedAlerting, ps.SQLStore, ps.log, ngstate.ValidateRuleTemplates)
+	cfg := provisionerAlerting.ProvisionerConfig{
+		RuleService: ngprovisioning.NewAlertRuleService(st, st, st,
+			int64(ps.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
+			int64(ps.Cfg.UnifiedAlerting.BaseInterval.Seconds()), ps.log),
+		ContactPointService: ngprovisioning.NewContactPointService(st, ps.secretsService, st, st, ps.log),
+		PolicyService:       ngprovisioning.NewNotificationPolicyService(st, st, st, ps.log, ps.Cfg.UnifiedAlerting),
+		ProvenanceStore:     st,
+		RuleOrgStore:        st,
+		OrgStore:            ps.SQLStore,
+	}
+	if err := ps.provisionAlerting(ctx, alertingPath, cfg); err != nil {
+		err = errutil.Wrap("Alerting provisioning error", err)
+		ps.log.Error("Failed to provision alerting", "error", err)
+		return err
+	}
+	return nil
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) error {
 	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
 	dashProvisioner, err := ps.newDashboardProvisioner(ctx, dashboardPath, ps.dashboardService, ps.SQLStore, ps.SQLStore)
@@ -83,7 +83,14 @@ func (s *Service) QueryData(ctx context.Context, user *models.SignedInUser, skip
 
 // handleExpressions handles POST /api/ds/query when there is an expression.
 func (s *Service) handleExpressions(ctx context.Context, user *models.SignedInUser, parsedReq *parsedRequest) (*backend.QueryDataResponse, error) {
-	h := models.LogzIoHeaders{} // LOGZ.IO CHANGE :: DEV-33325 Open expressions for Grafana 8.5.1
+	h := models.LogzIoHeaders{Settings: s.logzioHeadersSettings()} // LOGZ.IO CHANGE :: DEV-33325 Open expressions for Grafana 8.5.1
+	// LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
+	for _, pq := range parsedReq.parsedQueries {
+		if pq.datasource != nil {
+			h.DatasourceWhitelist = append(h.DatasourceWhitelist, models.LogzioHeadersWhitelistFromJsonData(pq.datasource.JsonData)...)
+		}
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
 	exprReq := expr.Request{
 		OrgId:   user.OrgId,
 		Queries: []expr.Query{},
@@ -127,7 +134,7 @@ func (s *Service) handleQueryData(ctx context.Context, user *models.SignedInUser
 		return nil, fmt.Errorf("failed to convert data source to instance settings: %w", err)
 	}
 
-	h := models.LogzIoHeaders{} // LOGZ.IO CHANGE :: DEV-33325 Open expressions for Grafana 8.5.1
+	h := models.LogzIoHeaders{DatasourceWhitelist: models.LogzioHeadersWhitelistFromJsonData(ds.JsonData), Settings: s.logzioHeadersSettings()} // LOGZ.IO CHANGE :: DEV-33325 Open expressions for Grafana 8.5.1
 	req := &backend.QueryDataRequest{
 		PluginContext: backend.PluginContext{
 			OrgID:                      ds.OrgId,
@@ -304,3 +311,13 @@ func (s *Service) decryptSecureJsonDataFn(ctx context.Context) func(map[string][
 		return decryptedJsonData
 	}
 }
+
+// LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
+func (s *Service) logzioHeadersSettings() *setting.LogzioHeadersSettings {
+	if s.cfg == nil {
+		return nil
+	}
+	return &s.cfg.LogzioHeaders
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
@@ -0,0 +1,138 @@
+package ualert
+
+// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+
+import (
+	"fmt"
+)
+
+const (
+	// UnsupportedAlertItem is a dashboard alert that cannot be migrated.
+	UnsupportedAlertItem = "alert"
+	// UnsupportedNotificationChannelItem is a notification channel that cannot be migrated.
+	UnsupportedNotificationChannelItem = "notification_channel"
+	// UnsupportedEmailNotificationItem is an email notification of an alert that has no matching contact point.
+	UnsupportedEmailNotificationItem = "email_notification"
+	// UnsupportedAlertmanagerConfigItem is an Alertmanager configuration that failed to be built or validated.
+	UnsupportedAlertmanagerConfigItem = "alertmanager_config"
+
+	// dryRunFolderUID is used instead of the UID of the folders that a dry-run would create.
+	dryRunFolderUID = "dry-run"
+)
+
+// OrgMigrationReport describes what the migration of the alerts of an organization creates, or would create when run as a dry-run.
+type OrgMigrationReport struct {
+	OrgId              int64                               `json:"orgId"`
+	DryRun             bool                                `json:"dryRun"`
+	Alerts             []OrgMigrationReportAlert           `json:"alerts"`
+	ContactPoints      []OrgMigrationReportContactPoint    `json:"contactPoints"`
+	EmailContactPoints []OrgMigrationReportEmailContact    `json:"emailContactPoints"`
+	Unsupported        []OrgMigrationReportUnsupportedItem `json:"unsupported"`
+}
+
+// OrgMigrationReportAlert is a dashboard alert migrated to an alert rule.
+type OrgMigrationReportAlert struct {
+	AlertId      int64  `json:"alertId"`
+	Name         string `json:"name"`
+	DashboardUID string `json:"dashboardUid"`
+	PanelId      int64  `json:"panelId"`
+	FolderTitle  string `json:"folderTitle"`
+}
+
+// OrgMigrationReportContactPoint is a notification channel migrated to a contact point.
+type OrgMigrationReportContactPoint struct {
+	ChannelUid string `json:"channelUid"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	IsDefault  bool   `json:"isDefault"`
+}
+
+// OrgMigrationReportEmailContact is an email notification of an alert routed to the contact point of the given channel.
+type OrgMigrationReportEmailContact struct {
+	AlertId    int64  `json:"alertId"`
+	Address    string `json:"address"`
+	ChannelUid string `json:"channelUid"`
+}
+
+// OrgMigrationReportUnsupportedItem is an item that is skipped by the migration, or that makes it fail.
+type OrgMigrationReportUnsupportedItem struct {
+	Kind   string `json:"kind"`
+	Id     int64  `json:"id,omitempty"`
+	Uid    string `json:"uid,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Reason string `json:"reason"`
+}
+
+func newOrgMigrationReport(orgId int64, dryRun bool) *OrgMigrationReport {
+	return &OrgMigrationReport{
+		OrgId:              orgId,
+		DryRun:             dryRun,
+		Alerts:             []OrgMigrationReportAlert{},
+		ContactPoints:      []OrgMigrationReportContactPoint{},
+		EmailContactPoints: []OrgMigrationReportEmailContact{},
+		Unsupported:        []OrgMigrationReportUnsupportedItem{},
+	}
+}
+
+func (r *OrgMigrationReport) addAlert(da dashAlert, folderTitle string) {
+	r.Alerts = append(r.Alerts, OrgMigrationReportAlert{
+		AlertId:      da.Id,
+		Name:         da.Name,
+		DashboardUID: da.DashboardUID,
+		PanelId:      da.PanelId,
+		FolderTitle:  folderTitle,
+	})
+}
+
+func (r *OrgMigrationReport) addContactPoint(c *notificationChannel) {
+	r.ContactPoints = append(r.ContactPoints, OrgMigrationReportContactPoint{
+		ChannelUid: c.Uid,
+		Name:       c.Name,
+		Type:       c.Type,
+		IsDefault:  c.IsDefault,
+	})
+}
+
+// addEmailNotifications reports the email notifications of the alert and whether they match a contact point.
+func (r *OrgMigrationReport) addEmailNotifications(da dashAlert, channelUidByEmail map[string]string) {
+	for _, emailNot := range da.ParsedSettings.EmailNotifications {
+		if emailNot.Address == "" {
+			continue
+		}
+		if channelUid, found := channelUidByEmail[emailNot.Address]; found {
+			r.EmailContactPoints = append(r.EmailContactPoints, OrgMigrationReportEmailContact{
+				AlertId:    da.Id,
+				Address:    emailNot.Address,
+				ChannelUid: channelUid,
+			})
+		} else {
+			r.Unsupported = append(r.Unsupported, OrgMigrationReportUnsupportedItem{
+				Kind:   UnsupportedEmailNotificationItem,
+				Id:     da.Id,
+				Name:   da.Name,
+				Reason: fmt.Sprintf("no contact point provided for email address %s", emailNot.Address),
+			})
+		}
+	}
+}
+
+func (r *OrgMigrationReport) addUnsupportedAlert(da dashAlert, err error) {
+	r.Unsupported = append(r.Unsupported, OrgMigrationReportUnsupportedItem{
+		Kind:   UnsupportedAlertItem,
+		Id:     da.Id,
+		Name:   da.Name,
+		Reason: err.Error(),
+	})
+}
+
+func (r *OrgMigrationReport) addUnsupportedChannel(c notificationChannel, reason string) {
+	r.Unsupported = append(r.Unsupported, OrgMigrationReportUnsupportedItem{
+		Kind:   UnsupportedNotificationChannelItem,
+		Id:     c.ID,
+		Uid:    c.Uid,
+		Name:   c.Name,
+		Reason: reason,
+	})
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
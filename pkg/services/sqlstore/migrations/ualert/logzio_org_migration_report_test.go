@@ -0,0 +1,64 @@
+package ualert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations/ualert"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestOrgAlertMigrationDryRun(t *testing.T) {
+	x := setupTestDB(t)
+	defer teardown(t, x)
+
+	setupLegacyAlertsTables(t, x,
+		[]*models.AlertNotification{
+			createAlertNotification(t, int64(1), "notifier1", "email", `{"addresses": "test"}`, false),
+			createAlertNotification(t, int64(1), "notifier2", "hipchat", "", false),
+			createAlertNotification(t, int64(2), "notifier3", "email", `{"addresses": "test"}`, false),
+		},
+		[]*models.Alert{
+			createAlert(t, int64(1), int64(1), int64(1), "alert1", []string{"notifier1"}),
+			createAlert(t, int64(1), int64(2), int64(2), "alert2", []string{"notifier2"}),
+			createAlert(t, int64(2), int64(3), int64(1), "alert3", []string{"notifier3"}),
+		},
+	)
+
+	dryRun := ualert.NewOrgAlertMigrationDryRun(1, map[string]string{})
+	err := migrator.NewMigrator(x, &setting.Cfg{}).RunMigration(dryRun)
+	require.NoError(t, err)
+
+	report := dryRun.Report()
+	require.True(t, report.DryRun)
+
+	alertNames := make([]string, 0, len(report.Alerts))
+	for _, a := range report.Alerts {
+		alertNames = append(alertNames, a.Name)
+	}
+	require.ElementsMatch(t, []string{"alert1", "alert2"}, alertNames)
+
+	require.Len(t, report.ContactPoints, 1)
+	require.Equal(t, "notifier1", report.ContactPoints[0].ChannelUid)
+
+	unsupported := make(map[string]string)
+	for _, item := range report.Unsupported {
+		unsupported[item.Kind] = item.Name
+	}
+	require.Equal(t, "notifier2", unsupported[ualert.UnsupportedNotificationChannelItem])
+	require.Equal(t, "alert2", unsupported[ualert.UnsupportedAlertItem])
+
+	// nothing is written by a dry-run
+	rules, err := x.Table("alert_rule").Count()
+	require.NoError(t, err)
+	require.Zero(t, rules)
+	configs, err := x.Table("alert_configuration").Count()
+	require.NoError(t, err)
+	require.Zero(t, configs)
+	folders, err := x.Table("dashboard").Where("is_folder = ?", true).Count()
+	require.NoError(t, err)
+	require.Zero(t, folders)
+}
@@ -57,6 +57,11 @@ type MigrateOrgAlerts struct {
 	silences          map[int64][]*pb.MeshSilence
 	orgId             int64
 	channelUidByEmail map[string]string // email address -> Notification channel uuid
+	// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+	// dryRun makes the migration report what it would create without writing anything.
+	dryRun bool
+	report *OrgMigrationReport
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 func NewOrgAlertMigration(orgId int64, channelUidByEmail map[string]string) *MigrateOrgAlerts {
@@ -65,9 +70,29 @@ func NewOrgAlertMigration(orgId int64, channelUidByEmail map[string]string) *Mig
 		silences:          make(map[int64][]*pb.MeshSilence),
 		orgId:             orgId,
 		channelUidByEmail: channelUidByEmail,
+		report:            newOrgMigrationReport(orgId, false), // LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
 	}
 }
 
+// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+
+// NewOrgAlertMigrationDryRun creates a migration that only reports what NewOrgAlertMigration would create.
+// It does not create folders, rules, silences nor Alertmanager configurations, and it does not stop at the
+// first alert that cannot be migrated.
+func NewOrgAlertMigrationDryRun(orgId int64, channelUidByEmail map[string]string) *MigrateOrgAlerts {
+	m := NewOrgAlertMigration(orgId, channelUidByEmail)
+	m.dryRun = true
+	m.report = newOrgMigrationReport(orgId, true)
+	return m
+}
+
+// Report returns what the migration created, or would create for a dry-run. It is complete once the migration ran.
+func (m *MigrateOrgAlerts) Report() *OrgMigrationReport {
+	return m.report
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
 func (m *MigrateOrgAlerts) SQL(dialect migrator.Dialect) string {
 	return "code migration"
 }
@@ -104,6 +129,12 @@ func (m *MigrateOrgAlerts) Exec(sess *xorm.Session, mg *migrator.Migrator) error
 	for _, da := range dashAlerts {
 		newCond, err := transConditions(*da.ParsedSettings, da.OrgId, dsIDMap)
 		if err != nil {
+			// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+			m.report.addUnsupportedAlert(da, err)
+			if m.dryRun {
+				continue
+			}
+			// LOGZ.IO GRAFANA CHANGE :: end
 			return err
 		}
 
@@ -119,8 +150,15 @@ func (m *MigrateOrgAlerts) Exec(sess *xorm.Session, mg *migrator.Migrator) error
 			}
 		}
 		if !exists {
+			err := fmt.Errorf("dashboard with UID %v under organisation %d not found: %w", da.DashboardUID, da.OrgId, err)
+			// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+			m.report.addUnsupportedAlert(da, err)
+			if m.dryRun {
+				continue
+			}
+			// LOGZ.IO GRAFANA CHANGE :: end
 			return MigrationError{
-				Err:     fmt.Errorf("dashboard with UID %v under organisation %d not found: %w", da.DashboardUID, da.OrgId, err),
+				Err:     err,
 				AlertId: da.Id,
 			}
 		}
@@ -130,6 +168,13 @@ func (m *MigrateOrgAlerts) Exec(sess *xorm.Session, mg *migrator.Migrator) error
 		case dash.HasAcl:
 			folderName := getAlertFolderNameFromDashboard(&dash)
 			f, ok := folderCache[folderName]
+			// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+			if !ok && m.dryRun {
+				f = &dashboard{OrgId: dash.OrgId, Uid: dryRunFolderUID, Title: folderName}
+				folderCache[folderName] = f
+				ok = true
+			}
+			// LOGZ.IO GRAFANA CHANGE :: end
 			if !ok {
 				mg.Logger.Info("create a new folder for alerts that belongs to dashboard because it has custom permissions", "org", dash.OrgId, "dashboard_uid", dash.Uid, "folder", folderName)
 				// create folder and assign the permissions of the dashboard (included default and inherited)
@@ -194,6 +239,12 @@ func (m *MigrateOrgAlerts) Exec(sess *xorm.Session, mg *migrator.Migrator) error
 		}
 		rule, err := m.makeAlertRule(*newCond, da, folder.Uid)
 		if err != nil {
+			// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+			m.report.addUnsupportedAlert(da, err)
+			if m.dryRun {
+				continue
+			}
+			// LOGZ.IO GRAFANA CHANGE :: end
 			return err
 		}
 
@@ -209,6 +260,14 @@ func (m *MigrateOrgAlerts) Exec(sess *xorm.Session, mg *migrator.Migrator) error
 			}
 		}
 
+		// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+		m.report.addAlert(da, folder.Title)
+		m.report.addEmailNotifications(da, m.channelUidByEmail)
+		if m.dryRun {
+			continue
+		}
+		// LOGZ.IO GRAFANA CHANGE :: end
+
 		if strings.HasPrefix(mg.Dialect.DriverName(), migrator.Postgres) {
 			err = mg.InTransaction(func(sess *xorm.Session) error {
 				_, err = sess.Insert(rule)
@@ -234,6 +293,18 @@ func (m *MigrateOrgAlerts) Exec(sess *xorm.Session, mg *migrator.Migrator) error
 		}
 	}
 
+	// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+	if m.dryRun {
+		if _, err := m.setupAlertmanagerConfigs(rulesPerOrg); err != nil {
+			m.report.Unsupported = append(m.report.Unsupported, OrgMigrationReportUnsupportedItem{
+				Kind:   UnsupportedAlertmanagerConfigItem,
+				Reason: err.Error(),
+			})
+		}
+		return nil
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
 	for orgID := range rulesPerOrg {
 		if err := m.writeSilencesFile(orgID); err != nil {
 			m.mg.Logger.Error("alert migration error: failed to write silence file", "err", err)
@@ -425,6 +496,7 @@ func (m *MigrateOrgAlerts) getNotificationChannelMap() (channelsPerOrg, defaultC
 	for i, c := range allChannels {
 		if c.Type == "hipchat" || c.Type == "sensu" {
 			m.mg.Logger.Error("alert migration error: discontinued notification channel found", "type", c.Type, "name", c.Name, "uid", c.Uid)
+			m.report.addUnsupportedChannel(c, fmt.Sprintf("discontinued notification channel type %s", c.Type)) // LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
 			continue
 		}
 
@@ -455,6 +527,8 @@ func (m *MigrateOrgAlerts) createReceivers(allChannels []*notificationChannel) (
 
 		receivers = append(receivers, recv)
 
+		m.report.addContactPoint(c) // LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+
 		// Store receivers for creating routes from alert rules later.
 		if c.Uid != "" {
 			receiversMap[c.Uid] = recv
@@ -562,6 +636,14 @@ func (m *MigrateOrgAlerts) filterReceiversForAlert(da dashAlert, receivers map[u
 			filteredReceiverNames[recv.Name] = struct{}{} // Deduplicate on contact point name.
 		} else {
 			m.mg.Logger.Warn("alert linked to obsolete notification channel, ignoring", "alert", da.Name, "uid", uidOrId)
+			// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+			m.report.Unsupported = append(m.report.Unsupported, OrgMigrationReportUnsupportedItem{
+				Kind:   UnsupportedAlertItem,
+				Id:     da.Id,
+				Name:   da.Name,
+				Reason: fmt.Sprintf("alert linked to obsolete notification channel %v", uidOrId),
+			})
+			// LOGZ.IO GRAFANA CHANGE :: end
 		}
 	}
 
@@ -816,7 +898,7 @@ func (m *MigrateOrgAlerts) setACL(orgID int64, dashboardID int64, items []*dashb
 }
 
 // getOrCreateGeneralFolder returns the general folder under the specific organisation
-// If the general folder does not exist it creates it.
+// If the general folder does not exist it creates it, unless the migration is a dry-run.
 func (m *MigrateOrgAlerts) getOrCreateGeneralFolder(orgID int64) (*dashboard, error) {
 	// there is a unique constraint on org_id, folder_id, title
 	// there are no nested folders so the parent folder id is always 0
@@ -824,6 +906,11 @@ func (m *MigrateOrgAlerts) getOrCreateGeneralFolder(orgID int64) (*dashboard, er
 	has, err := m.sess.Get(&dashboard)
 	if err != nil {
 		return nil, err
+	} else if !has && m.dryRun {
+		// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+		dashboard.Uid = dryRunFolderUID
+		return &dashboard, nil
+		// LOGZ.IO GRAFANA CHANGE :: end
 	} else if !has {
 		// create folder
 		result, err := m.createFolder(orgID, GENERAL_FOLDER)
@@ -25,6 +25,16 @@ func AddTablesMigrations(mg *migrator.Migrator) {
 
 	// Create provisioning data table
 	AddProvisioningMigrations(mg)
+
+	// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+	// Create per-org alert migration status table
+	AddOrgMigrationStatusMigrations(mg)
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+	// Create alert state history table
+	AddStateHistoryMigrations(mg)
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 // AddAlertDefinitionMigrations should not be modified.
@@ -234,6 +244,36 @@ func AddAlertRuleMigrations(mg *migrator.Migrator, defaultIntervalSeconds int64)
 			Cols: []string{"org_id", "dashboard_uid", "panel_id"},
 		},
 	))
+
+	// LOGZ.IO GRAFANA CHANGE :: Recording rules
+	// add record column
+	mg.AddMigration("add column record to alert_rule", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{Name: "record", Type: migrator.DB_Text, Nullable: true}))
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+	// add query_offset column
+	mg.AddMigration("add column query_offset to alert_rule", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{Name: "query_offset", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+	// add is_paused column
+	mg.AddMigration("add column is_paused to alert_rule", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{Name: "is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+	// add keep_firing_for column
+	mg.AddMigration("add column keep_firing_for to alert_rule", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{Name: "keep_firing_for", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+	// add max_instances column
+	mg.AddMigration("add column max_instances to alert_rule", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{Name: "max_instances", Type: migrator.DB_BigInt, Nullable: true}))
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+	// add eviction_delay column
+	mg.AddMigration("add column eviction_delay to alert_rule", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{Name: "eviction_delay", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
@@ -277,6 +317,36 @@ func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
 
 	// add labels column
 	mg.AddMigration("add column labels to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "labels", Type: migrator.DB_Text, Nullable: true}))
+
+	// LOGZ.IO GRAFANA CHANGE :: Recording rules
+	// add record column
+	mg.AddMigration("add column record to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "record", Type: migrator.DB_Text, Nullable: true}))
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Evaluation query offset
+	// add query_offset column
+	mg.AddMigration("add column query_offset to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "query_offset", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+	// add is_paused column
+	mg.AddMigration("add column is_paused to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Keep-firing-for hysteresis
+	// add keep_firing_for column
+	mg.AddMigration("add column keep_firing_for to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "keep_firing_for", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+	// add max_instances column
+	mg.AddMigration("add column max_instances to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "max_instances", Type: migrator.DB_BigInt, Nullable: true}))
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+	// add eviction_delay column
+	mg.AddMigration("add column eviction_delay to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "eviction_delay", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+	// LOGZ.IO GRAFANA CHANGE :: end
 }
 
 func AddAlertmanagerConfigMigrations(mg *migrator.Migrator) {
@@ -353,3 +423,60 @@ func AddProvisioningMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("create provenance_type table", migrator.NewAddTableMigration(provisioningTable))
 	mg.AddMigration("add index to uniquify (record_key, record_type, org_id) columns", migrator.NewAddIndexMigration(provisioningTable, provisioningTable.Indices[0]))
 }
+
+// LOGZ.IO GRAFANA CHANGE :: Dry-run and status reporting for the per-org alert migration
+func AddOrgMigrationStatusMigrations(mg *migrator.Migrator) {
+	orgMigrationStatus := migrator.Table{
+		Name: "alert_org_migration_status",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "status", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "started_at", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "finished_at", Type: migrator.DB_DateTime, Nullable: true},
+			{Name: "alerts", Type: migrator.DB_Int, Nullable: false, Default: "0"},
+			{Name: "contact_points", Type: migrator.DB_Int, Nullable: false, Default: "0"},
+			{Name: "email_contact_points", Type: migrator.DB_Int, Nullable: false, Default: "0"},
+			{Name: "unsupported", Type: migrator.DB_Int, Nullable: false, Default: "0"},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_org_migration_status table", migrator.NewAddTableMigration(orgMigrationStatus))
+	mg.AddMigration("add unique index in alert_org_migration_status on org_id column", migrator.NewAddIndexMigration(orgMigrationStatus, orgMigrationStatus.Indices[0]))
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
+
+// LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+func AddStateHistoryMigrations(mg *migrator.Migrator) {
+	stateHistory := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "state", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "eval_values", Type: migrator.DB_Text, Nullable: true},
+			{Name: "evaluated_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "rule_uid", "evaluated_at"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "evaluated_at"}, Type: migrator.IndexType},
+			{Cols: []string{"evaluated_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history table", migrator.NewAddTableMigration(stateHistory))
+	mg.AddMigration("add index in alert_state_history on org_id, rule_uid and evaluated_at columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[0]))
+	mg.AddMigration("add index in alert_state_history on org_id and evaluated_at columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[1]))
+	mg.AddMigration("add index in alert_state_history on evaluated_at column", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[2]))
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
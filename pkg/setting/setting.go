@@ -438,6 +438,9 @@ type Cfg struct {
 	// Unified Alerting
 	UnifiedAlerting UnifiedAlertingSettings
 
+	// LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
+	LogzioHeaders LogzioHeadersSettings
+
 	// Query history
 	QueryHistoryEnabled bool
 }
@@ -900,6 +903,12 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 		return err
 	}
 
+	// LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
+	if err := cfg.readLogzioHeadersSettings(iniFile); err != nil {
+		return err
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
 	if err := readSecuritySettings(iniFile, cfg); err != nil {
 		return err
 	}
@@ -0,0 +1,62 @@
+package setting
+
+// LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	logzioHeadersSection          = "logzio_headers"
+	logzioHeadersRenameSection    = "logzio_headers.rename"
+	logzioHeadersRedactSection    = "logzio_headers.redact"
+	logzioHeadersDefaultWhitelist = "x-auth-token,x-api-token,user-context,x-request-id,cookie,x-logz-csrf-token,x-logz-csrf-token-v2"
+)
+
+// LogzioHeadersSettings configures which headers of the incoming request are passed on to datasource queries.
+type LogzioHeadersSettings struct {
+	// Whitelist holds the lower-cased names of the headers that are passed on.
+	Whitelist []string
+	// Rename maps the lower-cased name of a whitelisted header to the name it is passed on with.
+	Rename map[string]string
+	// Redact maps the lower-cased name of a whitelisted header to the pattern of the parts of its value that are
+	// replaced before it is passed on.
+	Redact map[string]*regexp.Regexp
+}
+
+func (cfg *Cfg) readLogzioHeadersSettings(iniFile *ini.File) error {
+	headers := LogzioHeadersSettings{
+		Rename: map[string]string{},
+		Redact: map[string]*regexp.Regexp{},
+	}
+
+	for _, name := range util.SplitString(valueAsString(iniFile.Section(logzioHeadersSection), "whitelist", logzioHeadersDefaultWhitelist)) {
+		headers.Whitelist = append(headers.Whitelist, strings.ToLower(name))
+	}
+
+	for _, key := range iniFile.Section(logzioHeadersRenameSection).Keys() {
+		if key.Value() == "" {
+			return fmt.Errorf("header %s in section %s must be renamed to a non-empty name", key.Name(), logzioHeadersRenameSection)
+		}
+		headers.Rename[strings.ToLower(key.Name())] = key.Value()
+	}
+
+	for _, key := range iniFile.Section(logzioHeadersRedactSection).Keys() {
+		pattern, err := regexp.Compile(key.Value())
+		if err != nil {
+			return fmt.Errorf("invalid redaction pattern of header %s in section %s: %w", key.Name(), logzioHeadersRedactSection, err)
+		}
+		headers.Redact[strings.ToLower(key.Name())] = pattern
+	}
+
+	cfg.LogzioHeaders = headers
+	return nil
+}
+
+// LOGZ.IO GRAFANA CHANGE :: end
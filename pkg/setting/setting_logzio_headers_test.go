@@ -0,0 +1,45 @@
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestCfg_ReadLogzioHeadersSettings(t *testing.T) {
+	t.Run("uses the default whitelist", func(t *testing.T) {
+		cfg := NewCfg()
+		require.NoError(t, cfg.readLogzioHeadersSettings(ini.Empty()))
+
+		require.Contains(t, cfg.LogzioHeaders.Whitelist, "x-auth-token")
+		require.Contains(t, cfg.LogzioHeaders.Whitelist, "x-request-id")
+		require.Empty(t, cfg.LogzioHeaders.Rename)
+		require.Empty(t, cfg.LogzioHeaders.Redact)
+	})
+
+	t.Run("reads the whitelist, rename and redaction rules", func(t *testing.T) {
+		iniFile := ini.Empty()
+		_, err := iniFile.Section("logzio_headers").NewKey("whitelist", "X-Auth-Token, x-account-id")
+		require.NoError(t, err)
+		_, err = iniFile.Section("logzio_headers.rename").NewKey("X-Account-Id", "x-logzio-account")
+		require.NoError(t, err)
+		_, err = iniFile.Section("logzio_headers.redact").NewKey("Cookie", "session=[^;]*")
+		require.NoError(t, err)
+
+		cfg := NewCfg()
+		require.NoError(t, cfg.readLogzioHeadersSettings(iniFile))
+
+		require.Equal(t, []string{"x-auth-token", "x-account-id"}, cfg.LogzioHeaders.Whitelist)
+		require.Equal(t, map[string]string{"x-account-id": "x-logzio-account"}, cfg.LogzioHeaders.Rename)
+		require.Equal(t, "a=1; [REDACTED]", cfg.LogzioHeaders.Redact["cookie"].ReplaceAllString("a=1; session=abc", "[REDACTED]"))
+	})
+
+	t.Run("fails on an invalid redaction pattern", func(t *testing.T) {
+		iniFile := ini.Empty()
+		_, err := iniFile.Section("logzio_headers.redact").NewKey("cookie", "(")
+		require.NoError(t, err)
+
+		require.Error(t, NewCfg().readLogzioHeadersSettings(iniFile))
+	})
+}
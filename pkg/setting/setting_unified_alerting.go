@@ -48,6 +48,35 @@ const (
 	schedulereDefaultExecuteAlerts          = true
 	schedulerDefaultMaxAttempts             = 3
 	schedulerDefaultLegacyMinInterval       = 1
+	// LOGZ.IO GRAFANA CHANGE :: Batch evaluation endpoint
+	logzioBatchEvaluationDefaultMaxWorkers = 10
+	// LOGZ.IO GRAFANA CHANGE :: end
+	logzioProcessIdempotencyDefaultTTL = 10 * time.Minute // LOGZ.IO GRAFANA CHANGE :: Idempotent alert processing
+	// LOGZ.IO GRAFANA CHANGE :: Shared alert state cache across HA peers
+	// StateCacheBackendMemory keeps the alert states only in the memory of each peer.
+	StateCacheBackendMemory = "memory"
+	// StateCacheBackendRemoteCache keeps the alert states in the configured remote cache, shared by all peers.
+	StateCacheBackendRemoteCache = "remote_cache"
+	logzioStateCacheDefaultTTL   = 24 * time.Hour
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+	logzioStateEvictionDefaultDelay     = time.Hour
+	logzioStateEvictionDefaultFrequency = 40 * time.Minute
+	// LOGZ.IO GRAFANA CHANGE :: end
+	logzioNotificationDeliveryLogDefaultSize = 1000 // LOGZ.IO GRAFANA CHANGE :: Notification delivery log
+	// LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+	logzioScreenshotsDefaultTimeout = 10 * time.Second
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+	// PausedRuleStatePolicyResolve resolves the alerts of a rule when it is paused.
+	PausedRuleStatePolicyResolve = "resolve"
+	// PausedRuleStatePolicyKeep keeps the alerts of a rule as they are when it is paused, until it is resumed.
+	PausedRuleStatePolicyKeep = "keep"
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+	logzioStateHistoryDefaultRetention = 30 * 24 * time.Hour
+	// LOGZ.IO GRAFANA CHANGE :: end
+	logzioRecordingRulesDefaultRemoteWriteTimeout = 30 * time.Second // LOGZ.IO GRAFANA CHANGE :: Recording rules
 	// SchedulerBaseInterval base interval of the scheduler. Controls how often the scheduler fetches database for new changes as well as schedules evaluation of a rule
 	// changing this value is discouraged because this could cause existing alert definition
 	// with intervals that are not exactly divided by this number not to be evaluated
@@ -73,6 +102,68 @@ type UnifiedAlertingSettings struct {
 	Enabled                        *bool // determines whether unified alerting is enabled. If it is nil then user did not define it and therefore its value will be determined during migration. Services should not use it directly.
 	DisabledOrgs                   map[int64]struct{}
 	AlertManagerEnabled            bool // LOGZ.IO GRAFANA CHANGE :: DEV-30762 - disable creation of alert managers by config
+	// LOGZ.IO GRAFANA CHANGE :: Batch evaluation endpoint
+	// BatchEvaluationMaxWorkers is the maximum number of rules of a single batch evaluation request evaluated concurrently.
+	BatchEvaluationMaxWorkers int
+	// BatchEvaluationRuleTimeout is the maximum duration of the evaluation of a single rule of a batch evaluation request.
+	BatchEvaluationRuleTimeout time.Duration
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Idempotent alert processing
+	// ProcessIdempotencyTTL is how long the responses of processed alerts are kept to answer replays of the same request. Zero disables deduplication.
+	ProcessIdempotencyTTL time.Duration
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Shared alert state cache across HA peers
+	// StateCacheBackend is where the state manager keeps the current alert states, see StateCacheBackendMemory and StateCacheBackendRemoteCache.
+	StateCacheBackend string
+	// StateCacheTTL is how long the alert states of a rule are kept in a shared state cache backend after its last evaluation.
+	StateCacheTTL time.Duration
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+	// StateEvictionDelay is how long after the last evaluation of a rule its states are evicted from the state cache.
+	StateEvictionDelay time.Duration
+	// StateEvictionFrequency is how often the states to evict are looked for.
+	StateEvictionFrequency time.Duration
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+	// StateHistoryEnabled records the state transitions of the alerts in the state history table.
+	StateHistoryEnabled bool
+	// StateHistoryRetention is how long the state transitions are kept in the state history table. Zero keeps them forever.
+	StateHistoryRetention time.Duration
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Recording rules
+	// RecordingRulesRemoteWriteURL is the Prometheus remote-write endpoint the series of the recording rules are written to. Recording rules fail to evaluate when it is empty.
+	RecordingRulesRemoteWriteURL string
+	// RecordingRulesRemoteWriteUser and RecordingRulesRemoteWritePassword authenticate the remote-write requests with basic authentication when the user is set.
+	RecordingRulesRemoteWriteUser     string
+	RecordingRulesRemoteWritePassword string
+	// RecordingRulesRemoteWriteTimeout is the maximum duration of a remote-write request.
+	RecordingRulesRemoteWriteTimeout time.Duration
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Rule evaluation sharding across HA scheduler instances
+	// SchedulerShardingEnabled splits the evaluation of the rules between the live peers of the high availability cluster.
+	// It requires the StateCacheBackendRemoteCache state cache backend.
+	SchedulerShardingEnabled bool
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+	// PausedRuleStatePolicy is what happens to the alerts of a rule when it is paused, see PausedRuleStatePolicyResolve and PausedRuleStatePolicyKeep.
+	PausedRuleStatePolicy string
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+	// MaxInstancesPerRule is the maximum number of alert instances of a rule. Zero is unlimited.
+	MaxInstancesPerRule int
+	// MaxInstancesPerRuleByOrg overrides MaxInstancesPerRule for the rules of an org.
+	MaxInstancesPerRuleByOrg map[int64]int
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Notification delivery log
+	// NotificationDeliveryLogSize is the number of notification attempts kept in the delivery log of each org. Zero disables the log.
+	NotificationDeliveryLogSize int
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+	// ScreenshotsEnabled attaches the screenshot of the panel of a rule to the notifications of its alerts.
+	ScreenshotsEnabled bool
+	// ScreenshotsTimeout is the maximum time to render the screenshot of a panel.
+	ScreenshotsTimeout time.Duration
+	// LOGZ.IO GRAFANA CHANGE :: end
 	// BaseInterval interval of time the scheduler updates the rules and evaluates rules.
 	// Only for internal use and not user configuration.
 	BaseInterval time.Duration
@@ -241,6 +332,132 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	uaCfg.MinInterval = uaMinInterval
 
 	uaCfg.AlertManagerEnabled = ua.Key("alert_manager_enabled").MustBool(true) // LOGZ.IO GRAFANA CHANGE
+
+	// LOGZ.IO GRAFANA CHANGE :: Batch evaluation endpoint
+	uaCfg.BatchEvaluationMaxWorkers = ua.Key("batch_evaluation_max_workers").MustInt(logzioBatchEvaluationDefaultMaxWorkers)
+	if uaCfg.BatchEvaluationMaxWorkers <= 0 {
+		return fmt.Errorf("value of setting 'batch_evaluation_max_workers' should be greater than 0")
+	}
+	uaCfg.BatchEvaluationRuleTimeout, err = gtime.ParseDuration(valueAsString(ua, "batch_evaluation_rule_timeout", uaCfg.EvaluationTimeout.String()))
+	if err != nil {
+		return err
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Idempotent alert processing
+	uaCfg.ProcessIdempotencyTTL, err = gtime.ParseDuration(valueAsString(ua, "process_idempotency_ttl", logzioProcessIdempotencyDefaultTTL.String()))
+	if err != nil {
+		return err
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Shared alert state cache across HA peers
+	uaCfg.StateCacheBackend = valueAsString(ua, "state_cache_backend", StateCacheBackendMemory)
+	if uaCfg.StateCacheBackend != StateCacheBackendMemory && uaCfg.StateCacheBackend != StateCacheBackendRemoteCache {
+		return fmt.Errorf("value of setting 'state_cache_backend' should be one of %q or %q", StateCacheBackendMemory, StateCacheBackendRemoteCache)
+	}
+	uaCfg.StateCacheTTL, err = gtime.ParseDuration(valueAsString(ua, "state_cache_ttl", logzioStateCacheDefaultTTL.String()))
+	if err != nil {
+		return err
+	}
+	if uaCfg.StateCacheTTL <= 0 {
+		return fmt.Errorf("value of setting 'state_cache_ttl' should be greater than 0")
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+
+	// LOGZ.IO GRAFANA CHANGE :: Configurable state eviction
+	uaCfg.StateEvictionDelay, err = gtime.ParseDuration(valueAsString(ua, "state_eviction_delay", logzioStateEvictionDefaultDelay.String()))
+	if err != nil {
+		return err
+	}
+	if uaCfg.StateEvictionDelay <= 0 {
+		return fmt.Errorf("value of setting 'state_eviction_delay' should be greater than 0")
+	}
+	uaCfg.StateEvictionFrequency, err = gtime.ParseDuration(valueAsString(ua, "state_eviction_frequency", logzioStateEvictionDefaultFrequency.String()))
+	if err != nil {
+		return err
+	}
+	if uaCfg.StateEvictionFrequency <= 0 {
+		return fmt.Errorf("value of setting 'state_eviction_frequency' should be greater than 0")
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Dedicated alert state history store
+	uaCfg.StateHistoryEnabled = ua.Key("state_history_enabled").MustBool(true)
+	uaCfg.StateHistoryRetention, err = gtime.ParseDuration(valueAsString(ua, "state_history_retention", logzioStateHistoryDefaultRetention.String()))
+	if err != nil {
+		return err
+	}
+	if uaCfg.StateHistoryRetention < 0 {
+		return fmt.Errorf("value of setting 'state_history_retention' should not be negative")
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Recording rules
+	uaCfg.RecordingRulesRemoteWriteURL = valueAsString(ua, "recording_rules_remote_write_url", "")
+	uaCfg.RecordingRulesRemoteWriteUser = valueAsString(ua, "recording_rules_remote_write_user", "")
+	uaCfg.RecordingRulesRemoteWritePassword = valueAsString(ua, "recording_rules_remote_write_password", "")
+	uaCfg.RecordingRulesRemoteWriteTimeout, err = gtime.ParseDuration(valueAsString(ua, "recording_rules_remote_write_timeout", logzioRecordingRulesDefaultRemoteWriteTimeout.String()))
+	if err != nil {
+		return err
+	}
+	if uaCfg.RecordingRulesRemoteWriteTimeout <= 0 {
+		return fmt.Errorf("value of setting 'recording_rules_remote_write_timeout' should be greater than 0")
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Rule evaluation sharding across HA scheduler instances
+	uaCfg.SchedulerShardingEnabled = ua.Key("scheduler_sharding_enabled").MustBool(false)
+	// the instance that takes over a rule continues from the states of the previous owner, which only the shared state
+	// cache holds
+	if uaCfg.SchedulerShardingEnabled && uaCfg.StateCacheBackend != StateCacheBackendRemoteCache {
+		return fmt.Errorf("setting 'scheduler_sharding_enabled' requires 'state_cache_backend' to be %q", StateCacheBackendRemoteCache)
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Pause and resume individual alert rules
+	uaCfg.PausedRuleStatePolicy = valueAsString(ua, "paused_rule_state_policy", PausedRuleStatePolicyResolve)
+	if uaCfg.PausedRuleStatePolicy != PausedRuleStatePolicyResolve && uaCfg.PausedRuleStatePolicy != PausedRuleStatePolicyKeep {
+		return fmt.Errorf("value of setting 'paused_rule_state_policy' should be one of %q or %q", PausedRuleStatePolicyResolve, PausedRuleStatePolicyKeep)
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Per-rule alert instance limit
+	uaCfg.MaxInstancesPerRule = ua.Key("max_instances_per_rule").MustInt(0)
+	if uaCfg.MaxInstancesPerRule < 0 {
+		return fmt.Errorf("value of setting 'max_instances_per_rule' should not be negative")
+	}
+	uaCfg.MaxInstancesPerRuleByOrg = make(map[int64]int)
+	for _, orgLimit := range util.SplitString(valueAsString(ua, "max_instances_per_rule_by_org", "")) {
+		parts := strings.SplitN(orgLimit, ":", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("value of setting 'max_instances_per_rule_by_org' should be a list of orgID:limit pairs, got %q", orgLimit)
+		}
+		orgID, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return err
+		}
+		limit, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return err
+		}
+		if limit < 0 {
+			return fmt.Errorf("value of setting 'max_instances_per_rule_by_org' should not be negative for org %d", orgID)
+		}
+		uaCfg.MaxInstancesPerRuleByOrg[orgID] = limit
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Notification delivery log
+	uaCfg.NotificationDeliveryLogSize = ua.Key("notification_delivery_log_size").MustInt(logzioNotificationDeliveryLogDefaultSize)
+	if uaCfg.NotificationDeliveryLogSize < 0 {
+		return fmt.Errorf("value of setting 'notification_delivery_log_size' should not be negative")
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
+	// LOGZ.IO GRAFANA CHANGE :: Panel screenshots in unified alert notifications
+	uaCfg.ScreenshotsEnabled = ua.Key("screenshots_enabled").MustBool(false)
+	uaCfg.ScreenshotsTimeout, err = gtime.ParseDuration(valueAsString(ua, "screenshots_timeout", logzioScreenshotsDefaultTimeout.String()))
+	if err != nil {
+		return err
+	}
+	if uaCfg.ScreenshotsTimeout <= 0 {
+		return fmt.Errorf("value of setting 'screenshots_timeout' should be greater than 0")
+	}
+	// LOGZ.IO GRAFANA CHANGE :: end
 	uaCfg.DefaultRuleEvaluationInterval = DefaultRuleEvaluationInterval
 	if uaMinInterval > uaCfg.DefaultRuleEvaluationInterval {
 		uaCfg.DefaultRuleEvaluationInterval = uaMinInterval
@@ -25,6 +25,24 @@ func TestCfg_ReadUnifiedAlertingSettings(t *testing.T) {
 		require.Len(t, cfg.UnifiedAlerting.HAPeers, 0)
 		require.Equal(t, 200*time.Millisecond, cfg.UnifiedAlerting.HAGossipInterval)
 		require.Equal(t, 60*time.Second, cfg.UnifiedAlerting.HAPushPullInterval)
+		require.Equal(t, 10, cfg.UnifiedAlerting.BatchEvaluationMaxWorkers)
+		require.Equal(t, cfg.UnifiedAlerting.EvaluationTimeout, cfg.UnifiedAlerting.BatchEvaluationRuleTimeout)
+		require.Equal(t, 10*time.Minute, cfg.UnifiedAlerting.ProcessIdempotencyTTL)
+		require.Equal(t, StateCacheBackendMemory, cfg.UnifiedAlerting.StateCacheBackend)
+		require.Equal(t, 24*time.Hour, cfg.UnifiedAlerting.StateCacheTTL)
+		require.Equal(t, time.Hour, cfg.UnifiedAlerting.StateEvictionDelay)
+		require.Equal(t, 40*time.Minute, cfg.UnifiedAlerting.StateEvictionFrequency)
+		require.True(t, cfg.UnifiedAlerting.StateHistoryEnabled)
+		require.Equal(t, 30*24*time.Hour, cfg.UnifiedAlerting.StateHistoryRetention)
+		require.Empty(t, cfg.UnifiedAlerting.RecordingRulesRemoteWriteURL)
+		require.Equal(t, 30*time.Second, cfg.UnifiedAlerting.RecordingRulesRemoteWriteTimeout)
+		require.False(t, cfg.UnifiedAlerting.SchedulerShardingEnabled)
+		require.Equal(t, PausedRuleStatePolicyResolve, cfg.UnifiedAlerting.PausedRuleStatePolicy)
+		require.Equal(t, 0, cfg.UnifiedAlerting.MaxInstancesPerRule)
+		require.Empty(t, cfg.UnifiedAlerting.MaxInstancesPerRuleByOrg)
+		require.Equal(t, 1000, cfg.UnifiedAlerting.NotificationDeliveryLogSize)
+		require.False(t, cfg.UnifiedAlerting.ScreenshotsEnabled)
+		require.Equal(t, 10*time.Second, cfg.UnifiedAlerting.ScreenshotsTimeout)
 	}
 
 	// With peers set, it correctly parses them.
@@ -172,6 +190,28 @@ func TestUnifiedAlertingSettings(t *testing.T) {
 	}
 }
 
+func TestUnifiedAlertingSettings_SchedulerSharding(t *testing.T) {
+	readSettings := func(options map[string]string) (*Cfg, error) {
+		f := ini.Empty()
+		cfg := NewCfg()
+		cfg.IsFeatureToggleEnabled = func(key string) bool { return false }
+		sec, err := f.NewSection("unified_alerting")
+		require.NoError(t, err)
+		for k, v := range options {
+			_, err = sec.NewKey(k, v)
+			require.NoError(t, err)
+		}
+		return cfg, cfg.ReadUnifiedAlertingSettings(f)
+	}
+
+	_, err := readSettings(map[string]string{"scheduler_sharding_enabled": "true"})
+	require.EqualError(t, err, `setting 'scheduler_sharding_enabled' requires 'state_cache_backend' to be "remote_cache"`)
+
+	cfg, err := readSettings(map[string]string{"scheduler_sharding_enabled": "true", "state_cache_backend": StateCacheBackendRemoteCache})
+	require.NoError(t, err)
+	require.True(t, cfg.UnifiedAlerting.SchedulerShardingEnabled)
+}
+
 func TestMinInterval(t *testing.T) {
 	randPredicate := func(predicate func(dur time.Duration) bool) *time.Duration {
 		for {
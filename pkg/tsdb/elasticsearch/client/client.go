@@ -21,6 +21,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 )
 
@@ -36,6 +37,8 @@ type DatasourceInfo struct {
 	MaxConcurrentShardRequests int64
 	IncludeFrozen              bool
 	XPack                      bool
+	LogzioHeadersWhitelist     []string                       // LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
+	LogzioHeaders              *setting.LogzioHeadersSettings // LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
 }
 
 const loggerName = "tsdb.elasticsearch.client"
@@ -73,7 +76,8 @@ var NewClient = func(ctx context.Context, httpClientProvider httpclient.Provider
 	clientLog.Debug("Creating new client", "version", ds.ESVersion, "timeField", ds.TimeField, "indices", strings.Join(indices, ", "))
 
 	// LOGZ.IO GRAFANA CHANGE :: Upgrade to 8.4.0 start
-	logzIoHeaders := &models.LogzIoHeaders{}
+	// the headers of the datasource request were already passed on by Grafana, renamed headers included
+	logzIoHeaders := &models.LogzIoHeaders{DatasourceWhitelist: ds.LogzioHeadersWhitelist, Settings: ds.LogzioHeaders, Forwarded: true} // LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
 	headers := ctx.Value("logzioHeaders")
 	if headers != nil {
 		logzIoHeaders.RequestHeaders = http.Header{}
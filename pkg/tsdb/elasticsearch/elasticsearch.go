@@ -13,8 +13,11 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 )
@@ -25,9 +28,10 @@ type Service struct {
 	httpClientProvider httpclient.Provider
 	intervalCalculator intervalv2.Calculator
 	im                 instancemgmt.InstanceManager
+	logzioHeaders      *setting.LogzioHeadersSettings // LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
 }
 
-func ProvideService(httpClientProvider httpclient.Provider) *Service {
+func ProvideService(httpClientProvider httpclient.Provider, cfg *setting.Cfg) *Service { // LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
 	eslog.Debug("initializing")
 	// LOGZ.IO GRAFANA CHANGE :: DEV-31493 Override datasource URL on alert evaluation
 	ip := &eval.LogzioInstanceProvider{
@@ -39,6 +43,7 @@ func ProvideService(httpClientProvider httpclient.Provider) *Service {
 		im:                 im, // LOGZ.IO GRAFANA CHANGE :: DEV-31493 Override datasource URL on alert evaluation
 		httpClientProvider: httpClientProvider,
 		intervalCalculator: intervalv2.NewCalculator(),
+		logzioHeaders:      &cfg.LogzioHeaders, // LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
 	}
 }
 
@@ -51,6 +56,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	if err != nil {
 		return &backend.QueryDataResponse{}, err
 	}
+	dsInfo.LogzioHeaders = s.logzioHeaders // LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
 
 	client, err := es.NewClient(context.WithValue(ctx, "logzioHeaders", req.Headers), s.httpClientProvider, dsInfo, req.Queries[0].TimeRange) // LOGZ.IO :: Upgrade to 8.4.0
 	if err != nil {
@@ -139,6 +145,7 @@ func newInstanceSettings() datasource.InstanceFactoryFunc {
 			TimeInterval:               timeInterval,
 			IncludeFrozen:              includeFrozen,
 			XPack:                      xpack,
+			LogzioHeadersWhitelist:     models.LogzioHeadersWhitelistFromJsonData(simplejson.NewFromAny(jsonData)), // LOGZ.IO GRAFANA CHANGE :: Configurable header pass-through whitelist
 		}
 		return model, nil
 	}
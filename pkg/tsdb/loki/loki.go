@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"go.opentelemetry.io/otel/attribute"
 )
 
@@ -30,8 +31,14 @@ var (
 )
 
 func ProvideService(httpClientProvider httpclient.Provider, tracer tracing.Tracer) *Service {
+	// LOGZ.IO GRAFANA CHANGE :: Datasource overrides beyond URL
+	ip := &eval.LogzioInstanceProvider{
+		Delegate: datasource.NewInstanceProvider(newInstanceSettings(httpClientProvider)),
+	}
+	im := instancemgmt.New(ip)
+	// LOGZ.IO GRAFANA CHANGE :: end
 	return &Service{
-		im:     datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
+		im:     im, // LOGZ.IO GRAFANA CHANGE :: Datasource overrides beyond URL
 		plog:   log.New("tsdb.loki"),
 		tracer: tracer,
 	}